@@ -0,0 +1,107 @@
+package algorithms
+
+import (
+	"testing"
+
+	"github.com/Jintumoni/vortex/manager"
+	"github.com/Jintumoni/vortex/nodes"
+	"github.com/stretchr/testify/assert"
+)
+
+// newTestManager builds the triangle A - B - C with a tail C - D and a
+// separate pair E - F, all joined by the TwoWay edge FriendsWith
+func newTestManager(t *testing.T) *manager.AppManager {
+	a := manager.NewAppManager()
+	assert.NoError(t, a.WriteSchema(&nodes.SchemaDefNode{SchemaName: &nodes.StringNode{Value: "Person"}}))
+	for _, name := range []string{"A", "B", "C", "D", "E", "F"} {
+		assert.NoError(t, a.WriteVertex(&nodes.VertexInitNode{
+			SchemaName: &nodes.StringNode{Value: "Person"},
+			VertexName: &nodes.StringNode{Value: name},
+		}))
+	}
+	assert.NoError(t, a.WriteEdge(&nodes.EdgeDefNode{EdgeName: &nodes.StringNode{Value: "FriendsWith"}, EdgeType: nodes.TwoWayEdge}))
+	for _, r := range [][2]string{{"A", "B"}, {"B", "C"}, {"C", "A"}, {"C", "D"}, {"E", "F"}} {
+		assert.NoError(t, a.WriteRelation(&nodes.RelationInitNode{
+			LeftVertex:  &nodes.StringNode{Value: r[0]},
+			Relation:    &nodes.StringNode{Value: "FriendsWith"},
+			RightVertex: &nodes.StringNode{Value: r[1]},
+		}))
+	}
+	return a
+}
+
+func run(t *testing.T, name string) []nodes.ASTNode {
+	g, err := NewGraph(newTestManager(t), "Person", "FriendsWith")
+	assert.NoError(t, err)
+	algorithm, err := GetAlgorithm(name)
+	assert.NoError(t, err)
+	args, err := algorithm.Arguments(nil)
+	assert.NoError(t, err)
+	return algorithm.Run(g, args)
+}
+
+func ints(values []nodes.ASTNode) []int {
+	var numbers []int
+	for _, v := range values {
+		numbers = append(numbers, v.(*nodes.IntNode).Value)
+	}
+	return numbers
+}
+
+func floats(values []nodes.ASTNode) []float64 {
+	var numbers []float64
+	for _, v := range values {
+		numbers = append(numbers, v.(*nodes.FloatNode).Value)
+	}
+	return numbers
+}
+
+func TestConnectedComponents(t *testing.T) {
+	assert.Equal(t, []int{0, 0, 0, 0, 1, 1}, ints(run(t, "ConnectedComponents")))
+}
+
+func TestTriangleCount(t *testing.T) {
+	assert.Equal(t, []int{1, 1, 1, 0, 0, 0}, ints(run(t, "TriangleCount")))
+}
+
+func TestLabelPropagation(t *testing.T) {
+	communities := ints(run(t, "LabelPropagation"))
+	assert.Equal(t, communities[0], communities[1])
+	assert.Equal(t, communities[0], communities[2])
+	assert.Equal(t, communities[4], communities[5])
+	assert.NotEqual(t, communities[0], communities[4])
+}
+
+func TestPageRank(t *testing.T) {
+	rank := floats(run(t, "PageRank"))
+
+	total := 0.0
+	for _, r := range rank {
+		total += r
+	}
+	assert.InDelta(t, 1, total, 1e-9)
+	// C has the most friends
+	assert.Greater(t, rank[2], rank[0])
+	assert.Greater(t, rank[0], rank[3])
+}
+
+func TestBetweennessCentrality(t *testing.T) {
+	// every path from D goes through C, once in each direction
+	assert.Equal(t, []float64{0, 0, 4, 0, 0, 0}, floats(run(t, "BetweennessCentrality")))
+}
+
+func TestUnknownAlgorithm(t *testing.T) {
+	_, err := GetAlgorithm("PageRanks")
+	assert.ErrorIs(t, err, UnknownAlgorithm)
+}
+
+func TestAlgorithmArguments(t *testing.T) {
+	algorithm, _ := GetAlgorithm("PageRank")
+
+	args, err := algorithm.Arguments(nil)
+	assert.NoError(t, err)
+	assert.Equal(t, []int{20}, args)
+
+	_, err = algorithm.Arguments([]int{10, 1})
+	assert.ErrorIs(t, err, InvalidArguments)
+}
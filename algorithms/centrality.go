@@ -0,0 +1,113 @@
+package algorithms
+
+import (
+	"github.com/Jintumoni/vortex/nodes"
+)
+
+const damping = 0.85
+
+func init() {
+	register(&Algorithm{
+		Name:   "PageRank",
+		Column: "rank",
+		Params: []Param{{Name: "iterations", Default: 20}},
+		Run:    pageRank,
+	})
+	register(&Algorithm{
+		Name:   "BetweennessCentrality",
+		Column: "centrality",
+		Run:    betweennessCentrality,
+	})
+}
+
+// pageRank iterates the rank of every vertex, the rank of a vertex
+// without outgoing edges is spread over the whole graph
+func pageRank(g *Graph, args []int) []nodes.ASTNode {
+	n := len(g.Vertices)
+	if n == 0 {
+		return nil
+	}
+
+	rank := make([]float64, n)
+	for i := range rank {
+		rank[i] = 1 / float64(n)
+	}
+
+	for iteration := 0; iteration < args[0]; iteration++ {
+		dangling := 0.0
+		for i := range g.Vertices {
+			if len(g.Out[i]) == 0 {
+				dangling += rank[i]
+			}
+		}
+
+		next := make([]float64, n)
+		for i := range next {
+			next[i] = (1-damping)/float64(n) + damping*dangling/float64(n)
+		}
+		for i := range g.Vertices {
+			for _, j := range g.Out[i] {
+				next[j] += damping * rank[i] / float64(len(g.Out[i]))
+			}
+		}
+		rank = next
+	}
+
+	values := make([]nodes.ASTNode, n)
+	for i, r := range rank {
+		values[i] = &nodes.FloatNode{Value: r}
+	}
+	return values
+}
+
+// betweennessCentrality counts, for every vertex, the shortest paths between
+// other vertices going through it (Brandes' algorithm)
+func betweennessCentrality(g *Graph, args []int) []nodes.ASTNode {
+	n := len(g.Vertices)
+	centrality := make([]float64, n)
+
+	for s := range g.Vertices {
+		var stack []int
+		predecessors := make([][]int, n)
+		paths := make([]float64, n) // number of shortest paths from s
+		dist := make([]int, n)
+		for i := range dist {
+			dist[i] = -1
+		}
+		paths[s], dist[s] = 1, 0
+
+		queue := []int{s}
+		for len(queue) > 0 {
+			v := queue[0]
+			queue = queue[1:]
+			stack = append(stack, v)
+			for _, w := range g.Out[v] {
+				if dist[w] < 0 {
+					dist[w] = dist[v] + 1
+					queue = append(queue, w)
+				}
+				if dist[w] == dist[v]+1 {
+					paths[w] += paths[v]
+					predecessors[w] = append(predecessors[w], v)
+				}
+			}
+		}
+
+		dependency := make([]float64, n)
+		for i := len(stack) - 1; i >= 0; i-- {
+			w := stack[i]
+			for _, v := range predecessors[w] {
+				dependency[v] += paths[v] / paths[w] * (1 + dependency[w])
+			}
+			if w != s {
+				centrality[w] += dependency[w]
+			}
+		}
+	}
+
+	values := make([]nodes.ASTNode, n)
+	for i, c := range centrality {
+		values[i] = &nodes.FloatNode{Value: c}
+	}
+	return values
+}
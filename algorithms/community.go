@@ -0,0 +1,134 @@
+package algorithms
+
+import (
+	"github.com/Jintumoni/vortex/nodes"
+)
+
+func init() {
+	register(&Algorithm{
+		Name:   "ConnectedComponents",
+		Column: "component",
+		Run:    connectedComponents,
+	})
+	register(&Algorithm{
+		Name:   "TriangleCount",
+		Column: "triangles",
+		Run:    triangleCount,
+	})
+	register(&Algorithm{
+		Name:   "LabelPropagation",
+		Column: "community",
+		Params: []Param{{Name: "iterations", Default: 10}},
+		Run:    labelPropagation,
+	})
+}
+
+// connectedComponents numbers the weakly connected components of the graph
+// in the order their first vertex appears
+func connectedComponents(g *Graph, args []int) []nodes.ASTNode {
+	adjacency := g.Undirected()
+	component := make([]int, len(g.Vertices))
+	for i := range component {
+		component[i] = -1
+	}
+
+	next := 0
+	for s := range g.Vertices {
+		if component[s] >= 0 {
+			continue
+		}
+		component[s] = next
+		stack := []int{s}
+		for len(stack) > 0 {
+			v := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			for _, w := range adjacency[v] {
+				if component[w] < 0 {
+					component[w] = next
+					stack = append(stack, w)
+				}
+			}
+		}
+		next++
+	}
+
+	return intValues(component)
+}
+
+// triangleCount counts the triangles every vertex is part of, ignoring the
+// direction of the edges
+func triangleCount(g *Graph, args []int) []nodes.ASTNode {
+	adjacency := g.Undirected()
+	neighbours := make([]map[int]bool, len(g.Vertices))
+	for i, list := range adjacency {
+		neighbours[i] = make(map[int]bool, len(list))
+		for _, j := range list {
+			neighbours[i][j] = true
+		}
+	}
+
+	triangles := make([]int, len(g.Vertices))
+	for u, list := range adjacency {
+		for _, v := range list {
+			if v <= u {
+				continue
+			}
+			for _, w := range adjacency[v] {
+				if w > v && neighbours[u][w] {
+					triangles[u]++
+					triangles[v]++
+					triangles[w]++
+				}
+			}
+		}
+	}
+
+	return intValues(triangles)
+}
+
+// labelPropagation starts every vertex in its own community and moves it to
+// the most common community among its neighbours until nothing changes.
+// Ties go to the smallest label so the result does not depend on map order.
+func labelPropagation(g *Graph, args []int) []nodes.ASTNode {
+	adjacency := g.Undirected()
+	label := make([]int, len(g.Vertices))
+	for i := range label {
+		label[i] = i
+	}
+
+	for iteration := 0; iteration < args[0]; iteration++ {
+		changed := false
+		for v, list := range adjacency {
+			if len(list) == 0 {
+				continue
+			}
+			count := make(map[int]int)
+			for _, w := range list {
+				count[label[w]]++
+			}
+			best := label[v]
+			for l, c := range count {
+				if c > count[best] || (c == count[best] && l < best) {
+					best = l
+				}
+			}
+			if best != label[v] {
+				label[v] = best
+				changed = true
+			}
+		}
+		if !changed {
+			break
+		}
+	}
+
+	return intValues(label)
+}
+
+func intValues(numbers []int) []nodes.ASTNode {
+	values := make([]nodes.ASTNode, len(numbers))
+	for i, number := range numbers {
+		values[i] = &nodes.IntNode{Value: number}
+	}
+	return values
+}
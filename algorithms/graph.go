@@ -0,0 +1,149 @@
+package algorithms
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	vortexerrors "github.com/Jintumoni/vortex/errors"
+	"github.com/Jintumoni/vortex/manager"
+	"github.com/Jintumoni/vortex/nodes"
+)
+
+var (
+	UnknownAlgorithm = errors.New("Unknown algorithm")
+	InvalidArguments = errors.New("Invalid algorithm arguments")
+)
+
+func init() {
+	vortexerrors.RegisterCode("V0301", UnknownAlgorithm)
+	vortexerrors.RegisterCode("V0302", InvalidArguments)
+}
+
+// Graph is the subgraph an algorithm runs on, vertices are numbered in the
+// order the AppManager returns them
+type Graph struct {
+	Vertices []*nodes.VertexInitNode
+	Out      [][]int // Out[i] are the vertices joined to i by an edge leaving i
+	In       [][]int // In[i] are the vertices joined to i by an edge entering i
+}
+
+// NewGraph builds the subgraph of the vertices of the schema joined by the edge.
+// An empty schema selects every vertex and an empty edge follows every edge.
+func NewGraph(a *manager.AppManager, schema, edge string) (*Graph, error) {
+	g := new(Graph)
+	if schema == "" {
+		g.Vertices = a.ReadVertices()
+	} else {
+		if _, err := a.ReadSchema(schema); err != nil {
+			return nil, &vortexerrors.SemanticError{Err: fmt.Errorf("%w: %s", err, schema), Suggestions: vortexerrors.Suggest(schema, a.SchemaNames())}
+		}
+		g.Vertices = a.ReadVerticesBySchema(schema)
+	}
+	if edge != "" {
+		if _, err := a.ReadEdge(edge); err != nil {
+			return nil, &vortexerrors.SemanticError{Err: fmt.Errorf("%w: %s", err, edge), Suggestions: vortexerrors.Suggest(edge, a.EdgeNames())}
+		}
+	}
+
+	index := make(map[*nodes.VertexInitNode]int, len(g.Vertices))
+	for i, v := range g.Vertices {
+		index[v] = i
+	}
+
+	g.Out = make([][]int, len(g.Vertices))
+	g.In = make([][]int, len(g.Vertices))
+	for i, v := range g.Vertices {
+		seen := make(map[int]bool)
+		for _, pair := range a.Neighbours(v) {
+			if edge != "" && pair.Relation.EdgeName.Value != edge {
+				continue
+			}
+			j, ok := index[pair.Vertex]
+			if !ok || j == i || seen[j] {
+				continue
+			}
+			seen[j] = true
+			g.Out[i] = append(g.Out[i], j)
+			g.In[j] = append(g.In[j], i)
+		}
+	}
+	return g, nil
+}
+
+// Undirected returns the neighbours of every vertex ignoring the direction of the edges
+func (g *Graph) Undirected() [][]int {
+	adjacency := make([][]int, len(g.Vertices))
+	for i := range g.Vertices {
+		seen := make(map[int]bool)
+		for _, list := range [][]int{g.Out[i], g.In[i]} {
+			for _, j := range list {
+				if !seen[j] {
+					seen[j] = true
+					adjacency[i] = append(adjacency[i], j)
+				}
+			}
+		}
+		sort.Ints(adjacency[i])
+	}
+	return adjacency
+}
+
+// Algorithm computes one value for every vertex of a graph
+type Algorithm struct {
+	Name   string
+	Column string // name of the computed value
+	Params []Param
+	Run    func(g *Graph, args []int) []nodes.ASTNode
+}
+
+// Param is an optional integer parameter given after the schema and the edge
+type Param struct {
+	Name    string
+	Default int
+}
+
+var registry = map[string]*Algorithm{}
+
+func register(algorithm *Algorithm) {
+	registry[algorithm.Name] = algorithm
+}
+
+// GetAlgorithm returns the algorithm registered with the name
+func GetAlgorithm(name string) (*Algorithm, error) {
+	algorithm, ok := registry[name]
+	if !ok {
+		return nil, fmt.Errorf("%w %q, expected one of: %s", UnknownAlgorithm, name, strings.Join(GetAllAlgorithms(), ", "))
+	}
+	return algorithm, nil
+}
+
+// GetAllAlgorithms returns the names of every registered algorithm
+func GetAllAlgorithms() []string {
+	var names []string
+	for name := range registry {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Arguments fills in the defaults of the parameters that are not given
+func (a *Algorithm) Arguments(given []int) ([]int, error) {
+	if len(given) > len(a.Params) {
+		return nil, fmt.Errorf("%w: %s takes at most %d parameters", InvalidArguments, a.Name, len(a.Params))
+	}
+
+	args := make([]int, len(a.Params))
+	for i, param := range a.Params {
+		args[i] = param.Default
+		if i < len(given) {
+			args[i] = given[i]
+		}
+		if args[i] < 0 {
+			return nil, fmt.Errorf("%w: %s can not be negative", InvalidArguments, param.Name)
+		}
+	}
+	return args, nil
+}
@@ -0,0 +1,246 @@
+// Package client connects to a Vortex server over the binary protocol of the
+// wire package. A Client keeps a pool of sessions and is safe for concurrent use.
+//
+//	c, err := client.Dial(ctx, "localhost:7688", nil)
+//	if err != nil {
+//		return err
+//	}
+//	defer c.Close()
+//
+//	err = c.Exec(ctx, `Vertex Ann Person { .name = "Ann" .age = 30 }`, nil)
+//	rows, err := c.Query(ctx, `Query Person { .age > $age } Return .name, .age`, map[string]any{"age": 18})
+package client
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/Jintumoni/vortex/wire"
+)
+
+var Closed = errors.New("Client is closed")
+
+// Options configures a client, the zero value uses the defaults
+type Options struct {
+	MaxOpenConns int           // sessions open at once, no limit when 0
+	MaxIdleConns int           // sessions kept open while unused, 2 when 0
+	MaxRetries   int           // times a read is sent again after its session failed, 2 when 0, none when negative
+	RetryDelay   time.Duration // wait before the first retry, doubled for every other one, 50ms when 0
+	BatchSize    int           // rows per batch asked for, wire.DefaultBatchSize when 0
+}
+
+// Client runs programs on a server, over sessions it opens when they are needed
+type Client struct {
+	address string
+	options Options
+
+	slots  chan struct{} // a value for every session in use, nil without MaxOpenConns
+	mu     sync.Mutex
+	idle   []*conn
+	closed bool
+}
+
+// conn is a session of the pool with the statements prepared in it
+type conn struct {
+	*wire.Conn
+	statements map[*Stmt]*wire.Stmt
+}
+
+// Dial connects to the server at the address, host:port or unix:path. The
+// session it opens is kept for the first program. opts may be nil.
+func Dial(ctx context.Context, address string, opts *Options) (*Client, error) {
+	c := &Client{address: address}
+	if opts != nil {
+		c.options = *opts
+	}
+	if c.options.MaxIdleConns == 0 {
+		c.options.MaxIdleConns = 2
+	}
+	if c.options.MaxRetries == 0 {
+		c.options.MaxRetries = 2
+	}
+	if c.options.RetryDelay == 0 {
+		c.options.RetryDelay = 50 * time.Millisecond
+	}
+	if c.options.MaxOpenConns > 0 {
+		c.slots = make(chan struct{}, c.options.MaxOpenConns)
+	}
+
+	cn, err := c.get(ctx)
+	if err != nil {
+		return nil, err
+	}
+	c.put(cn)
+	return c, nil
+}
+
+// Close closes the sessions that are not in use, the others are closed
+// once their programs are complete
+func (c *Client) Close() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.closed {
+		return Closed
+	}
+	c.closed = true
+	for _, cn := range c.idle {
+		cn.Close()
+	}
+	c.idle = nil
+	return nil
+}
+
+// Ping reports whether the server can be reached
+func (c *Client) Ping(ctx context.Context) error {
+	rows, err := c.Query(ctx, "", nil)
+	if err != nil {
+		return err
+	}
+	return rows.Close()
+}
+
+// Exec runs a program with the values of its $name parameters, the results
+// of its statements are discarded. It is only sent again when it could not
+// be written to its session, once it was the server may have run it.
+func (c *Client) Exec(ctx context.Context, program string, params map[string]any) error {
+	return c.exec(ctx, func(cn *conn) (*wire.Results, error) {
+		return cn.Run(ctx, program, params)
+	})
+}
+
+// Query runs a program that does not change the graph and returns the rows
+// of its first statement, a program changing the graph fails with
+// vortex.ReadOnly. When the session fails before the first result arrives
+// the program is sent again on another one, up to MaxRetries times.
+func (c *Client) Query(ctx context.Context, program string, params map[string]any) (*Rows, error) {
+	return c.query(ctx, func(cn *conn) (*wire.Results, error) {
+		return cn.RunReadOnly(ctx, program, params)
+	})
+}
+
+func (c *Client) exec(ctx context.Context, run func(*conn) (*wire.Results, error)) error {
+	delay := c.options.RetryDelay
+	for attempt := 0; ; attempt++ {
+		cn, err := c.get(ctx)
+		if err == nil {
+			var results *wire.Results
+			if results, err = run(cn); err == nil {
+				for results.NextResult() {
+				}
+				c.put(cn)
+				return results.Err()
+			}
+			c.put(cn)
+		}
+		if !c.retry(ctx, cn, err, attempt, &delay) {
+			return err
+		}
+	}
+}
+
+func (c *Client) query(ctx context.Context, run func(*conn) (*wire.Results, error)) (*Rows, error) {
+	delay := c.options.RetryDelay
+	for attempt := 0; ; attempt++ {
+		cn, err := c.get(ctx)
+		if err == nil {
+			var results *wire.Results
+			if results, err = run(cn); err == nil {
+				if results.NextResult() || results.Err() == nil {
+					return &Rows{client: c, conn: cn, results: results}, nil
+				}
+				err = results.Err()
+			}
+			c.put(cn)
+		}
+		if !c.retry(ctx, cn, err, attempt, &delay) {
+			return nil, err
+		}
+	}
+}
+
+// retry reports whether a request that failed with err is sent again, after
+// waiting for delay. Only a session that could not be opened or that failed
+// is worth another attempt, the server answered the others.
+func (c *Client) retry(ctx context.Context, cn *conn, err error, attempt int, delay *time.Duration) bool {
+	if errors.Is(err, Closed) || ctx.Err() != nil || attempt >= c.options.MaxRetries {
+		return false
+	}
+	if cn != nil && cn.Err() == nil {
+		return false
+	}
+	select {
+	case <-time.After(*delay):
+		*delay *= 2
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
+// get takes an idle session or opens a new one, waiting while MaxOpenConns are in use
+func (c *Client) get(ctx context.Context) (*conn, error) {
+	if c.slots != nil {
+		select {
+		case c.slots <- struct{}{}:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+
+	c.mu.Lock()
+	if c.closed {
+		c.mu.Unlock()
+		c.release()
+		return nil, Closed
+	}
+	if n := len(c.idle); n > 0 {
+		cn := c.idle[n-1]
+		c.idle = c.idle[:n-1]
+		c.mu.Unlock()
+		return cn, nil
+	}
+	c.mu.Unlock()
+
+	session, err := wire.Dial(ctx, c.address)
+	if err != nil {
+		c.release()
+		return nil, err
+	}
+	session.BatchSize = c.options.BatchSize
+	return &conn{Conn: session, statements: make(map[*Stmt]*wire.Stmt)}, nil
+}
+
+// put returns a session to the pool, it is closed when it failed or the pool is full
+func (c *Client) put(cn *conn) {
+	defer c.release()
+	if cn.Err() == nil {
+		cn.forget()
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.closed || cn.Err() != nil || len(c.idle) >= c.options.MaxIdleConns {
+		cn.Close()
+		return
+	}
+	c.idle = append(c.idle, cn)
+}
+
+func (c *Client) release() {
+	if c.slots != nil {
+		<-c.slots
+	}
+}
+
+// forget drops the statements closed since the session was last used
+func (cn *conn) forget() {
+	for stmt, prepared := range cn.statements {
+		if stmt.isClosed() {
+			prepared.Close(context.Background())
+			delete(cn.statements, stmt)
+		}
+	}
+}
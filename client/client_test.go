@@ -0,0 +1,178 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/Jintumoni/vortex"
+	"github.com/Jintumoni/vortex/server"
+	"github.com/Jintumoni/vortex/wire"
+	"github.com/stretchr/testify/assert"
+)
+
+const testGraph = `
+Schema Person {
+  name string
+  age  int
+}
+
+Vertex Ann Person { .name = "Ann" .age = 30 }
+Vertex Bob Person { .name = "Bob" .age = 20 }
+`
+
+type Person struct {
+	Name  string
+	Years int    `vortex:"age"`
+	Notes string `vortex:"-"`
+}
+
+// dropFirst closes the first connections it accepts, like a server restarting
+type dropFirst struct {
+	net.Listener
+	drop int
+}
+
+func (l *dropFirst) Accept() (net.Conn, error) {
+	for {
+		conn, err := l.Listener.Accept()
+		if err != nil || l.drop == 0 {
+			return conn, err
+		}
+		l.drop--
+		conn.Close()
+	}
+}
+
+// serve serves an in-memory database holding testGraph, the first drop connections are closed at once
+func serve(t *testing.T, drop int) string {
+	db, err := vortex.Open("", nil)
+	assert.NoError(t, err)
+	assert.NoError(t, db.Exec(context.Background(), testGraph))
+	t.Cleanup(func() { db.Close() })
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NoError(t, err)
+	go wire.NewServer(db).Serve(&dropFirst{Listener: listener, drop: drop})
+	t.Cleanup(func() { listener.Close() })
+	return listener.Addr().String()
+}
+
+func dial(t *testing.T, address string, opts *Options) *Client {
+	c, err := Dial(context.Background(), address, opts)
+	assert.NoError(t, err)
+	t.Cleanup(func() { c.Close() })
+	return c
+}
+
+func TestExecAndQuery(t *testing.T) {
+	ctx := context.Background()
+	c := dial(t, serve(t, 0), nil)
+
+	assert.NoError(t, c.Exec(ctx, `Vertex Cid Person { .name = "Cid" .age = 41 }`, nil))
+
+	rows, err := c.Query(ctx, `Query Person as P { .age > $age } Return P.name, .age`, map[string]any{"age": 25})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"P.name", ".age"}, rows.Columns())
+	var people []Person
+	assert.NoError(t, rows.All(&people))
+	assert.Equal(t, []Person{{Name: "Ann", Years: 30}, {Name: "Cid", Years: 41}}, people)
+
+	rows, err = c.Query(ctx, `Query Person { .name = "Bob" } Return .name, .age`, nil)
+	assert.NoError(t, err)
+	assert.True(t, rows.Next())
+	var bob struct {
+		Name string
+		Age  *int64
+	}
+	assert.NoError(t, rows.Decode(&bob))
+	assert.Equal(t, "Bob", bob.Name)
+	assert.Equal(t, int64(20), *bob.Age)
+	assert.ErrorIs(t, rows.Decode(bob), NotAStruct)
+	assert.False(t, rows.Next())
+	assert.NoError(t, rows.Close())
+
+	assert.NoError(t, c.Ping(ctx))
+}
+
+func TestQueryIsReadOnly(t *testing.T) {
+	ctx := context.Background()
+	c := dial(t, serve(t, 0), nil)
+
+	_, err := c.Query(ctx, `Vertex Dan Person { .name = "Dan" .age = 50 }`, nil)
+	var e *server.Error
+	assert.True(t, errors.As(err, &e))
+	assert.Equal(t, vortex.ReadOnly.Error(), e.Message)
+
+	rows, err := c.Query(ctx, `Query Count(Person)`, nil)
+	assert.NoError(t, err)
+	assert.True(t, rows.Next())
+	var count int
+	assert.NoError(t, rows.Scan(&count))
+	assert.Equal(t, 2, count)
+	assert.NoError(t, rows.Close())
+}
+
+func TestPrepare(t *testing.T) {
+	ctx := context.Background()
+	c := dial(t, serve(t, 0), &Options{MaxOpenConns: 2})
+
+	stmt, err := c.Prepare(ctx, `Query Person { .age > $age } Return .name`)
+	assert.NoError(t, err)
+	// both sessions are in use, the statement is prepared in the second one
+	first, err := stmt.Query(ctx, map[string]any{"age": 25})
+	assert.NoError(t, err)
+	second, err := stmt.Query(ctx, map[string]any{"age": 10})
+	assert.NoError(t, err)
+
+	var names []Person
+	assert.NoError(t, second.All(&names))
+	assert.Equal(t, []Person{{Name: "Ann"}, {Name: "Bob"}}, names)
+	assert.NoError(t, first.All(&names))
+	assert.Equal(t, []Person{{Name: "Ann"}, {Name: "Bob"}, {Name: "Ann"}}, names)
+
+	assert.NoError(t, stmt.Close())
+	_, err = stmt.Query(ctx, nil)
+	assert.ErrorIs(t, err, wire.UnknownStatement)
+
+	_, err = c.Prepare(ctx, `Query Person {`)
+	var e *server.Error
+	assert.True(t, errors.As(err, &e))
+	assert.Equal(t, "EOF", e.Token)
+}
+
+func TestQueryRetriesAFailedSession(t *testing.T) {
+	ctx := context.Background()
+	// the session opened by Dial is dropped by the server
+	c := dial(t, serve(t, 1), &Options{RetryDelay: time.Millisecond})
+
+	rows, err := c.Query(ctx, `Query Person Return .name`, nil)
+	assert.NoError(t, err)
+	var people []Person
+	assert.NoError(t, rows.All(&people))
+	assert.Len(t, people, 2)
+
+	c = dial(t, serve(t, 1), &Options{MaxRetries: -1})
+	_, err = c.Query(ctx, `Query Person Return .name`, nil)
+	assert.Error(t, err)
+}
+
+func TestMaxOpenConns(t *testing.T) {
+	ctx := context.Background()
+	c := dial(t, serve(t, 0), &Options{MaxOpenConns: 1})
+
+	rows, err := c.Query(ctx, `Query Person`, nil)
+	assert.NoError(t, err)
+
+	waiting, cancel := context.WithTimeout(ctx, 10*time.Millisecond)
+	defer cancel()
+	_, err = c.Query(waiting, `Query Person`, nil)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+
+	assert.NoError(t, rows.Close())
+	assert.NoError(t, c.Ping(ctx))
+	assert.NoError(t, c.Close())
+	assert.ErrorIs(t, c.Ping(ctx), Closed)
+}
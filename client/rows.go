@@ -0,0 +1,228 @@
+package client
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/Jintumoni/vortex/wire"
+)
+
+var NotAStruct = errors.New("Destination is not a pointer to a struct")
+
+// Rows are the rows of a query, read as they arrive. They hold their
+// session until they are closed.
+//
+//	defer rows.Close()
+//	for rows.Next() {
+//		var person Person
+//		if err := rows.Decode(&person); err != nil {
+//			return err
+//		}
+//	}
+//	return rows.Err()
+type Rows struct {
+	client  *Client
+	conn    *conn
+	results *wire.Results
+	closed  bool
+}
+
+// Columns returns the names of the columns, as written in the Return clause
+func (r *Rows) Columns() []string {
+	return r.results.Columns()
+}
+
+// Next moves to the next row, it returns false when there are no more rows
+func (r *Rows) Next() bool {
+	return !r.closed && r.results.Next()
+}
+
+// NextResultSet moves to the rows of the next statement of the program
+func (r *Rows) NextResultSet() bool {
+	return !r.closed && r.results.NextResult()
+}
+
+// Err returns why the program failed, once its rows are read
+func (r *Rows) Err() error {
+	return r.results.Err()
+}
+
+// Close stops the program if it still runs and releases its session
+func (r *Rows) Close() error {
+	if r.closed {
+		return nil
+	}
+	r.closed = true
+	err := r.results.Close()
+	r.client.put(r.conn)
+	return err
+}
+
+// Scan copies the columns of the current row into the values pointed at by
+// dest, see wire.Results.Scan
+func (r *Rows) Scan(dest ...any) error {
+	return r.results.Scan(dest...)
+}
+
+// Decode copies the current row into the struct pointed at by dest. A
+// column is copied into the field named like the attribute it returns:
+// .name and P.name into Name. The name is matched ignoring case, and a tag
+// names the attribute of a field or drops it with "-":
+//
+//	type Person struct {
+//		Name  string
+//		Years int    `vortex:"age"`
+//		Notes string `vortex:"-"`
+//	}
+//
+// Columns without a field are ignored. A null leaves its field zero, or
+// sets it to nil when it is a pointer.
+func (r *Rows) Decode(dest any) error {
+	v := reflect.ValueOf(dest)
+	if v.Kind() != reflect.Pointer || v.IsNil() || v.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("%w: %T", NotAStruct, dest)
+	}
+	return r.decode(v.Elem(), fields(v.Elem().Type()))
+}
+
+// All reads the remaining rows of the statement into the slice of structs
+// pointed at by dest and closes the rows, see Decode
+func (r *Rows) All(dest any) error {
+	defer r.Close()
+	v := reflect.ValueOf(dest)
+	if v.Kind() != reflect.Pointer || v.IsNil() || v.Elem().Kind() != reflect.Slice || v.Elem().Type().Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("%w: %T", NotAStruct, dest)
+	}
+
+	slice := v.Elem()
+	fields := fields(slice.Type().Elem())
+	for r.Next() {
+		row := reflect.New(slice.Type().Elem()).Elem()
+		if err := r.decode(row, fields); err != nil {
+			return err
+		}
+		slice.Set(reflect.Append(slice, row))
+	}
+	return r.Err()
+}
+
+func (r *Rows) decode(dest reflect.Value, fields map[string]int) error {
+	row := r.results.Row()
+	if row == nil {
+		return wire.NoRow
+	}
+	for i, column := range r.Columns() {
+		field, ok := fields[strings.ToLower(column)]
+		if !ok {
+			field, ok = fields[strings.ToLower(attribute(column))]
+		}
+		if !ok {
+			continue
+		}
+		if err := set(dest.Field(field), row[i]); err != nil {
+			return fmt.Errorf("column %s: %w", column, err)
+		}
+	}
+	return nil
+}
+
+// fields returns the index of the exported fields of a struct by the lower case name of their attribute
+func fields(t reflect.Type) map[string]int {
+	fields := make(map[string]int)
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		name := field.Name
+		if tag, ok := field.Tag.Lookup("vortex"); ok {
+			if tag == "-" {
+				continue
+			}
+			name = tag
+		}
+		fields[strings.ToLower(name)] = i
+	}
+	return fields
+}
+
+// attribute returns the attribute a column returns, name for .name or P.name
+func attribute(column string) string {
+	i := strings.LastIndexByte(column, '.')
+	if i < 0 {
+		return column
+	}
+	name := column[i+1:]
+	for _, c := range name {
+		if !(c == '_' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9') {
+			return column
+		}
+	}
+	return name
+}
+
+// set copies a value read from the server into a field
+func set(field reflect.Value, value any) error {
+	if value == nil {
+		field.SetZero()
+		return nil
+	}
+
+	switch field.Kind() {
+	case reflect.Pointer:
+		elem := reflect.New(field.Type().Elem())
+		if err := set(elem.Elem(), value); err != nil {
+			return err
+		}
+		field.Set(elem)
+		return nil
+	case reflect.Interface:
+		if reflect.TypeOf(value).AssignableTo(field.Type()) {
+			field.Set(reflect.ValueOf(value))
+			return nil
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if v, ok := value.(int64); ok && !field.OverflowInt(v) {
+			field.SetInt(v)
+			return nil
+		}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		if v, ok := value.(int64); ok && v >= 0 && !field.OverflowUint(uint64(v)) {
+			field.SetUint(uint64(v))
+			return nil
+		}
+	case reflect.Float32, reflect.Float64:
+		switch v := value.(type) {
+		case float64:
+			field.SetFloat(v)
+			return nil
+		case int64:
+			field.SetFloat(float64(v))
+			return nil
+		}
+	case reflect.String:
+		if v, ok := value.(string); ok {
+			field.SetString(v)
+			return nil
+		}
+	case reflect.Bool:
+		if v, ok := value.(bool); ok {
+			field.SetBool(v)
+			return nil
+		}
+	case reflect.Slice:
+		if values, ok := value.([]any); ok {
+			slice := reflect.MakeSlice(field.Type(), len(values), len(values))
+			for i, v := range values {
+				if err := set(slice.Index(i), v); err != nil {
+					return err
+				}
+			}
+			field.Set(slice)
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: %v into %s", wire.ScanTypeError, value, field.Type())
+}
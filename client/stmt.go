@@ -0,0 +1,89 @@
+package client
+
+import (
+	"context"
+	"sync"
+
+	"github.com/Jintumoni/vortex/wire"
+)
+
+// Stmt is a program prepared once and run many times. It is prepared again
+// in every session it runs in, the first time it does.
+type Stmt struct {
+	client  *Client
+	program string
+
+	mu     sync.Mutex
+	closed bool
+}
+
+// Prepare parses a program on the server, a syntax error is returned at once
+func (c *Client) Prepare(ctx context.Context, program string) (*Stmt, error) {
+	cn, err := c.get(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer c.put(cn)
+
+	stmt := &Stmt{client: c, program: program}
+	if _, err := stmt.prepared(ctx, cn); err != nil {
+		return nil, err
+	}
+	return stmt, nil
+}
+
+// Exec runs the program with the values of its $name parameters, see Client.Exec
+func (s *Stmt) Exec(ctx context.Context, params map[string]any) error {
+	if s.isClosed() {
+		return wire.UnknownStatement
+	}
+	return s.client.exec(ctx, func(cn *conn) (*wire.Results, error) {
+		prepared, err := s.prepared(ctx, cn)
+		if err != nil {
+			return nil, err
+		}
+		return prepared.Run(ctx, params)
+	})
+}
+
+// Query runs the program, that does not change the graph, with the values
+// of its $name parameters and returns the rows of its first statement, see Client.Query
+func (s *Stmt) Query(ctx context.Context, params map[string]any) (*Rows, error) {
+	if s.isClosed() {
+		return nil, wire.UnknownStatement
+	}
+	return s.client.query(ctx, func(cn *conn) (*wire.Results, error) {
+		prepared, err := s.prepared(ctx, cn)
+		if err != nil {
+			return nil, err
+		}
+		return prepared.RunReadOnly(ctx, params)
+	})
+}
+
+// Close drops the statement, the sessions forget it when they are next released
+func (s *Stmt) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.closed = true
+	return nil
+}
+
+func (s *Stmt) isClosed() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.closed
+}
+
+// prepared returns the statement prepared in the session, preparing it the first time
+func (s *Stmt) prepared(ctx context.Context, cn *conn) (*wire.Stmt, error) {
+	if prepared, ok := cn.statements[s]; ok {
+		return prepared, nil
+	}
+	prepared, err := cn.Prepare(ctx, s.program)
+	if err != nil {
+		return nil, err
+	}
+	cn.statements[s] = prepared
+	return prepared, nil
+}
@@ -0,0 +1,22 @@
+// Command vortex-lsp is the language server of Vortex programs. Editors start
+// it and speak the Language Server Protocol over its standard input and
+// output, the failures of the session are logged to the standard error.
+//
+//	vortex-lsp
+package main
+
+import (
+	"log"
+	"os"
+
+	"github.com/Jintumoni/vortex/lsp"
+)
+
+func main() {
+	s := lsp.NewServer()
+	s.Log = log.New(os.Stderr, "vortex-lsp: ", log.LstdFlags)
+	if err := s.Serve(os.Stdin, os.Stdout); err != nil {
+		s.Log.Println(err)
+		os.Exit(1)
+	}
+}
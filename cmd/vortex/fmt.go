@@ -0,0 +1,95 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	vortexerrors "github.com/Jintumoni/vortex/errors"
+	"github.com/Jintumoni/vortex/lexer"
+	"github.com/Jintumoni/vortex/parser"
+	"github.com/Jintumoni/vortex/visitors"
+)
+
+// formatProgram rewrites programs in their canonical form. The formatted
+// source of the files, or of the standard input when there are none, is
+// printed unless --check lists the ones that are not formatted or --write
+// rewrites them.
+func formatProgram(args []string) error {
+	flags := flag.NewFlagSet("fmt", flag.ExitOnError)
+	check := flags.Bool("check", false, "list the files that are not formatted and fail when there is one")
+	write := flags.Bool("write", false, "rewrite the files that are not formatted")
+	flags.Parse(args)
+
+	if *check && *write {
+		return errors.New("--check and --write cannot be used together")
+	}
+	files := flags.Args()
+	if len(files) == 0 {
+		if *write {
+			return errors.New("--write needs the files to rewrite")
+		}
+		files = []string{"-"}
+	}
+
+	var unformatted, failed int
+	for _, name := range files {
+		source, err := read(name)
+		if err != nil {
+			return err
+		}
+		formatted, err := format(source)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%s:\n", name)
+			vortexerrors.Render(os.Stderr, vortexerrors.TextFormat(os.Stderr), vortexerrors.Diagnose(err))
+			failed++
+			continue
+		}
+
+		switch {
+		case *check:
+			if !bytes.Equal(source, formatted) {
+				fmt.Println(name)
+				unformatted++
+			}
+		case *write:
+			if !bytes.Equal(source, formatted) {
+				if err := os.WriteFile(name, formatted, 0o644); err != nil {
+					return err
+				}
+			}
+		default:
+			os.Stdout.Write(formatted)
+		}
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("%d of %d files have syntax errors", failed, len(files))
+	}
+	if unformatted > 0 {
+		return fmt.Errorf("%d of %d files are not formatted", unformatted, len(files))
+	}
+	return nil
+}
+
+// read returns the content of the file, of the standard input for -
+func read(name string) ([]byte, error) {
+	if name == "-" {
+		return io.ReadAll(os.Stdin)
+	}
+	return os.ReadFile(name)
+}
+
+// format returns the canonical source of a program with its comments
+func format(source []byte) ([]byte, error) {
+	l := lexer.NewLexer(bytes.NewReader(source))
+	l.KeepComments = true
+	root, err := parser.NewParser(l).Parse()
+	if err != nil {
+		return nil, err
+	}
+	return visitors.NewFormatter(l.Comments).Format(root), nil
+}
@@ -0,0 +1,39 @@
+// Command vortex runs the tools of the Vortex graph database.
+//
+//	vortex serve --listen :7687 --data /data/graph
+//	vortex fmt --write graph.vtx
+package main
+
+import (
+	"fmt"
+	"os"
+)
+
+const usage = `Usage: vortex <command> [flags]
+
+Commands:
+  serve    share a database over HTTP and the binary protocol
+  fmt      rewrite programs in their canonical form
+`
+
+func main() {
+	if len(os.Args) < 2 {
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+
+	var err error
+	switch os.Args[1] {
+	case "serve":
+		err = serve(os.Args[2:])
+	case "fmt":
+		err = formatProgram(os.Args[2:])
+	default:
+		fmt.Fprintf(os.Stderr, "vortex: unknown command %q\n\n%s", os.Args[1], usage)
+		os.Exit(2)
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "vortex:", err)
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,57 @@
+package main
+
+import (
+	"flag"
+	"log"
+	"time"
+
+	"github.com/Jintumoni/vortex"
+	"github.com/Jintumoni/vortex/server"
+	"github.com/Jintumoni/vortex/visitors"
+	"github.com/Jintumoni/vortex/wire"
+)
+
+// serve shares a database over HTTP, and over the binary protocol when
+// --wire is set, until a server fails
+func serve(args []string) error {
+	flags := flag.NewFlagSet("serve", flag.ExitOnError)
+	listen := flags.String("listen", ":7687", "address to listen on")
+	wireListen := flags.String("wire", "", "address of the binary protocol, host:port or unix:path, empty to disable it")
+	data := flags.String("data", "", "directory of the database, empty to keep it in memory")
+	timeout := flags.Duration("timeout", 30*time.Second, "longest a program may run, 0 for no limit")
+	readOnly := flags.Bool("read-only", false, "refuse the programs that change the graph")
+	maxRows := flags.Int("max-rows", 0, "rows an operator of a query may produce, 0 for no limit")
+	maxExpandedEdges := flags.Int64("max-expanded-edges", 0, "adjacency list entries the traversals of a query may read, 0 for no limit")
+	flags.Parse(args)
+
+	db, err := vortex.Open(*data, &vortex.Options{
+		ReadOnly: *readOnly,
+		Limits:   visitors.Limits{MaxRows: *maxRows, MaxExpandedEdges: *maxExpandedEdges},
+	})
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	failed := make(chan error, 2)
+	if *wireListen != "" {
+		w := wire.NewServer(db)
+		w.ReadOnly = *readOnly
+		log.Printf("serving %s on %s with the binary protocol", describe(*data), *wireListen)
+		go func() { failed <- w.ListenAndServe(*wireListen) }()
+	}
+
+	s := server.NewServer(db)
+	s.Timeout = *timeout
+	s.ReadOnly = *readOnly
+	log.Printf("serving %s on %s", describe(*data), *listen)
+	go func() { failed <- s.ListenAndServe(*listen) }()
+	return <-failed
+}
+
+func describe(dir string) string {
+	if dir == "" {
+		return "an in-memory database"
+	}
+	return dir
+}
@@ -0,0 +1,126 @@
+package errors
+
+import (
+	stderrors "errors"
+
+	"github.com/Jintumoni/vortex/nodes"
+)
+
+// Severity is how bad the problem a diagnostic describes is
+type Severity int
+
+const (
+	SeverityError Severity = iota + 1
+	SeverityWarning
+	SeverityNote
+)
+
+func (s Severity) String() string {
+	switch s {
+	case SeverityError:
+		return "error"
+	case SeverityWarning:
+		return "warning"
+	case SeverityNote:
+		return "note"
+	default:
+		return ""
+	}
+}
+
+func (s Severity) MarshalText() ([]byte, error) {
+	return []byte(s.String()), nil
+}
+
+// Codes of the diagnostics of the parser, the codes of the errors of a
+// statement that parsed are registered by the packages running it
+const (
+	CodeUnexpectedToken    = "V0001"
+	CodeUnknownEdgeType    = "V0002"
+	CodeUnknownStatement   = "V0003"
+	CodeUnknownBuiltinFunc = "V0004"
+)
+
+// Label is a part of the source that explains a diagnostic, eg: where a
+// name was first written
+type Label struct {
+	Span    nodes.Span `json:"span"`
+	Message string     `json:"message"`
+}
+
+// Fix is a change of the source that solves the problem of a diagnostic
+type Fix struct {
+	Message     string     `json:"message"`
+	Span        nodes.Span `json:"span"`        // part of the source to replace
+	Replacement string     `json:"replacement"` // text to write instead
+}
+
+// Diagnostic describes a problem of a program for people and for tools.
+// Spans are zero when the part of the source is not known.
+type Diagnostic struct {
+	Severity  Severity   `json:"severity"`
+	Code      string     `json:"code,omitempty"`
+	Message   string     `json:"message"`
+	Span      nodes.Span `json:"span"`            // part of the source at fault
+	Label     string     `json:"label,omitempty"` // shown under the span, eg: Expected one of: ...
+	Secondary []Label    `json:"secondary,omitempty"`
+	Notes     []string   `json:"notes,omitempty"`
+	Fixes     []Fix      `json:"fixes,omitempty"`
+
+	// the lines of the source up to the first row of the span, empty when
+	// the source is not known
+	SourceContext string `json:"-"`
+}
+
+// diagnosable is an error that describes itself with a diagnostic
+type diagnosable interface {
+	error
+	Diagnostic() *Diagnostic
+}
+
+// Diagnose describes err with diagnostics, one for each of the syntax
+// errors of a program
+func Diagnose(err error) []*Diagnostic {
+	var syntaxErrors SyntaxErrors
+	if stderrors.As(err, &syntaxErrors) {
+		diagnostics := make([]*Diagnostic, 0, len(syntaxErrors))
+		for _, e := range syntaxErrors {
+			diagnostics = append(diagnostics, diagnose(e))
+		}
+		return diagnostics
+	}
+	return []*Diagnostic{diagnose(err)}
+}
+
+func diagnose(err error) *Diagnostic {
+	var d diagnosable
+	if stderrors.As(err, &d) {
+		return d.Diagnostic()
+	}
+	return &Diagnostic{Severity: SeverityError, Code: codeOf(err), Message: err.Error()}
+}
+
+var codes []struct {
+	code string
+	err  error
+}
+
+// RegisterCode sets the code of the diagnostics of the errors wrapping err.
+// The packages register the codes of their errors when they are loaded.
+func RegisterCode(code string, err error) {
+	codes = append(codes, struct {
+		code string
+		err  error
+	}{code, err})
+}
+
+// codeOf returns the code registered for an error that err wraps, empty
+// when there is none
+func codeOf(err error) string {
+	for _, c := range codes {
+		if stderrors.Is(err, c.err) {
+			return c.code
+		}
+	}
+	return ""
+}
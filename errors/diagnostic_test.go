@@ -0,0 +1,114 @@
+package errors
+
+import (
+	"bytes"
+	stderrors "errors"
+	"fmt"
+	"testing"
+
+	"github.com/Jintumoni/vortex/lexer"
+	"github.com/Jintumoni/vortex/nodes"
+	"github.com/stretchr/testify/assert"
+)
+
+var testMissing = stderrors.New("Vertex missing")
+
+func init() {
+	RegisterCode("V9999", testMissing)
+}
+
+func span(row, start, end int) nodes.Span {
+	return nodes.Span{Start: nodes.Position{Row: row, Col: start}, End: nodes.Position{Row: row, Col: end}}
+}
+
+func TestDiagnose(t *testing.T) {
+	err := SyntaxErrors{
+		&UnexpectedToken{ActualToken: &lexer.Token{Type: lexer.TokenIntegerConstant, Value: "5", Row: 2, Col: 6, Span: 1}, ExpectedToken: lexer.TokenIdentifier},
+		&UnknownBuiltinFunc{ActualToken: &lexer.Token{Type: lexer.TokenIdentifier, Value: "Cuont", Row: 3, Col: 15, Span: 5}},
+	}
+	diagnostics := Diagnose(fmt.Errorf("parsing: %w", err))
+	assert.Len(t, diagnostics, 2)
+
+	assert.Equal(t, &Diagnostic{
+		Severity: SeverityError,
+		Code:     CodeUnexpectedToken,
+		Message:  `Unexpected "5" found`,
+		Span:     span(2, 6, 7),
+		Label:    `Did you mean "<identifier>"?`,
+	}, diagnostics[0])
+	assert.Equal(t, CodeUnknownBuiltinFunc, diagnostics[1].Code)
+	assert.Equal(t, []Fix{{Message: `Replace with "Count"`, Span: span(3, 15, 20), Replacement: "Count"}}, diagnostics[1].Fixes)
+
+	// a string spanning lines ends on the row of its closing quote
+	multiline := &UnexpectedToken{ActualToken: &lexer.Token{Type: lexer.TokenStringConstant, Value: "a\nlong", Row: 0, Col: 7, Span: 6, EndRow: 1, EndCol: 5}}
+	assert.Equal(t, nodes.Span{Start: nodes.Position{Row: 0, Col: 7}, End: nodes.Position{Row: 1, Col: 5}}, multiline.Diagnostic().Span)
+
+	semantic := &SemanticError{Span: span(0, 4, 7), Err: fmt.Errorf("%w: Bbo", testMissing), Suggestions: []string{"Bob"}}
+	d := Diagnose(semantic)[0]
+	assert.Equal(t, "V9999", d.Code)
+	assert.Equal(t, "Vertex missing: Bbo", d.Message)
+	assert.Equal(t, "Bob", d.Fixes[0].Replacement)
+	assert.Equal(t, `1:5: Vertex missing: Bbo; did you mean "Bob"?`, semantic.Error())
+
+	d = Diagnose(stderrors.New("Database is closed"))[0]
+	assert.Equal(t, &Diagnostic{Severity: SeverityError, Message: "Database is closed"}, d)
+}
+
+func TestRender(t *testing.T) {
+	d := &Diagnostic{
+		Severity:      SeverityError,
+		Code:          "V0103",
+		Message:       "Vertex already exist",
+		Span:          span(1, 7, 10),
+		Secondary:     []Label{{Span: span(0, 7, 10), Message: "first written here"}},
+		Notes:         []string{"vertex names are unique in a graph"},
+		SourceContext: "1\t|\tVertex Ann Person {}\n2\t|\tVertex Ann Person {}\n",
+	}
+
+	buffer := new(bytes.Buffer)
+	assert.NoError(t, Render(buffer, Plain, []*Diagnostic{d}))
+	// There are tab characters in the string
+	assert.Equal(t, `Error[V0103]: Vertex already exist
+1	|	Vertex Ann Person {}
+2	|	Vertex Ann Person {}
+		       ^^^--here
+		= 1:8: first written here
+		= note: vertex names are unique in a graph
+`, buffer.String())
+
+	buffer.Reset()
+	assert.NoError(t, Render(buffer, Coloured, []*Diagnostic{d}))
+	assert.Contains(t, buffer.String(), "\x1b[31mError[V0103]: Vertex already exist\n\x1b[0m")
+	assert.Contains(t, buffer.String(), "\x1b[34m^^^--here\x1b[0m")
+
+	// without its source the position is in the header
+	buffer.Reset()
+	d = &Diagnostic{Severity: SeverityWarning, Message: "Vertex missing", Span: span(0, 4, 7), Fixes: []Fix{{Replacement: "Bob"}}}
+	assert.NoError(t, Render(buffer, Plain, []*Diagnostic{d}))
+	assert.Equal(t, "Warning: 1:5: Vertex missing\n\t\t= Did you mean \"Bob\"?\n", buffer.String())
+}
+
+func TestRenderJSON(t *testing.T) {
+	d := &Diagnostic{
+		Severity:      SeverityError,
+		Code:          "V0104",
+		Message:       "Vertex missing",
+		Span:          span(0, 4, 7),
+		Fixes:         []Fix{{Message: `Replace with "Bob"`, Span: span(0, 4, 7), Replacement: "Bob"}},
+		SourceContext: "1\t|\tAnn Bbo\n",
+	}
+
+	buffer := new(bytes.Buffer)
+	assert.NoError(t, Render(buffer, JSON, []*Diagnostic{d}))
+	assert.JSONEq(t, `[{
+		"severity": "error",
+		"code": "V0104",
+		"message": "Vertex missing",
+		"span": {"start": {"row": 0, "col": 4}, "end": {"row": 0, "col": 7}},
+		"fixes": [{"message": "Replace with \"Bob\"", "span": {"start": {"row": 0, "col": 4}, "end": {"row": 0, "col": 7}}, "replacement": "Bob"}]
+	}]`, buffer.String())
+
+	buffer.Reset()
+	assert.NoError(t, Render(buffer, JSON, nil))
+	assert.Equal(t, "[]\n", buffer.String())
+}
@@ -3,11 +3,11 @@ package errors
 import (
 	"bytes"
 	"fmt"
+	"slices"
 	"strings"
 
 	"github.com/Jintumoni/vortex/lexer"
 	"github.com/Jintumoni/vortex/nodes"
-	"github.com/fatih/color"
 )
 
 type UnexpectedToken struct {
@@ -18,34 +18,27 @@ type UnexpectedToken struct {
 }
 
 func (e *UnexpectedToken) Error() string {
-	buffer := new(bytes.Buffer)
-	buffer.WriteString(color.RedString(fmt.Sprintf("Error: Unexpected \"%s\" found\n", e.ActualToken.Value)))
-
-	buffer.WriteString(e.SourceContext)
-
-	buffer.WriteString(strings.Repeat("\t", 2))
-	buffer.WriteString(strings.Repeat(" ", e.ActualToken.Col))
-	// buffer.WriteString("\033[31m")
-	buffer.WriteString(color.BlueString(strings.Repeat("^", e.ActualToken.Span)))
-	buffer.WriteString(color.BlueString("--"))
+	return render(e.Diagnostic())
+}
 
+func (e *UnexpectedToken) Diagnostic() *Diagnostic {
+	d := tokenDiagnostic(CodeUnexpectedToken, "Unexpected", e.ActualToken, e.SourceContext)
 	if e.SuggestedTokens != nil {
-		buffer.WriteString(color.BlueString(fmt.Sprintf("Expected one of: ")))
-		for i, t := range e.SuggestedTokens {
-			if i > 0 {
-				buffer.WriteString(color.BlueString(", "))
-			}
-			buffer.WriteString(color.BlueString(fmt.Sprintf("\"%s\"", t)))
-		}
+		d.Label = expected(e.SuggestedTokens)
 	} else if e.ExpectedToken != 0 {
-		buffer.WriteString(color.BlueString(fmt.Sprintf("Did you mean \"%s\"?", e.ExpectedToken)))
-	} else {
-		buffer.WriteString(color.BlueString("here"))
+		d.Label = fmt.Sprintf("Did you mean \"%s\"?", e.ExpectedToken)
+	}
+	if e.expects(lexer.TokenIdentifier) {
+		if _, ok := lexer.ReservedKeywords[e.ActualToken.Value]; ok {
+			d.Notes = append(d.Notes, fmt.Sprintf("\"%s\" is a reserved word and cannot be used as a name", e.ActualToken.Value))
+		}
 	}
-	// buffer.WriteString("\033[0m")
-	buffer.WriteString("\n")
+	return d
+}
 
-	return buffer.String()
+// expects reports whether the token was one the parser expected
+func (e *UnexpectedToken) expects(tokenType lexer.TokenType) bool {
+	return e.ExpectedToken == tokenType || slices.Contains(e.SuggestedTokens, tokenType)
 }
 
 type UnknownEdgeType struct {
@@ -54,27 +47,13 @@ type UnknownEdgeType struct {
 }
 
 func (e *UnknownEdgeType) Error() string {
-	buffer := new(bytes.Buffer)
-	buffer.WriteString(color.RedString(fmt.Sprintf("Error: Unknown \"%s\" found\n", e.ActualToken.Value)))
-
-	buffer.WriteString(e.SourceContext)
-
-	buffer.WriteString(strings.Repeat("\t", 2))
-	buffer.WriteString(strings.Repeat(" ", e.ActualToken.Col))
-
-	buffer.WriteString(color.BlueString(strings.Repeat("^", e.ActualToken.Span)))
-	buffer.WriteString(color.BlueString("--"))
-
-	buffer.WriteString(color.BlueString(fmt.Sprintf("Expected one of: ")))
-	for i, t := range nodes.GetAllEdgeTypes() {
-		if i > 0 {
-			buffer.WriteString(color.BlueString(", "))
-		}
-		buffer.WriteString(color.BlueString(fmt.Sprintf("\"%s\"", t)))
-	}
-	buffer.WriteString("\n")
+	return render(e.Diagnostic())
+}
 
-	return buffer.String()
+func (e *UnknownEdgeType) Diagnostic() *Diagnostic {
+	d := tokenDiagnostic(CodeUnknownEdgeType, "Unknown", e.ActualToken, e.SourceContext)
+	d.Label = expected(nodes.GetAllEdgeTypes())
+	return d
 }
 
 type UnknownStatement struct {
@@ -83,27 +62,13 @@ type UnknownStatement struct {
 }
 
 func (e *UnknownStatement) Error() string {
-	buffer := new(bytes.Buffer)
-	buffer.WriteString(color.RedString(fmt.Sprintf("Error: Unknown \"%s\" found\n", e.ActualToken.Value)))
-
-	buffer.WriteString(e.SourceContext)
-
-	buffer.WriteString(strings.Repeat("\t", 2))
-	buffer.WriteString(strings.Repeat(" ", e.ActualToken.Col))
-
-	buffer.WriteString(color.BlueString(strings.Repeat("^", e.ActualToken.Span)))
-	buffer.WriteString(color.BlueString("--"))
-
-	buffer.WriteString(color.BlueString(fmt.Sprintf("Expected one of: ")))
-	for i, t := range lexer.GetAllStatementTypes() {
-		if i > 0 {
-			buffer.WriteString(color.BlueString(", "))
-		}
-		buffer.WriteString(color.BlueString(fmt.Sprintf("\"%s\"", t)))
-	}
-	buffer.WriteString("\n")
+	return render(e.Diagnostic())
+}
 
-	return buffer.String()
+func (e *UnknownStatement) Diagnostic() *Diagnostic {
+	d := tokenDiagnostic(CodeUnknownStatement, "Unknown", e.ActualToken, e.SourceContext)
+	d.Label = expected(lexer.GetAllStatementTypes())
+	return d
 }
 
 type UnknownBuiltinFunc struct {
@@ -112,25 +77,75 @@ type UnknownBuiltinFunc struct {
 }
 
 func (e *UnknownBuiltinFunc) Error() string {
-	buffer := new(bytes.Buffer)
-	buffer.WriteString(color.RedString(fmt.Sprintf("Error: Unknown \"%s\" found\n", e.ActualToken.Value)))
+	return render(e.Diagnostic())
+}
 
-	buffer.WriteString(e.SourceContext)
+func (e *UnknownBuiltinFunc) Diagnostic() *Diagnostic {
+	d := tokenDiagnostic(CodeUnknownBuiltinFunc, "Unknown", e.ActualToken, e.SourceContext)
+	d.Fixes = fixes(d.Span, e.Suggestions())
+	return d
+}
 
-	buffer.WriteString(strings.Repeat("\t", 2))
-	buffer.WriteString(strings.Repeat(" ", e.ActualToken.Col))
+// Suggestions returns the built-in functions closest to the unknown one
+func (e *UnknownBuiltinFunc) Suggestions() []string {
+	var names []string
+	for _, t := range nodes.GetAllFuncTypes() {
+		names = append(names, t.String())
+	}
+	return Suggest(e.ActualToken.Value, names)
+}
 
-	buffer.WriteString(color.BlueString(strings.Repeat("^", e.ActualToken.Span)))
-	buffer.WriteString(color.BlueString("--"))
+// tokenDiagnostic describes a token the parser did not expect, eg: Unknown "Foo" found
+func tokenDiagnostic(code, problem string, token *lexer.Token, sourceContext string) *Diagnostic {
+	d := &Diagnostic{
+		Severity: SeverityError,
+		Code:     code,
+		Message:  fmt.Sprintf("%s \"%s\" found", problem, token.Value),
+		Span: nodes.Span{
+			Start: nodes.Position{Row: token.Row, Col: token.Col},
+			End:   nodes.Position{Row: token.Row, Col: token.Col + token.Span},
+		},
+		SourceContext: sourceContext,
+	}
+	if token.EndRow > token.Row {
+		// a string spanning lines, up to its closing quote
+		d.Span.End = nodes.Position{Row: token.EndRow, Col: token.EndCol}
+	}
+	return d
+}
 
-	buffer.WriteString(color.BlueString(fmt.Sprintf("Expected one of: ")))
-	for i, t := range nodes.GetAllFuncTypes() {
-		if i > 0 {
-			buffer.WriteString(color.BlueString(", "))
-		}
-		buffer.WriteString(color.BlueString(fmt.Sprintf("\"%s\"", t)))
+// expected lists the tokens or names the parser expected, eg: Expected one of: "A", "B"
+func expected[T fmt.Stringer](values []T) string {
+	names := make([]string, 0, len(values))
+	for _, v := range values {
+		names = append(names, fmt.Sprintf("\"%s\"", v))
+	}
+	return "Expected one of: " + strings.Join(names, ", ")
+}
+
+// fixes replaces the span with each of the suggestions
+func fixes(span nodes.Span, suggestions []string) []Fix {
+	var fixes []Fix
+	for _, s := range suggestions {
+		fixes = append(fixes, Fix{Message: fmt.Sprintf("Replace with %q", s), Span: span, Replacement: s})
+	}
+	return fixes
+}
+
+// SyntaxErrors are the errors found by the parser in one pass over a
+// program, in the order of the source. Each one is rendered with its own
+// source context.
+type SyntaxErrors []error
+
+func (e SyntaxErrors) Error() string {
+	buffer := new(bytes.Buffer)
+	for _, err := range e {
+		buffer.WriteString(err.Error())
 	}
-	buffer.WriteString("\n")
 
 	return buffer.String()
 }
+
+func (e SyntaxErrors) Unwrap() []error {
+	return e
+}
@@ -0,0 +1,159 @@
+package errors
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/fatih/color"
+	"github.com/mattn/go-isatty"
+)
+
+// Format is how diagnostics are rendered
+type Format int
+
+const (
+	Plain    Format = iota // text
+	Coloured               // text with ANSI colours, for terminals
+	JSON                   // an array of the diagnostics, for tools
+)
+
+// TextFormat returns Coloured when f is a terminal and colours are not
+// disabled with the NO_COLOR environment variable, Plain otherwise
+func TextFormat(f *os.File) Format {
+	if os.Getenv("NO_COLOR") != "" || os.Getenv("TERM") == "dumb" {
+		return Plain
+	}
+	if isatty.IsTerminal(f.Fd()) || isatty.IsCygwinTerminal(f.Fd()) {
+		return Coloured
+	}
+	return Plain
+}
+
+// Render writes the diagnostics to w in the format
+func Render(w io.Writer, format Format, diagnostics []*Diagnostic) error {
+	if format == JSON {
+		if diagnostics == nil {
+			diagnostics = []*Diagnostic{}
+		}
+		return json.NewEncoder(w).Encode(diagnostics)
+	}
+
+	buffer := new(bytes.Buffer)
+	for _, d := range diagnostics {
+		d.text(buffer, format == Coloured)
+	}
+	_, err := w.Write(buffer.Bytes())
+	return err
+}
+
+// render is the text of the diagnostic returned by Error, coloured unless
+// colours are disabled with color.NoColor
+func render(d *Diagnostic) string {
+	buffer := new(bytes.Buffer)
+	d.text(buffer, !color.NoColor)
+	return buffer.String()
+}
+
+// text writes the header of the diagnostic followed by its source with the
+// span underlined, eg:
+//
+//	Error[V0104]: Vertex missing
+//	1	|	Relation LivesIn { Ann Lundon }
+//			                       ^^^^^^--Did you mean "London"?
+func (d *Diagnostic) text(buffer *bytes.Buffer, coloured bool) {
+	severity := d.Severity.String()
+	if severity == "" {
+		severity = SeverityError.String()
+	}
+	header := strings.ToUpper(severity[:1]) + severity[1:]
+	if d.Code != "" {
+		header += "[" + d.Code + "]"
+	}
+	header += ": "
+	if d.SourceContext == "" && !d.Span.IsZero() {
+		header += d.Span.String() + ": "
+	}
+	buffer.WriteString(paint(coloured, d.Severity.colour(), header+d.Message+"\n"))
+
+	label := d.Annotation()
+	if d.SourceContext != "" {
+		buffer.WriteString(d.SourceContext)
+		buffer.WriteString(strings.Repeat("\t", 2))
+		buffer.WriteString(strings.Repeat(" ", d.Span.Start.Col))
+		if label == "" {
+			label = "here"
+		}
+		buffer.WriteString(paint(coloured, color.FgBlue, strings.Repeat("^", d.width())+"--"+label))
+		buffer.WriteString("\n")
+	} else if label != "" {
+		d.annotate(buffer, coloured, label)
+	}
+
+	for _, l := range d.Secondary {
+		d.annotate(buffer, coloured, fmt.Sprintf("%s: %s", l.Span, l.Message))
+	}
+	for _, note := range d.Notes {
+		d.annotate(buffer, coloured, "note: "+note)
+	}
+}
+
+// annotate writes a line explaining the diagnostic under its source
+func (d *Diagnostic) annotate(buffer *bytes.Buffer, coloured bool, annotation string) {
+	buffer.WriteString(strings.Repeat("\t", 2))
+	buffer.WriteString(paint(coloured, color.FgBlue, "= "+annotation))
+	buffer.WriteString("\n")
+}
+
+// Annotation returns the text shown under the span, the replacements of the
+// fixes when the diagnostic has no label of its own
+func (d *Diagnostic) Annotation() string {
+	if d.Label != "" || len(d.Fixes) == 0 {
+		return d.Label
+	}
+	replacements := make([]string, 0, len(d.Fixes))
+	for _, f := range d.Fixes {
+		replacements = append(replacements, f.Replacement)
+	}
+	return fmt.Sprintf("Did you mean %s?", quoted(replacements))
+}
+
+// width returns the number of characters to underline, up to the end of the
+// first row of the span
+func (d *Diagnostic) width() int {
+	if d.Span.End.Row == d.Span.Start.Row {
+		return max(1, d.Span.End.Col-d.Span.Start.Col)
+	}
+
+	lines := strings.Split(strings.TrimSuffix(d.SourceContext, "\n"), "\n")
+	line := lines[len(lines)-1]
+	if i := strings.LastIndex(line, "|\t"); i >= 0 {
+		line = line[i+2:]
+	}
+	return max(1, utf8.RuneCountInString(line)-d.Span.Start.Col)
+}
+
+func (s Severity) colour() color.Attribute {
+	switch s {
+	case SeverityWarning:
+		return color.FgYellow
+	case SeverityNote:
+		return color.FgCyan
+	default:
+		return color.FgRed
+	}
+}
+
+// paint colours the text when coloured is set, whatever color.NoColor is
+func paint(coloured bool, attribute color.Attribute, text string) string {
+	if !coloured {
+		return text
+	}
+	c := color.New(attribute)
+	c.EnableColor()
+	return c.Sprint(text)
+}
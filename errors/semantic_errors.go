@@ -0,0 +1,48 @@
+package errors
+
+import (
+	"fmt"
+
+	"github.com/Jintumoni/vortex/nodes"
+)
+
+// SemanticError is an error of a statement that parsed, caused by the part
+// of the source at Span. Once its SourceContext is set it is rendered like
+// the syntax errors, with the span underlined.
+type SemanticError struct {
+	SourceContext string // the lines up to the first row of the span, empty when the source is not known
+	Span          nodes.Span
+	Err           error
+	Suggestions   []string // names close to a misspelt one at Span
+	Secondary     []Label  // other parts of the source the error is about, eg: a first definition
+	Notes         []string
+}
+
+func (e *SemanticError) Error() string {
+	if e.SourceContext == "" {
+		message := e.Err.Error() + didYouMean(e.Suggestions)
+		if e.Span.IsZero() {
+			return message
+		}
+		return fmt.Sprintf("%s: %s", e.Span, message)
+	}
+
+	return render(e.Diagnostic())
+}
+
+func (e *SemanticError) Unwrap() error {
+	return e.Err
+}
+
+func (e *SemanticError) Diagnostic() *Diagnostic {
+	return &Diagnostic{
+		Severity:      SeverityError,
+		Code:          codeOf(e.Err),
+		Message:       e.Err.Error(),
+		Span:          e.Span,
+		Secondary:     e.Secondary,
+		Notes:         e.Notes,
+		Fixes:         fixes(e.Span, e.Suggestions),
+		SourceContext: e.SourceContext,
+	}
+}
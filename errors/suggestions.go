@@ -0,0 +1,104 @@
+package errors
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// Suggest returns the names closest to a misspelt name, the ones at the
+// smallest edit distance. A name is only suggested when at most a third of
+// the characters of the misspelt name have to change, and at least one of
+// them is kept.
+func Suggest(name string, names []string) []string {
+	misspelt := []rune(name)
+	best := max(1, len(misspelt)/3)
+	var suggestions []string
+	for _, n := range names {
+		d := distance(misspelt, []rune(n))
+		if n == name || d >= len(misspelt) || d > best {
+			continue
+		}
+		if d < best {
+			best, suggestions = d, nil
+		}
+		suggestions = append(suggestions, n)
+	}
+
+	sort.Strings(suggestions)
+	return compact(suggestions)
+}
+
+// DidYouMean ends the message of an error caused by a misspelt name with the
+// names closest to it, eg: `; did you mean "FriendsWith"?`. It is empty when
+// no name is close enough.
+func DidYouMean(name string, names []string) string {
+	return didYouMean(Suggest(name, names))
+}
+
+func didYouMean(suggestions []string) string {
+	if len(suggestions) == 0 {
+		return ""
+	}
+	return fmt.Sprintf("; did you mean %s?", quoted(suggestions))
+}
+
+// quoted lists the names as "A", "B" or "C"
+func quoted(names []string) string {
+	buffer := new(strings.Builder)
+	for i, n := range names {
+		switch {
+		case i == 0:
+		case i == len(names)-1:
+			buffer.WriteString(" or ")
+		default:
+			buffer.WriteString(", ")
+		}
+		fmt.Fprintf(buffer, "%q", n)
+	}
+	return buffer.String()
+}
+
+// distance is the number of characters to insert, delete, replace or swap
+// with their neighbour to turn a into b. A change of case costs nothing,
+// so "person" is as close to "Person" as a name can be without being it.
+func distance(a, b []rune) int {
+	// rows i-2, i-1 and i of the table of the distances between the prefixes
+	previous2 := make([]int, len(b)+1)
+	previous := make([]int, len(b)+1)
+	current := make([]int, len(b)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+
+	for i := 1; i <= len(a); i++ {
+		current[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if same(a[i-1], b[j-1]) {
+				cost = 0
+			}
+			current[j] = min(previous[j]+1, current[j-1]+1, previous[j-1]+cost)
+			if i > 1 && j > 1 && same(a[i-1], b[j-2]) && same(a[i-2], b[j-1]) {
+				current[j] = min(current[j], previous2[j-2]+1)
+			}
+		}
+		previous2, previous, current = previous, current, previous2
+	}
+	return previous[len(b)]
+}
+
+func same(a, b rune) bool {
+	return unicode.ToLower(a) == unicode.ToLower(b)
+}
+
+func compact(names []string) []string {
+	var unique []string
+	for i, n := range names {
+		if i == 0 || n != names[i-1] {
+			unique = append(unique, n)
+		}
+	}
+	return unique
+}
@@ -0,0 +1,39 @@
+package errors
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSuggest(t *testing.T) {
+	names := []string{"Person", "City", "FriendsWith", "LivesIn", "Count", "Sum", "Avg"}
+
+	tests := []struct {
+		name        string
+		suggestions []string
+	}{
+		{"Persn", []string{"Person"}},
+		{"person", []string{"Person"}},
+		{"FriendWith", []string{"FriendsWith"}},
+		{"Cuont", []string{"Count"}},
+		{"Sim", []string{"Sum"}},
+		{"Av", []string{"Avg"}},
+		{"Person", nil}, // not misspelt
+		{"Region", nil},
+		{"X", nil},
+		{"", nil},
+	}
+	for _, test := range tests {
+		assert.Equal(t, test.suggestions, Suggest(test.name, names), test.name)
+	}
+
+	assert.Equal(t, []string{"Amy", "Ann"}, Suggest("Any", []string{"Bob", "Ann", "Amy", "Ann"}))
+}
+
+func TestDidYouMean(t *testing.T) {
+	assert.Equal(t, `; did you mean "FriendsWith"?`, DidYouMean("FriendWith", []string{"FriendsWith", "LivesIn"}))
+	assert.Equal(t, `; did you mean "Amy" or "Ann"?`, DidYouMean("Any", []string{"Ann", "Amy"}))
+	assert.Equal(t, `; did you mean "Ab", "Ax" or "Ay"?`, DidYouMean("Az", []string{"Ay", "Ab", "Ax"}))
+	assert.Equal(t, "", DidYouMean("Country", []string{"Person"}))
+}
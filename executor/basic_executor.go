@@ -1,8 +1,16 @@
 package executor
 
 import (
+	"context"
 	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"text/tabwriter"
 
+	vortexerrors "github.com/Jintumoni/vortex/errors"
 	"github.com/Jintumoni/vortex/manager"
 	"github.com/Jintumoni/vortex/nodes"
 	"github.com/Jintumoni/vortex/parser"
@@ -17,37 +25,285 @@ var (
 type Executor struct {
 	AppManager *manager.AppManager
 	Parser     parser.ParserInterface
+	Output     io.Writer       // query results are written here
+	Limits     visitors.Limits // work every query may do before it is stopped
 }
 
 func NewExecutor(appManager *manager.AppManager, parser parser.ParserInterface) *Executor {
-	return &Executor{AppManager: appManager, Parser: parser}
+	return &Executor{AppManager: appManager, Parser: parser, Output: os.Stdout}
 }
 
+// Execute parses the program and runs every statement in it
 func (q *Executor) Execute() error {
-	root, err := q.Parser.Parse()
+	return q.ExecuteContext(context.Background())
+}
+
+// ExecuteContext is Execute stopped as soon as ctx is done, even in the
+// middle of a query. A query exceeding the Limits of the executor fails
+// with a *visitors.LimitError.
+//
+// Every statement is parsed, run and dropped before the next one is read,
+// so a program of any size runs in constant memory. The statements before
+// the first one that fails to parse or to run are kept. After a syntax
+// error the rest of the program is only parsed, all of its syntax errors
+// are returned as vortexerrors.SyntaxErrors.
+func (q *Executor) ExecuteContext(ctx context.Context) error {
+	evaluator := visitors.NewEvaluator(q.AppManager)
+	evaluator.Limits = q.Limits
+
+	var syntaxErrors vortexerrors.SyntaxErrors
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		node, err := q.Parser.Next()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			syntaxErrors = append(syntaxErrors, err)
+			continue
+		}
+		if syntaxErrors != nil {
+			continue
+		}
+
+		result, err := q.run(ctx, evaluator, node, q.plan)
+		if err != nil {
+			return withSource(err, q.Parser.GetSourceContextAt)
+		}
+		if err := q.write(result); err != nil {
+			return err
+		}
+	}
+	if syntaxErrors != nil {
+		return syntaxErrors
+	}
+	return nil
+}
+
+// Statement is a parsed program that can be executed many times. The plans
+// of its queries are made on the first execution and reused afterwards,
+// until the cardinalities of the graph they were made from change.
+type Statement struct {
+	executor *Executor
+	program  *nodes.ProgramStatementNode
+	source   map[int]string // source context of every row of the program, shown by its errors
+	plans    map[*nodes.QueryStatementNode]visitors.Operator
+	plansMu  sync.Mutex
+	// generation of the statistics of the graph the plans were made from
+	generation int
+}
+
+// Prepare parses the program once, its $name parameters are set by every Execute.
+// The source of every statement is kept while the lexer still has it, so
+// the errors of its executions show it like the ones of Execute.
+func (q *Executor) Prepare() (*Statement, error) {
+	program := &nodes.ProgramStatementNode{}
+	source := make(map[int]string)
+	var syntaxErrors vortexerrors.SyntaxErrors
+	for {
+		node, err := q.Parser.Next()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			syntaxErrors = append(syntaxErrors, err)
+			continue
+		}
+		program.Children = append(program.Children, node)
+
+		span := node.GetSpan()
+		for row := span.Start.Row; row <= span.End.Row; row++ {
+			if _, ok := source[row]; !ok {
+				source[row] = q.Parser.GetSourceContextAt(row)
+			}
+		}
+	}
+	if syntaxErrors != nil {
+		return nil, syntaxErrors
+	}
+	if n := len(program.Children); n > 0 {
+		program.Span = nodes.Span{Start: program.Children[0].GetSpan().Start, End: program.Children[n-1].GetSpan().End}
+	}
+
+	return &Statement{
+		executor:   q,
+		program:    program,
+		source:     source,
+		plans:      make(map[*nodes.QueryStatementNode]visitors.Operator),
+		generation: q.AppManager.Generation(),
+	}, nil
+}
+
+// Result is the outcome of one statement of a program
+type Result struct {
+	Statement nodes.ASTNode
+	Rows      *visitors.ResultSet // rows of a query, a profile or a Call without Write, nil otherwise
+	Plan      visitors.Operator   // plan of an Explain or a Profile, nil otherwise
+	Profile   visitors.Profile    // work done by the plan of a Profile, nil otherwise
+}
+
+// Execute runs every statement of the program with the given parameter values
+// and writes their results to the Output of the executor
+func (s *Statement) Execute(params map[string]any) error {
+	return s.Run(context.Background(), params, s.executor.write)
+}
+
+// ReadOnly reports whether the program runs without changing the graph
+func (s *Statement) ReadOnly() bool {
+	for _, node := range s.program.Children {
+		switch n := node.(type) {
+		case *nodes.QueryStatementNode, *nodes.ExplainStatementNode, *nodes.ProfileStatementNode:
+		case *nodes.CallStatementNode:
+			if n.Write != nil {
+				return false
+			}
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// Run runs every statement of the program with the given parameter values and
+// calls emit with the result of each one. It stops at the first statement that
+// fails, ctx is checked before every statement and while a query runs.
+func (s *Statement) Run(ctx context.Context, params map[string]any, emit func(*Result) error) error {
+	q := s.executor
+	parameters, err := visitors.Parameters(params)
 	if err != nil {
 		return err
 	}
 
-	visitor := visitors.NewVisualizer()
-
-	for _, node := range root.(*nodes.ProgramStatementNode).Children {
-		switch node.(type) {
-		case *nodes.SchemaDefNode:
-			q.AppManager.WriteSchema(node.(*nodes.SchemaDefNode))
-		case *nodes.EdgeDefNode:
-			q.AppManager.WriteEdge(node.(*nodes.EdgeDefNode))
-		case *nodes.RelationInitNode:
-			q.AppManager.WriteRelation(node.(*nodes.RelationInitNode))
-		case *nodes.VertexInitNode:
-			q.AppManager.WriteVertex(node.(*nodes.VertexInitNode))
-		case *nodes.QueryStatementNode:
-			root.Accept(visitor)
-		default:
-			return UnknownRootNode
+	evaluator := visitors.NewEvaluator(q.AppManager)
+	evaluator.Parameters = parameters
+	evaluator.Limits = q.Limits
+
+	for _, node := range s.program.Children {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		result, err := q.run(ctx, evaluator, node, s.plan)
+		if err != nil {
+			return withSource(err, s.sourceAt)
+		}
+		if err := emit(result); err != nil {
+			return err
 		}
 	}
 	// data, err := json.Marshal(root)
 	// fileio.WriteToFile(config.SchemaDefPath, bytes.NewReader(data))
 	return nil
 }
+
+// run runs one statement of a program, the plans of its queries are made by plan
+func (q *Executor) run(ctx context.Context, evaluator *visitors.Evaluator, node nodes.ASTNode, plan func(*nodes.QueryStatementNode) (visitors.Operator, error)) (*Result, error) {
+	var err error
+	result := &Result{Statement: node}
+	switch node.(type) {
+	case *nodes.SchemaDefNode:
+		err = q.AppManager.WriteSchema(node.(*nodes.SchemaDefNode))
+	case *nodes.EdgeDefNode:
+		err = q.AppManager.WriteEdge(node.(*nodes.EdgeDefNode))
+	case *nodes.RelationInitNode:
+		err = q.AppManager.WriteRelation(node.(*nodes.RelationInitNode))
+	case *nodes.VertexInitNode:
+		err = q.AppManager.WriteVertex(node.(*nodes.VertexInitNode))
+	case *nodes.QueryStatementNode:
+		var operator visitors.Operator
+		if operator, err = plan(node.(*nodes.QueryStatementNode)); err == nil {
+			result.Rows, err = evaluator.RunContext(ctx, operator)
+		}
+	case *nodes.ExplainStatementNode:
+		result.Plan, err = plan(node.(*nodes.ExplainStatementNode).Query)
+	case *nodes.ProfileStatementNode:
+		if result.Plan, err = plan(node.(*nodes.ProfileStatementNode).Query); err == nil {
+			result.Rows, result.Profile, err = evaluator.ProfileContext(ctx, result.Plan)
+		}
+	case *nodes.CallStatementNode:
+		result.Rows, err = evaluator.Call(node.(*nodes.CallStatementNode))
+	default:
+		return nil, UnknownRootNode
+	}
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// withSource sets the source context of a semantic error to the lines of
+// the statement that caused it, as returned by source for its first row
+func withSource(err error, source func(row int) string) error {
+	var semantic *vortexerrors.SemanticError
+	if errors.As(err, &semantic) && !semantic.Span.IsZero() && semantic.SourceContext == "" {
+		semantic.SourceContext = source(semantic.Span.Start.Row)
+	}
+	return err
+}
+
+// sourceAt returns the source context of a row of the program, empty when
+// the lexer no longer had it once the statement on the row was parsed
+func (s *Statement) sourceAt(row int) string {
+	return s.source[row]
+}
+
+// plan makes the plan of a query run once
+func (q *Executor) plan(query *nodes.QueryStatementNode) (visitors.Operator, error) {
+	return visitors.NewPlanner(q.AppManager).Plan(query)
+}
+
+// plan returns the plan of the query, made on its first execution and made
+// again once the graph changed
+func (s *Statement) plan(query *nodes.QueryStatementNode) (visitors.Operator, error) {
+	s.plansMu.Lock()
+	defer s.plansMu.Unlock()
+
+	if generation := s.executor.AppManager.Generation(); generation != s.generation {
+		clear(s.plans)
+		s.generation = generation
+	}
+	if plan, ok := s.plans[query]; ok {
+		return plan, nil
+	}
+	plan, err := visitors.NewPlanner(s.executor.AppManager).Plan(query)
+	if err != nil {
+		return nil, err
+	}
+	s.plans[query] = plan
+	return plan, nil
+}
+
+// write prints the rows of a result as a table, followed by its plan
+func (q *Executor) write(result *Result) error {
+	if result.Rows != nil {
+		if err := q.writeResult(result.Rows); err != nil {
+			return err
+		}
+	}
+	if result.Plan == nil {
+		return nil
+	}
+
+	visualizer := visitors.NewVisualizer()
+	visualizer.Output = q.Output
+	if result.Profile != nil {
+		visualizer.VisualizeProfile(result.Plan, result.Profile)
+	} else {
+		visualizer.VisualizePlan(result.Plan)
+	}
+	return nil
+}
+
+// writeResult prints the result of a query as a table
+func (q *Executor) writeResult(result *visitors.ResultSet) error {
+	w := tabwriter.NewWriter(q.Output, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, strings.Join(result.Columns, "\t"))
+	for _, row := range result.Rows {
+		values := make([]string, 0, len(row))
+		for _, value := range row {
+			values = append(values, visitors.FormatValue(value))
+		}
+		fmt.Fprintln(w, strings.Join(values, "\t"))
+	}
+	return w.Flush()
+}
@@ -0,0 +1,181 @@
+package executor
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/Jintumoni/vortex/errors"
+	"github.com/Jintumoni/vortex/lexer"
+	"github.com/Jintumoni/vortex/manager"
+	"github.com/Jintumoni/vortex/nodes"
+	"github.com/Jintumoni/vortex/parser"
+	"github.com/stretchr/testify/assert"
+)
+
+func execute(appManager *manager.AppManager, program io.Reader) (string, error) {
+	output := new(bytes.Buffer)
+	q := NewExecutor(appManager, parser.NewParser(lexer.NewLexer(program)))
+	q.Output = output
+	err := q.Execute()
+	return output.String(), err
+}
+
+func TestExecuteRunsStatementsBeforeASyntaxError(t *testing.T) {
+	appManager := manager.NewAppManager()
+	_, err := execute(appManager, strings.NewReader(`
+Schema Person { name string }
+Vertex Ann Person { .name = "Ann" }
+Vertex Bob Person { .name = }
+Vertex Cid Person { .name = "Cid" }
+`))
+	var unexpected *errors.UnexpectedToken
+	assert.ErrorAs(t, err, &unexpected)
+	assert.Equal(t, 3, unexpected.ActualToken.Row)
+
+	output, err := execute(appManager, strings.NewReader(`Query Person Return .name`))
+	assert.NoError(t, err)
+	assert.Equal(t, ".name\nAnn\n", output)
+}
+
+func TestExecuteReportsEverySyntaxError(t *testing.T) {
+	appManager := manager.NewAppManager()
+	_, err := execute(appManager, strings.NewReader(`
+Schema Person { name string }
+Vertex Ann Person { .name = }
+Vertex Bob Person { .name = "Bob" }
+Vertex Cid Person { .name 5 }
+Query Person {
+`))
+	var syntaxErrors errors.SyntaxErrors
+	assert.ErrorAs(t, err, &syntaxErrors)
+	var rows []int
+	for _, err := range syntaxErrors {
+		var unexpected *errors.UnexpectedToken
+		assert.ErrorAs(t, err, &unexpected)
+		rows = append(rows, unexpected.ActualToken.Row)
+	}
+	assert.Equal(t, []int{2, 4, 6}, rows)
+
+	// the statements after the first syntax error are not run
+	output, err := execute(appManager, strings.NewReader(`Query Count(Person)`))
+	assert.NoError(t, err)
+	assert.Equal(t, "Count(Person)\n0\n", output)
+}
+
+func TestExecuteStreams(t *testing.T) {
+	const n = 20000
+	reader, writer := io.Pipe()
+	go func() {
+		fmt.Fprintln(writer, "Schema Person { name string }")
+		for i := 0; i < n; i++ {
+			fmt.Fprintf(writer, "Vertex V%d Person { .name = \"V%d\" }\n", i, i)
+		}
+		fmt.Fprintln(writer, "Query Count(Person)")
+		writer.Close()
+	}()
+
+	output, err := execute(manager.NewAppManager(), reader)
+	assert.NoError(t, err)
+	assert.Equal(t, fmt.Sprintf("Count(Person)\n%d\n", n), output)
+}
+
+func TestExecuteShowsTheSourceOfSemanticErrors(t *testing.T) {
+	_, err := execute(manager.NewAppManager(), strings.NewReader(`Schema Person { age int }
+Vertex Ann Person { .age = 30 }
+Vertex Bob Person {
+  .age = "old"
+}
+Query Person`))
+
+	var semantic *errors.SemanticError
+	assert.ErrorAs(t, err, &semantic)
+	assert.ErrorIs(t, err, manager.PropertyTypeMismatch)
+	// There are tab characters in the string
+	assert.Equal(t, `Error[V0111]: Property value does not match its type in the schema
+2	|	Vertex Ann Person { .age = 30 }
+3	|	Vertex Bob Person {
+4	|	  .age = "old"
+		         ^^^^^--here
+		= 1:17: age is declared as int here
+`, err.Error())
+
+	_, err = execute(manager.NewAppManager(), strings.NewReader(`Schema Person { age int } Vertex Ann Person { .age = 30 }
+Query Person { .age + 1 > "a" }`))
+	assert.ErrorAs(t, err, &semantic)
+	assert.Equal(t, `Error[V0205]: Type mismatch: int > string
+1	|	Schema Person { age int } Vertex Ann Person { .age = 30 }
+2	|	Query Person { .age + 1 > "a" }
+		               ^^^^^^^^^^^^^^--here
+`, err.Error())
+
+	// a string spanning lines ends on the row of its closing quote
+	_, err = execute(manager.NewAppManager(), strings.NewReader("Schema Person { age int } Vertex Ann Person { .age = 30 }\nQuery Person { .age > `a\nlong` }"))
+	assert.ErrorAs(t, err, &semantic)
+	assert.Equal(t, nodes.Span{Start: nodes.Position{Row: 1, Col: 15}, End: nodes.Position{Row: 2, Col: 5}}, semantic.Span)
+	assert.Equal(t, `Error[V0205]: Type mismatch: int > string
+1	|	Schema Person { age int } Vertex Ann Person { .age = 30 }
+2	|	Query Person { .age > `+"`a"+`
+		               ^^^^^^^^^--here
+`, err.Error())
+}
+
+func TestStatementShowsTheSourceOfSemanticErrors(t *testing.T) {
+	appManager := manager.NewAppManager()
+	_, err := execute(appManager, strings.NewReader(`Schema Person { age int } Vertex Ann Person { .age = 30 }`))
+	assert.NoError(t, err)
+
+	statement, err := NewExecutor(appManager, parser.NewParser(lexer.NewLexer(strings.NewReader(`Query Person
+Query Person {
+  .age + 1 > "a"
+}`)))).Prepare()
+	assert.NoError(t, err)
+	for i := 0; i < 2; i++ {
+		err = statement.Run(context.Background(), nil, func(*Result) error { return nil })
+		var semantic *errors.SemanticError
+		assert.ErrorAs(t, err, &semantic)
+		// There are tab characters in the string
+		assert.Equal(t, `Error[V0205]: Type mismatch: int > string
+1	|	Query Person
+2	|	Query Person {
+3	|	  .age + 1 > "a"
+		  ^^^^^^^^^^^^^^--here
+`, err.Error())
+	}
+}
+
+func TestStatementPlansAgainAfterWrites(t *testing.T) {
+	appManager := manager.NewAppManager()
+	_, err := execute(appManager, strings.NewReader(`Schema Person { name string } Schema City { name string }
+Edge LivesIn OneWay
+Vertex Ann Person { .name = "Ann" }
+Vertex Paris City { .name = "Paris" } Vertex Rome City { .name = "Rome" } Vertex Oslo City { .name = "Oslo" }
+Relation LivesIn { Ann Paris }`))
+	assert.NoError(t, err)
+
+	statement, err := NewExecutor(appManager, parser.NewParser(lexer.NewLexer(strings.NewReader(`Explain Query Person { []LivesIn City }`)))).Prepare()
+	assert.NoError(t, err)
+	scan := func() string {
+		var leaf string
+		assert.NoError(t, statement.Run(context.Background(), nil, func(result *Result) error {
+			for op := result.Plan; op != nil; op = op.Inputs()[0] {
+				if leaf = op.String(); len(op.Inputs()) == 0 {
+					break
+				}
+			}
+			return nil
+		}))
+		return leaf
+	}
+	assert.Equal(t, "Scan Person", scan())
+
+	// with more people than cities the query starts from the cities
+	for i := 0; i < 5; i++ {
+		_, err := execute(appManager, strings.NewReader(fmt.Sprintf(`Vertex P%d Person { .name = "P%d" } Relation LivesIn { P%d Rome }`, i, i, i)))
+		assert.NoError(t, err)
+	}
+	assert.Equal(t, "Scan City", scan())
+}
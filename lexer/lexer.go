@@ -4,47 +4,149 @@ import (
 	"bytes"
 	"fmt"
 	"io"
+	"slices"
+	"strconv"
 	"strings"
 	"unicode"
+	"unicode/utf8"
+
+	"golang.org/x/text/unicode/norm"
 )
 
 type LexerInterface interface {
 	GetNextToken() *Token
 	GetSourceContext() string
+	GetSourceContextAt(row int) string
 }
 
+// the number of bytes read from the source at once
+const chunkSize = 4096
+
+// the number of lines shown by GetSourceContext, the current one included
+const contextLines = 3
+
+// the number of lines kept before the current one, the errors of a
+// statement that parsed can show its source while it is shorter
+const keptLines = 64
+
+// Lexer reads the source as it is lexed, so a script of any size can be
+// parsed statement by statement. Input holds the part of the source that
+// was read and not yet dropped: the lines before the current one are
+// dropped between tokens, only the last ones are kept for GetSourceContextAt.
 type Lexer struct {
-	Index        int
+	Index        int // position in Input
 	Row          int
 	Col          int
 	CurrentToken *Token
 	Input        []byte
+
+	reader    io.Reader
+	err       error
+	lineStart int // position of the current line in Input
+	lines     [keptLines][]byte
+
+	// the number of characters read, spans are measured with it
+	offset int
+
+	KeepComments bool      // the comments are added to Comments as they are skipped
+	Comments     []Comment // in the order they are written
+}
+
+// Comment is a // or /* */ comment of the source, its text includes the
+// delimiters. Rows and columns count like the ones of the tokens.
+type Comment struct {
+	Text   string
+	Row    int
+	Col    int
+	EndRow int // of the character after the comment
+	EndCol int
 }
 
 func NewLexer(r io.Reader) *Lexer {
-	data, _ := io.ReadAll(r)
 	return &Lexer{
 		Index:        0,
 		CurrentToken: nil,
-		Input:        data,
+		reader:       r,
+	}
+}
+
+// Err returns the error that stopped the reading of the source, nil when
+// it was read to its end. The lexer returns an invalid token once it fails.
+func (l *Lexer) Err() error {
+	if l.err == io.EOF {
+		return nil
+	}
+	return l.err
+}
+
+// available reads the source until n bytes from the current position are
+// in Input, it reports false when the source ends before
+func (l *Lexer) available(n int) bool {
+	for len(l.Input)-l.Index < n && l.err == nil {
+		l.Input = slices.Grow(l.Input, chunkSize)
+		read, err := l.reader.Read(l.Input[len(l.Input):cap(l.Input)])
+		l.Input = l.Input[:len(l.Input)+read]
+		l.err = err
 	}
+	return len(l.Input)-l.Index >= n
+}
+
+// discard drops the lines before the current one from Input once they fill a chunk
+func (l *Lexer) discard() {
+	if l.lineStart < chunkSize {
+		return
+	}
+	n := copy(l.Input, l.Input[l.lineStart:])
+	l.Input = l.Input[:n]
+	l.Index -= l.lineStart
+	l.lineStart = 0
 }
 
 func (l *Lexer) Peek() byte {
-	if l.Index+1 < len(l.Input) {
+	if l.available(2) {
 		return l.Input[l.Index+1]
 	}
 
 	return 0
 }
 
+// current returns the character at the current position, utf8.RuneError
+// when its bytes are not valid UTF-8
+func (l *Lexer) current() rune {
+	l.available(utf8.UTFMax)
+	r, _ := utf8.DecodeRune(l.Input[l.Index:])
+	return r
+}
+
+// advance moves past the character at the current position. Col and the
+// spans count characters, a combining mark is part of the character before it.
 func (l *Lexer) advance() {
-	l.Col++
-	if l.Input[l.Index] == '\n' {
+	l.available(utf8.UTFMax)
+	r, size := utf8.DecodeRune(l.Input[l.Index:])
+	l.Index += size
+	if r == '\n' {
+		line := &l.lines[l.Row%len(l.lines)]
+		*line = append((*line)[:0], l.Input[l.lineStart:l.Index-1]...)
+		l.lineStart = l.Index
 		l.Row++
 		l.Col = 0
+		l.offset++
+	} else if !unicode.Is(unicode.M, r) {
+		l.Col++
+		l.offset++
+	}
+}
+
+// Characters returns the number of characters of s, counted like the
+// columns: a combining mark is part of the character before it
+func Characters(s string) int {
+	n := 0
+	for _, r := range s {
+		if !unicode.Is(unicode.M, r) {
+			n++
+		}
 	}
-	l.Index++
+	return n
 }
 
 // Use this method only for single line tokens
@@ -56,86 +158,243 @@ func (l *Lexer) addSLToken(token TokenType, value string) *Token {
     Row: l.Row,
     Col: l.Col - len(value),
     Span: len(value),
+    EndRow: l.Row,
+    EndCol: l.Col,
   }
 }
 
+// invalidToken returns the invalid token of the span characters at row and col
+func invalidToken(row, col, span int) *Token {
+	return &Token{Type: TokenInvalid, Value: "INVALID", Row: row, Col: col, Span: span, EndRow: row, EndCol: col + span}
+}
+
 func (l *Lexer) ignoreSpace() {
-	for l.Index < len(l.Input) && unicode.IsSpace(rune(l.Input[l.Index])) {
+	for l.available(1) && unicode.IsSpace(l.current()) {
 		l.advance()
 	}
 }
 
+// skipTrivia skips the spaces and the // and /* */ comments before the next
+// token, it returns an invalid token for a block comment that is not closed
+func (l *Lexer) skipTrivia() *Token {
+	for {
+		l.ignoreSpace()
+		if !l.available(2) || l.Input[l.Index] != '/' {
+			return nil
+		}
+
+		row, col, start := l.Row, l.Col, l.Index
+		switch l.Peek() {
+		case '/':
+			for l.available(1) && l.Input[l.Index] != '\n' {
+				l.advance()
+			}
+		case '*':
+			l.advance()
+			l.advance()
+			for l.available(2) && !(l.Input[l.Index] == '*' && l.Input[l.Index+1] == '/') {
+				l.advance()
+			}
+			if !l.available(2) {
+				for l.available(1) {
+					l.advance()
+				}
+				return invalidToken(row, col, 2)
+			}
+			l.advance()
+			l.advance()
+		default:
+			return nil
+		}
+		if l.KeepComments {
+			text := strings.TrimRight(string(l.Input[start:l.Index]), " \t\r")
+			l.Comments = append(l.Comments, Comment{Text: text, Row: row, Col: col, EndRow: l.Row, EndCol: l.Col})
+		}
+	}
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
 func (l *Lexer) getNumberToken() *Token {
-	if !unicode.IsNumber(rune(l.Input[l.Index])) {
+	if !l.available(1) || !isDigit(l.Input[l.Index]) {
 		return l.addSLToken(TokenInvalid, "INVALID")
 	}
 
 	row, col := l.Row, l.Col
 	buffer := bytes.Buffer{}
-	for l.Index < len(l.Input) && unicode.IsNumber(rune(l.Input[l.Index])) {
+	for l.available(1) && isDigit(l.Input[l.Index]) {
 		buffer.WriteByte(l.Input[l.Index])
 		l.advance()
 	}
 
-	return &Token{TokenIntegerConstant, buffer.String(), row, col, buffer.Len()}
+	return &Token{Type: TokenIntegerConstant, Value: buffer.String(), Row: row, Col: col, Span: buffer.Len(), EndRow: l.Row, EndCol: l.Col}
 }
 
+// getIDToken returns the token of a keyword or a name. A name starts with a
+// letter followed by letters, digits and combining marks of any script, its
+// value is normalised to NFC so that the composed and decomposed ways of
+// writing it are the same name.
 func (l *Lexer) getIDToken() *Token {
-	if !unicode.IsLetter(rune(l.Input[l.Index])) {
-		return &Token{TokenInvalid, "INVALID", l.Row, l.Col, 1}
+	if !unicode.IsLetter(l.current()) {
+		return invalidToken(l.Row, l.Col, 1)
 	}
 
-	row, col := l.Row, l.Col
-	buffer := bytes.Buffer{}
-	for l.Index < len(l.Input) {
-		if unicode.IsLetter(rune(l.Input[l.Index])) || unicode.IsNumber(rune(l.Input[l.Index])) {
-			buffer.WriteByte(l.Input[l.Index])
-		} else {
+	row, col, start, offset := l.Row, l.Col, l.Index, l.offset
+	for l.available(1) {
+		r := l.current()
+		if !unicode.IsLetter(r) && !unicode.IsNumber(r) && !unicode.Is(unicode.M, r) {
 			break
 		}
 		l.advance()
 	}
+	value := norm.NFC.String(string(l.Input[start:l.Index]))
 
-	tokenType, ok := ReservedKeywords[buffer.String()]
+	tokenType, ok := ReservedKeywords[value]
 	if ok {
-		return &Token{tokenType, buffer.String(), row, col, buffer.Len()}
+		return &Token{Type: tokenType, Value: value, Row: row, Col: col, Span: l.offset - offset, EndRow: l.Row, EndCol: l.Col}
 	}
 
-	return &Token{TokenIdentifier, buffer.String(), row, col, buffer.Len()}
+	return &Token{Type: TokenIdentifier, Value: value, Row: row, Col: col, Span: l.offset - offset, EndRow: l.Row, EndCol: l.Col}
 }
 
+// getStringToken returns the token of a string quoted with " or ', with its
+// escape sequences replaced. Its position and span are the ones of the text
+// between the quotes, which can span lines. A string that is not closed is an
+// invalid token at its opening quote, and a string with an unknown escape
+// sequence an invalid token at the sequence.
 func (l *Lexer) getStringToken() *Token {
+	if !l.available(1) || l.Input[l.Index] != '"' && l.Input[l.Index] != '\'' {
+		return invalidToken(l.Row, l.Col, 1)
+	}
+	quote := l.Input[l.Index]
+	quoteRow, quoteCol := l.Row, l.Col
+	l.advance()
+
+	row, col, start := l.Row, l.Col, l.offset
 	buffer := bytes.Buffer{}
+	var invalid *Token
+	for l.available(1) && l.Input[l.Index] != quote {
+		if l.Input[l.Index] != '\\' {
+			i := l.Index
+			l.advance()
+			buffer.Write(l.Input[i:l.Index])
+			continue
+		}
+		escapeRow, escapeCol, escapeStart := l.Row, l.Col, l.offset
+		if !l.escape(&buffer) && invalid == nil {
+			// a backslash before a line break ends on the next row
+			invalid = &Token{Type: TokenInvalid, Value: "INVALID", Row: escapeRow, Col: escapeCol, Span: l.offset - escapeStart, EndRow: l.Row, EndCol: l.Col}
+		}
+	}
 
-	if l.Input[l.Index] != '"' {
-		return l.addSLToken(TokenInvalid, "INVALID")
+	if !l.available(1) {
+		return invalidToken(quoteRow, quoteCol, 1)
 	}
+	span := l.offset - start
 	l.advance()
 
-	row, col := l.Row, l.Col
-	for l.Index < len(l.Input) && l.Input[l.Index] != '"' {
-		buffer.WriteByte(l.Input[l.Index])
+	if invalid != nil {
+		return invalid
+	}
+	return &Token{Type: TokenStringConstant, Value: buffer.String(), Row: row, Col: col, Span: span, EndRow: l.Row, EndCol: l.Col}
+}
+
+// escape reads the escape sequence starting with the backslash at the current
+// position into buffer: \n, \t, \r, \", \', \\ or \u{...} with the hex code
+// point of a character. It reports false when the sequence is not one of them.
+func (l *Lexer) escape(buffer *bytes.Buffer) bool {
+	l.advance()
+	if !l.available(1) {
+		return false
+	}
+	c := l.Input[l.Index]
+	l.advance()
+
+	switch c {
+	case 'n':
+		buffer.WriteByte('\n')
+	case 't':
+		buffer.WriteByte('\t')
+	case 'r':
+		buffer.WriteByte('\r')
+	case '"', '\'', '\\':
+		buffer.WriteByte(c)
+	case 'u':
+		if !l.available(1) || l.Input[l.Index] != '{' {
+			return false
+		}
 		l.advance()
+		start := l.Index
+		for l.available(1) && strings.IndexByte("0123456789abcdefABCDEF", l.Input[l.Index]) >= 0 {
+			l.advance()
+		}
+		digits := string(l.Input[start:l.Index])
+		if !l.available(1) || l.Input[l.Index] != '}' {
+			return false
+		}
+		l.advance()
+
+		code, err := strconv.ParseUint(digits, 16, 32)
+		if err != nil || len(digits) > 6 || !utf8.ValidRune(rune(code)) {
+			return false
+		}
+		buffer.WriteRune(rune(code))
+	default:
+		return false
 	}
+	return true
+}
 
-	if l.Index >= len(l.Input) || l.Input[l.Index] != '"' {
-		return l.addSLToken(TokenInvalid, "INVALID")
+// getRawStringToken returns the token of a string quoted with `, it has no
+// escape sequences and can span lines
+func (l *Lexer) getRawStringToken() *Token {
+	quoteRow, quoteCol := l.Row, l.Col
+	l.advance()
+
+	row, col, start, offset := l.Row, l.Col, l.Index, l.offset
+	for l.available(1) && l.Input[l.Index] != '`' {
+		l.advance()
 	}
+	if !l.available(1) {
+		return invalidToken(quoteRow, quoteCol, 1)
+	}
+	value := string(l.Input[start:l.Index])
+	span := l.offset - offset
+	l.advance()
+
+	return &Token{Type: TokenStringConstant, Value: value, Row: row, Col: col, Span: span, EndRow: l.Row, EndCol: l.Col}
+}
+
+// getParameterToken returns the token of a placeholder like $name, its value is the name
+func (l *Lexer) getParameterToken() *Token {
+	row, col := l.Row, l.Col
 	l.advance()
+	if !l.available(1) || !unicode.IsLetter(l.current()) {
+		return invalidToken(row, col, 1)
+	}
 
-	return &Token{TokenStringConstant, buffer.String(), row, col, buffer.Len()}
+	name := l.getIDToken()
+	return &Token{Type: TokenParameter, Value: name.Value, Row: row, Col: col, Span: name.Span + 1, EndRow: l.Row, EndCol: l.Col}
 }
 
 func (l *Lexer) GetNextToken() *Token {
-	l.ignoreSpace()
+	l.discard()
+	if invalid := l.skipTrivia(); invalid != nil {
+		return invalid
+	}
 
-	if l.Index >= len(l.Input) {
-		return l.addSLToken(TokenEOF, "EOF")
+	if !l.available(1) {
+		if l.Err() != nil {
+			return invalidToken(l.Row, l.Col, 1)
+		}
+		return &Token{Type: TokenEOF, Value: "EOF", Row: l.Row, Col: l.Col, Span: 1, EndRow: l.Row, EndCol: l.Col + 1}
 	}
 
-	if unicode.IsLetter(rune(l.Input[l.Index])) {
+	if unicode.IsLetter(l.current()) {
 		return l.getIDToken()
-	} else if unicode.IsNumber(rune(l.Input[l.Index])) {
+	} else if isDigit(l.Input[l.Index]) {
 		return l.getNumberToken()
 	}
 
@@ -203,25 +462,49 @@ func (l *Lexer) GetNextToken() *Token {
 	case '=':
 		l.advance()
 		return l.addSLToken(TokenEqual, "=")
-	case '"':
+	case '"', '\'':
 		return l.getStringToken()
+	case '`':
+		return l.getRawStringToken()
+	case '$':
+		return l.getParameterToken()
 	default:
+		row, col := l.Row, l.Col
 		l.advance()
-		return l.addSLToken(TokenInvalid, "INVALID")
+		return invalidToken(row, col, 1)
 	}
 }
 
+// GetSourceContext returns the current line and the ones before it, read
+// to the end of the current line
 func (l *Lexer) GetSourceContext() string {
-	lines := strings.Split(string(l.Input), "\n")
+	return l.GetSourceContextAt(l.Row)
+}
+
+// GetSourceContextAt returns the line row and the ones before it like
+// GetSourceContext, or an empty string when the line is no longer kept
+func (l *Lexer) GetSourceContextAt(row int) string {
+	if row < l.Row-len(l.lines) || row > l.Row {
+		return ""
+	}
 	source := new(bytes.Buffer)
 
-	i := max(0, l.Row-2)
-	for i <= l.Row {
+	for i := max(0, row-contextLines+1, l.Row-len(l.lines)); i <= row && i < l.Row; i++ {
 		source.WriteString(fmt.Sprintf("%d\t|\t", i+1))
-		source.WriteString(lines[i])
+		source.Write(l.lines[i%len(l.lines)])
 		source.WriteString("\n")
-		i++
 	}
+	if row < l.Row {
+		return source.String()
+	}
+
+	end := l.Index
+	for l.available(end-l.Index+1) && l.Input[end] != '\n' {
+		end++
+	}
+	source.WriteString(fmt.Sprintf("%d\t|\t", l.Row+1))
+	source.Write(l.Input[l.lineStart:end])
+	source.WriteString("\n")
 
 	return source.String()
 }
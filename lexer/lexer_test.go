@@ -1,9 +1,13 @@
 package lexer
 
 import (
+	"errors"
+	"fmt"
 	"github.com/stretchr/testify/assert"
+	"io"
 	"strings"
 	"testing"
+	"testing/iotest"
 )
 
 func TestAdvance(t *testing.T) {
@@ -84,7 +88,8 @@ func TestGetStringTokenShouldReturnStringToken(t *testing.T) {
 	mockData := `"this is a test 123 +=-/ >= <= ,.:? .. \n"`
 	l := NewLexer(strings.NewReader(mockData))
 
-	expected := `this is a test 123 +=-/ >= <= ,.:? .. \n`
+	// the escape sequence is replaced by the character it stands for
+	expected := "this is a test 123 +=-/ >= <= ,.:? .. \n"
 
 	token := l.getStringToken()
 	if token.Type != TokenStringConstant || token.Value != expected {
@@ -345,7 +350,9 @@ func TestGetSourceContext(t *testing.T) {
       LivesIn[2..] Country{.name="India"}
       and .salary >= A.salary`
 	l := NewLexer(strings.NewReader(mockData))
-	l.Row = 3
+	// the lines are known once they are lexed
+	for l.GetNextToken().Row < 3 {
+	}
 	source := l.GetSourceContext()
 
   // Tabs characters are present
@@ -354,3 +361,165 @@ func TestGetSourceContext(t *testing.T) {
 4	|	      and .salary >= A.salary
 `, source)
 }
+
+func TestGetNextTokenWithParameter(t *testing.T) {
+	l := NewLexer(strings.NewReader(`.age > $minAge and $ 1`))
+
+	expected := []Token{
+		{TokenDot, ".", 0, 0, 1, 0, 1},
+		{TokenIdentifier, "age", 0, 1, 3, 0, 4},
+		{TokenGreaterThan, ">", 0, 5, 1, 0, 6},
+		{TokenParameter, "minAge", 0, 7, 7, 0, 14},
+		{TokenAnd, "and", 0, 15, 3, 0, 18},
+		{TokenInvalid, "INVALID", 0, 19, 1, 0, 20},
+		{TokenIntegerConstant, "1", 0, 21, 1, 0, 22},
+	}
+
+	for _, e := range expected {
+		assert.Equal(t, e, *l.GetNextToken())
+	}
+}
+
+func TestGetNextTokenSkipsComments(t *testing.T) {
+	l := NewLexer(strings.NewReader("// a comment\nQuery /* a\nblock */ Person // the end\n/ 2 /* not closed"))
+
+	expected := []Token{
+		{TokenQuery, "Query", 1, 0, 5, 1, 5},
+		{TokenIdentifier, "Person", 2, 9, 6, 2, 15},
+		{TokenDivide, "/", 3, 0, 1, 3, 1},
+		{TokenIntegerConstant, "2", 3, 2, 1, 3, 3},
+		{TokenInvalid, "INVALID", 3, 4, 2, 3, 6},
+		{TokenEOF, "EOF", 3, 17, 1, 3, 18},
+	}
+
+	for _, e := range expected {
+		assert.Equal(t, e, *l.GetNextToken())
+	}
+}
+
+func TestGetNextTokenKeepsComments(t *testing.T) {
+	l := NewLexer(strings.NewReader("// a comment  \nQuery /* a\nblock */ Person // the end"))
+	l.KeepComments = true
+	for l.GetNextToken().Type != TokenEOF {
+	}
+
+	assert.Equal(t, []Comment{
+		{"// a comment", 0, 0, 0, 14},
+		{"/* a\nblock */", 1, 6, 2, 8},
+		{"// the end", 2, 16, 2, 26},
+	}, l.Comments)
+}
+
+func TestGetNextTokenWithStrings(t *testing.T) {
+	l := NewLexer(strings.NewReader(`'John' "say \"hi\"" 'it\'s\t\\' "\u{E9}\u{1F600}" ` + "`raw \\n\nline`" + ` "bad \q escape" 'not closed`))
+
+	expected := []Token{
+		{TokenStringConstant, "John", 0, 1, 4, 0, 6},
+		{TokenStringConstant, `say "hi"`, 0, 8, 10, 0, 19},
+		{TokenStringConstant, "it's\t\\", 0, 21, 9, 0, 31},
+		{TokenStringConstant, "\u00e9\U0001F600", 0, 33, 15, 0, 49},
+		{TokenStringConstant, "raw \\n\nline", 0, 51, 11, 1, 5},
+		{TokenInvalid, "INVALID", 1, 11, 2, 1, 13},
+		{TokenInvalid, "INVALID", 1, 22, 1, 1, 23},
+		{TokenEOF, "EOF", 1, 33, 1, 1, 34},
+	}
+
+	for _, e := range expected {
+		assert.Equal(t, e, *l.GetNextToken())
+	}
+}
+
+func TestInvalidUnicodeEscape(t *testing.T) {
+	for _, src := range []string{`"\u{}"`, `"\u{D800}"`, `"\u{110000}"`, `"\u{1234567}"`, `"\u0041"`, `"\u{41"`} {
+		token := NewLexer(strings.NewReader(src)).GetNextToken()
+		assert.Equal(t, TokenInvalid, token.Type, src)
+		assert.Equal(t, 1, token.Col, src)
+	}
+}
+
+func TestInvalidEscapeOfALineBreak(t *testing.T) {
+	l := NewLexer(strings.NewReader("\"a\\\nb\" x"))
+
+	assert.Equal(t, Token{TokenInvalid, "INVALID", 0, 2, 2, 1, 0}, *l.GetNextToken())
+	assert.Equal(t, Token{TokenIdentifier, "x", 1, 3, 1, 1, 4}, *l.GetNextToken())
+	assert.Equal(t, Token{TokenEOF, "EOF", 1, 4, 1, 1, 5}, *l.GetNextToken())
+}
+
+func TestGetNextTokenWithUnicode(t *testing.T) {
+	l := NewLexer(strings.NewReader("J\u00fcrgen = Ju\u0308rgen नमस्ते > \"é\" x \xff"))
+
+	expected := []Token{
+		{TokenIdentifier, "Jürgen", 0, 0, 6, 0, 6},
+		{TokenEqual, "=", 0, 7, 1, 0, 8},
+		// the decomposed u and diaeresis is one character of the same name
+		{TokenIdentifier, "Jürgen", 0, 9, 6, 0, 15},
+		{TokenIdentifier, "नमस्ते", 0, 16, 4, 0, 20},
+		{TokenGreaterThan, ">", 0, 21, 1, 0, 22},
+		{TokenStringConstant, "é", 0, 24, 1, 0, 26},
+		{TokenIdentifier, "x", 0, 27, 1, 0, 28},
+		{TokenInvalid, "INVALID", 0, 29, 1, 0, 30},
+		{TokenEOF, "EOF", 0, 30, 1, 0, 31},
+	}
+
+	for _, e := range expected {
+		assert.Equal(t, e, *l.GetNextToken())
+	}
+}
+
+// vertices is a script of n Vertex statements made as it is read
+type vertices struct {
+	n, i int
+	line []byte
+}
+
+func (v *vertices) Read(p []byte) (int, error) {
+	if len(v.line) == 0 {
+		if v.i == v.n {
+			return 0, io.EOF
+		}
+		v.i++
+		v.line = []byte(fmt.Sprintf("Vertex V%d Person { .name = \"V%d\" }\n", v.i, v.i))
+	}
+	n := copy(p, v.line)
+	v.line = v.line[n:]
+	return n, nil
+}
+
+func TestGetNextTokenStreams(t *testing.T) {
+	l := NewLexer(&vertices{n: 100000})
+
+	tokens := 0
+	for token := l.GetNextToken(); token.Type != TokenEOF; token = l.GetNextToken() {
+		assert.NotEqual(t, TokenInvalid, token.Type)
+		tokens++
+	}
+	assert.Equal(t, 100000*9, tokens)
+	// the lexed lines are dropped
+	assert.Less(t, cap(l.Input), 4*chunkSize)
+	assert.Equal(t, "99999\t|\tVertex V99999 Person { .name = \"V99999\" }\n100000\t|\tVertex V100000 Person { .name = \"V100000\" }\n100001\t|\t\n", l.GetSourceContext())
+}
+
+func TestGetNextTokenAcrossReads(t *testing.T) {
+	src := "Schema Jürgen {\n  name string // the name\n}\n/* a\nblock */ Query Jürgen { .name >= \"a\\u{E9}\" } Return `raw\nstring`, $limit"
+	whole := NewLexer(strings.NewReader(src))
+	bytewise := NewLexer(iotest.OneByteReader(strings.NewReader(src)))
+
+	for {
+		token := whole.GetNextToken()
+		assert.Equal(t, token, bytewise.GetNextToken())
+		if token.Type == TokenEOF {
+			break
+		}
+	}
+	assert.Equal(t, whole.GetSourceContext(), bytewise.GetSourceContext())
+}
+
+func TestGetNextTokenReadError(t *testing.T) {
+	failed := errors.New("disk failed")
+	l := NewLexer(io.MultiReader(strings.NewReader("Query Person"), iotest.ErrReader(failed)))
+
+	assert.Equal(t, Token{TokenQuery, "Query", 0, 0, 5, 0, 5}, *l.GetNextToken())
+	assert.Equal(t, Token{TokenIdentifier, "Person", 0, 6, 6, 0, 12}, *l.GetNextToken())
+	assert.Equal(t, Token{TokenInvalid, "INVALID", 0, 12, 1, 0, 13}, *l.GetNextToken())
+	assert.ErrorIs(t, l.Err(), failed)
+}
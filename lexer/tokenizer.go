@@ -39,6 +39,15 @@ const (
 	TokenEdge
 	TokenRelation
 	TokenQuery
+	TokenGroup
+	TokenBy
+	TokenHaving
+	TokenReturn
+	TokenCall
+	TokenWrite
+	TokenExplain
+	TokenProfile
+	TokenParameter
 )
 
 func (t TokenType) String() string {
@@ -111,26 +120,59 @@ func (t TokenType) String() string {
 		return "Relation"
 	case TokenQuery:
 		return "Query"
+	case TokenGroup:
+		return "Group"
+	case TokenBy:
+		return "By"
+	case TokenHaving:
+		return "Having"
+	case TokenReturn:
+		return "Return"
+	case TokenCall:
+		return "Call"
+	case TokenWrite:
+		return "Write"
+	case TokenExplain:
+		return "Explain"
+	case TokenProfile:
+		return "Profile"
+	case TokenParameter:
+		return "$parameter"
 	default:
 		return ""
 	}
 }
 
 var ReservedKeywords = map[string]TokenType{
-	"as":         TokenAlias,
-	"and":        TokenAnd,
-	"or":         TokenOr,
-	"int":        TokenInteger,
-	"string":     TokenString,
-	"Sum":        TokenFunction,
-	"Max":        TokenFunction,
-	"Min":        TokenFunction,
-	"StartsWith": TokenFunction,
-	"Schema":     TokenSchema,
-	"Vertex":     TokenVertex,
-	"Relation":   TokenRelation,
-	"Edge":       TokenEdge,
-	"Query":      TokenQuery,
+	"as":               TokenAlias,
+	"and":              TokenAnd,
+	"or":               TokenOr,
+	"int":              TokenInteger,
+	"string":           TokenString,
+	"Sum":              TokenFunction,
+	"Max":              TokenFunction,
+	"Min":              TokenFunction,
+	"StartsWith":       TokenFunction,
+	"Count":            TokenFunction,
+	"Avg":              TokenFunction,
+	"ShortestPath":     TokenFunction,
+	"AllShortestPaths": TokenFunction,
+	"Length":           TokenFunction,
+	"Nodes":            TokenFunction,
+	"Edges":            TokenFunction,
+	"Schema":           TokenSchema,
+	"Vertex":           TokenVertex,
+	"Relation":         TokenRelation,
+	"Edge":             TokenEdge,
+	"Query":            TokenQuery,
+	"Group":            TokenGroup,
+	"By":               TokenBy,
+	"Having":           TokenHaving,
+	"Return":           TokenReturn,
+	"Call":             TokenCall,
+	"Write":            TokenWrite,
+	"Explain":          TokenExplain,
+	"Profile":          TokenProfile,
 }
 
 type Token struct {
@@ -139,6 +181,9 @@ type Token struct {
 	Row   int
 	Col   int
 	Span  int // stride of the token
+	// of the character after the token, past the closing quote of a string
+	EndRow int
+	EndCol int
 }
 
 func GetAllStatementTypes() []TokenType {
@@ -148,5 +193,8 @@ func GetAllStatementTypes() []TokenType {
 		TokenEdge,
 		TokenRelation,
 		TokenQuery,
+		TokenCall,
+		TokenExplain,
+		TokenProfile,
 	}
 }
@@ -0,0 +1,138 @@
+package vortex
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/Jintumoni/vortex/nodes"
+	"github.com/Jintumoni/vortex/visitors"
+)
+
+// logEntry is a program that changed the graph with the values of its
+// parameters, the log holds one entry per line as JSON
+type logEntry struct {
+	Program    string              `json:"program"`
+	Parameters map[string]logValue `json:"parameters,omitempty"`
+}
+
+// logValue is a parameter value that keeps its type through JSON, an int and
+// a float written as 2 are told apart. The zero value is null.
+type logValue struct {
+	Int    *int        `json:"int,omitempty"`
+	Float  *float64    `json:"float,omitempty"`
+	String *string     `json:"string,omitempty"`
+	Bool   *bool       `json:"bool,omitempty"`
+	List   *[]logValue `json:"list,omitempty"`
+}
+
+func newLogEntry(src string, params map[string]any) (*logEntry, error) {
+	entry := &logEntry{Program: src}
+	if len(params) == 0 {
+		return entry, nil
+	}
+
+	parameters, err := visitors.Parameters(params)
+	if err != nil {
+		return nil, err
+	}
+	entry.Parameters = make(map[string]logValue, len(parameters))
+	for name, value := range parameters {
+		if entry.Parameters[name], err = newLogValue(value); err != nil {
+			return nil, fmt.Errorf("%w: $%s", err, name)
+		}
+	}
+	return entry, nil
+}
+
+func newLogValue(value nodes.ASTNode) (logValue, error) {
+	switch v := value.(type) {
+	case *nodes.IntNode:
+		return logValue{Int: &v.Value}, nil
+	case *nodes.FloatNode:
+		return logValue{Float: &v.Value}, nil
+	case *nodes.StringNode:
+		return logValue{String: &v.Value}, nil
+	case *nodes.BoolNode:
+		return logValue{Bool: &v.Value}, nil
+	case *nodes.NullNode:
+		return logValue{}, nil
+	case *nodes.ListNode:
+		list := make([]logValue, 0, len(v.Values))
+		for _, value := range v.Values {
+			element, err := newLogValue(value)
+			if err != nil {
+				return logValue{}, err
+			}
+			list = append(list, element)
+		}
+		return logValue{List: &list}, nil
+	default:
+		return logValue{}, fmt.Errorf("%w: %s", visitors.InvalidParameter, visitors.FormatValue(value))
+	}
+}
+
+func (v logValue) value() nodes.ASTNode {
+	switch {
+	case v.Int != nil:
+		return &nodes.IntNode{Value: *v.Int}
+	case v.Float != nil:
+		return &nodes.FloatNode{Value: *v.Float}
+	case v.String != nil:
+		return &nodes.StringNode{Value: *v.String}
+	case v.Bool != nil:
+		return &nodes.BoolNode{Value: *v.Bool}
+	case v.List != nil:
+		list := &nodes.ListNode{Values: make([]nodes.ASTNode, 0, len(*v.List))}
+		for _, element := range *v.List {
+			list.Values = append(list.Values, element.value())
+		}
+		return list
+	default:
+		return &nodes.NullNode{}
+	}
+}
+
+// append writes an entry to the log, a database in memory has no log
+func (db *DB) append(entry *logEntry) error {
+	if db.log == nil {
+		return nil
+	}
+	line, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	if _, err := db.log.Write(append(line, '\n')); err != nil {
+		return err
+	}
+	return db.log.Sync()
+}
+
+// replay runs every program of the log again
+func (db *DB) replay(log io.Reader) error {
+	scanner := bufio.NewScanner(log)
+	scanner.Buffer(nil, 64<<20)
+	for scanner.Scan() {
+		var entry logEntry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			return fmt.Errorf("%w: %s", CorruptLog, err)
+		}
+
+		statement, err := db.executor(entry.Program).Prepare()
+		if err != nil {
+			return fmt.Errorf("%w: %s", CorruptLog, err)
+		}
+		params := make(map[string]any, len(entry.Parameters))
+		for name, value := range entry.Parameters {
+			params[name] = value.value()
+		}
+		// a program that failed when it was run fails at the same statement again,
+		// the graph is left as it was after it
+		statement.Execute(params)
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("%w: %s", CorruptLog, err)
+	}
+	return nil
+}
@@ -0,0 +1,198 @@
+package lsp
+
+import (
+	"io"
+	"strings"
+	"unicode"
+	"unicode/utf16"
+	"unicode/utf8"
+
+	vortexerrors "github.com/Jintumoni/vortex/errors"
+	"github.com/Jintumoni/vortex/lexer"
+	"github.com/Jintumoni/vortex/manager"
+	"github.com/Jintumoni/vortex/nodes"
+	"github.com/Jintumoni/vortex/parser"
+	"github.com/Jintumoni/vortex/visitors"
+)
+
+// document is an open program and what is known of it
+type document struct {
+	uri     string
+	version int
+	lines   []string
+	utf16   bool // characters of the positions are UTF-16 code units, runes otherwise
+
+	statements  []nodes.ASTNode // the ones that parsed, in order
+	resolver    *visitors.Resolver
+	diagnostics []diagnostic
+}
+
+// analyse parses the text of a document, checks its statements and binds
+// their names
+func analyse(uri string, version int, text string, utf16 bool) *document {
+	d := &document{uri: uri, version: version, lines: strings.Split(text, "\n"), utf16: utf16}
+
+	p := parser.NewParser(lexer.NewLexer(strings.NewReader(text)))
+	for {
+		node, err := p.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			d.report(err, nodes.Span{})
+			continue
+		}
+		d.statements = append(d.statements, node)
+	}
+
+	// the definitions are checked like the executor writes them
+	appManager := manager.NewAppManager()
+	for _, node := range d.statements {
+		var err error
+		switch n := node.(type) {
+		case *nodes.SchemaDefNode:
+			err = appManager.WriteSchema(n)
+		case *nodes.EdgeDefNode:
+			err = appManager.WriteEdge(n)
+		case *nodes.RelationInitNode:
+			err = appManager.WriteRelation(n)
+		case *nodes.VertexInitNode:
+			err = appManager.WriteVertex(n)
+		}
+		if err != nil {
+			d.report(err, node.GetSpan())
+		}
+	}
+
+	d.resolver = visitors.NewResolver()
+	for _, node := range d.statements {
+		before := len(d.resolver.Errors)
+		d.resolver.Resolve(node)
+		for _, err := range d.resolver.Errors[before:] {
+			d.report(err, node.GetSpan())
+		}
+	}
+	return d
+}
+
+// report adds the diagnostics of err, at the statement when the error does
+// not know the part of the source it is about
+func (d *document) report(err error, statement nodes.Span) {
+	for _, v := range vortexerrors.Diagnose(err) {
+		span := v.Span
+		if span.IsZero() {
+			span = statement
+		}
+		message := v.Message
+		if annotation := v.Annotation(); annotation != "" {
+			message += "\n" + annotation
+		}
+		for _, note := range v.Notes {
+			message += "\nnote: " + note
+		}
+
+		lsp := diagnostic{
+			Range:    d.lspRange(span),
+			Severity: severityError,
+			Code:     v.Code,
+			Source:   "vortex",
+			Message:  message,
+		}
+		for _, l := range v.Secondary {
+			lsp.RelatedInformation = append(lsp.RelatedInformation, relatedInformation{
+				Location: location{URI: d.uri, Range: d.lspRange(l.Span)},
+				Message:  l.Message,
+			})
+		}
+		d.diagnostics = append(d.diagnostics, lsp)
+	}
+}
+
+// line returns the text of the row, empty past the end of the document
+func (d *document) line(row int) string {
+	if row < 0 || row >= len(d.lines) {
+		return ""
+	}
+	return strings.TrimSuffix(d.lines[row], "\r")
+}
+
+// position converts a position of the source to the encoding of the client
+func (d *document) position(p nodes.Position) position {
+	prefix := d.prefix(p)
+	if !d.utf16 {
+		return position{Line: p.Row, Character: utf8.RuneCountInString(prefix)}
+	}
+	character := 0
+	for _, r := range prefix {
+		character += utf16Len(r)
+	}
+	return position{Line: p.Row, Character: character}
+}
+
+// sourcePosition converts a position of the client to a position of the
+// source, a position inside a character is the one after it
+func (d *document) sourcePosition(p position) nodes.Position {
+	col, units := 0, 0
+	for _, r := range d.line(p.Line) {
+		if units >= p.Character {
+			break
+		}
+		if d.utf16 {
+			units += utf16Len(r)
+		} else {
+			units++
+		}
+		if !unicode.Is(unicode.M, r) {
+			col++
+		}
+	}
+	return nodes.Position{Row: p.Line, Col: col}
+}
+
+// offset returns the byte offset of a column of the source in the line.
+// The columns count characters like the lexer: a combining mark is part of
+// the character before it.
+func offset(line string, col int) int {
+	for i, r := range line {
+		if unicode.Is(unicode.M, r) {
+			continue
+		}
+		if col <= 0 {
+			return i
+		}
+		col--
+	}
+	return len(line)
+}
+
+// utf16Len returns the number of UTF-16 code units encoding the rune
+func utf16Len(r rune) int {
+	if utf16.IsSurrogate(r) || r < 0x10000 {
+		return 1
+	}
+	return 2
+}
+
+func (d *document) lspRange(s nodes.Span) lspRange {
+	return lspRange{Start: d.position(s.Start), End: d.position(s.End)}
+}
+
+// prefix returns the text of the row before the column
+func (d *document) prefix(p nodes.Position) string {
+	line := d.line(p.Row)
+	return line[:offset(line, p.Col)]
+}
+
+// firstLine returns the text of the span on its first row, eg: the head of a
+// query
+func (d *document) firstLine(s nodes.Span) string {
+	line := d.line(s.Start.Row)
+	start, end := offset(line, s.Start.Col), len(line)
+	if s.End.Row == s.Start.Row {
+		end = offset(line, s.End.Col)
+	}
+	if start >= end {
+		return ""
+	}
+	return strings.TrimSpace(line[start:end])
+}
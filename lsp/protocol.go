@@ -0,0 +1,229 @@
+// Package lsp is a language server for Vortex programs. It speaks the
+// Language Server Protocol over a pair of streams, usually the standard
+// input and output of the process started by an editor: JSON-RPC 2.0
+// messages each preceded by a Content-Length header.
+//
+// The documents are synchronised in full on every change. Each one is parsed
+// one statement at a time and its definitions are written to a catalog of its
+// own, the errors of both are published as diagnostics together with the
+// names of the queries and calls that refer to nothing. The names are then
+// offered for completion, described on hover, and lead to their definitions.
+package lsp
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/textproto"
+	"strconv"
+	"strings"
+)
+
+var (
+	MissingContentLength = errors.New("Message has no Content-Length header")
+	ExitBeforeShutdown   = errors.New("Exit notification received before shutdown")
+)
+
+// MaxMessageSize is the largest message accepted, in bytes
+const MaxMessageSize = 64 << 20
+
+// codes of the JSON-RPC errors
+const (
+	codeParseError     = -32700
+	codeInvalidRequest = -32600
+	codeMethodNotFound = -32601
+	codeInvalidParams  = -32602
+	codeNotInitialized = -32002
+)
+
+// message is a request, a response or a notification, a request without
+// an id is a notification
+type message struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id,omitempty"`
+	Method  string          `json:"method,omitempty"`
+	Params  json.RawMessage `json:"params,omitempty"`
+	Result  json.RawMessage `json:"result,omitempty"`
+	Error   *responseError  `json:"error,omitempty"`
+}
+
+type responseError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+func (e *responseError) Error() string {
+	return e.Message
+}
+
+// readMessage reads the headers of a message and its JSON content
+func readMessage(r *bufio.Reader) (*message, error) {
+	headers, err := textproto.NewReader(r).ReadMIMEHeader()
+	if err != nil {
+		if err == io.EOF && len(headers) == 0 {
+			return nil, io.EOF
+		}
+		return nil, fmt.Errorf("reading headers: %w", err)
+	}
+	length, err := strconv.Atoi(strings.TrimSpace(headers.Get("Content-Length")))
+	if err != nil || length < 0 {
+		return nil, MissingContentLength
+	}
+	if length > MaxMessageSize {
+		return nil, fmt.Errorf("message of %d bytes is larger than MaxMessageSize", length)
+	}
+
+	content := make([]byte, length)
+	if _, err := io.ReadFull(r, content); err != nil {
+		return nil, fmt.Errorf("reading content: %w", err)
+	}
+	m := new(message)
+	if err := json.Unmarshal(content, m); err != nil {
+		return nil, &responseError{Code: codeParseError, Message: err.Error()}
+	}
+	return m, nil
+}
+
+// writeMessage writes the message with its Content-Length header
+func writeMessage(w io.Writer, m *message) error {
+	m.JSONRPC = "2.0"
+	content, err := json.Marshal(m)
+	if err != nil {
+		return err
+	}
+	if _, err := fmt.Fprintf(w, "Content-Length: %d\r\n\r\n", len(content)); err != nil {
+		return err
+	}
+	_, err = w.Write(content)
+	return err
+}
+
+// position is a place in a document, characters count in the encoding
+// negotiated with the client
+type position struct {
+	Line      int `json:"line"`
+	Character int `json:"character"`
+}
+
+type lspRange struct {
+	Start position `json:"start"`
+	End   position `json:"end"`
+}
+
+type location struct {
+	URI   string   `json:"uri"`
+	Range lspRange `json:"range"`
+}
+
+type textDocumentIdentifier struct {
+	URI string `json:"uri"`
+}
+
+type textDocumentPositionParams struct {
+	TextDocument textDocumentIdentifier `json:"textDocument"`
+	Position     position               `json:"position"`
+}
+
+type initializeParams struct {
+	Capabilities struct {
+		General struct {
+			PositionEncodings []string `json:"positionEncodings"`
+		} `json:"general"`
+	} `json:"capabilities"`
+}
+
+type didOpenParams struct {
+	TextDocument struct {
+		URI     string `json:"uri"`
+		Version int    `json:"version"`
+		Text    string `json:"text"`
+	} `json:"textDocument"`
+}
+
+type didChangeParams struct {
+	TextDocument struct {
+		URI     string `json:"uri"`
+		Version int    `json:"version"`
+	} `json:"textDocument"`
+	ContentChanges []struct {
+		Text string `json:"text"`
+	} `json:"contentChanges"`
+}
+
+type didCloseParams struct {
+	TextDocument textDocumentIdentifier `json:"textDocument"`
+}
+
+type documentSymbolParams struct {
+	TextDocument textDocumentIdentifier `json:"textDocument"`
+}
+
+const severityError = 1
+
+type diagnostic struct {
+	Range              lspRange             `json:"range"`
+	Severity           int                  `json:"severity"`
+	Code               string               `json:"code,omitempty"`
+	Source             string               `json:"source"`
+	Message            string               `json:"message"`
+	RelatedInformation []relatedInformation `json:"relatedInformation,omitempty"`
+}
+
+type relatedInformation struct {
+	Location location `json:"location"`
+	Message  string   `json:"message"`
+}
+
+type publishDiagnosticsParams struct {
+	URI         string       `json:"uri"`
+	Version     *int         `json:"version,omitempty"`
+	Diagnostics []diagnostic `json:"diagnostics"`
+}
+
+// kinds of the completion items
+const (
+	completionFunction  = 3
+	completionField     = 5
+	completionVariable  = 6
+	completionClass     = 7
+	completionInterface = 8
+	completionKeyword   = 14
+	completionConstant  = 21
+)
+
+type completionItem struct {
+	Label  string `json:"label"`
+	Kind   int    `json:"kind"`
+	Detail string `json:"detail,omitempty"`
+}
+
+type markupContent struct {
+	Kind  string `json:"kind"`
+	Value string `json:"value"`
+}
+
+type hover struct {
+	Contents markupContent `json:"contents"`
+	Range    lspRange      `json:"range"`
+}
+
+// kinds of the document symbols
+const (
+	symbolClass     = 5
+	symbolField     = 8
+	symbolInterface = 11
+	symbolFunction  = 12
+	symbolObject    = 19
+	symbolEvent     = 24
+)
+
+type documentSymbol struct {
+	Name           string           `json:"name"`
+	Detail         string           `json:"detail,omitempty"`
+	Kind           int              `json:"kind"`
+	Range          lspRange         `json:"range"`
+	SelectionRange lspRange         `json:"selectionRange"`
+	Children       []documentSymbol `json:"children,omitempty"`
+}
@@ -0,0 +1,405 @@
+package lsp
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/Jintumoni/vortex/lexer"
+	"github.com/Jintumoni/vortex/nodes"
+	"github.com/Jintumoni/vortex/visitors"
+)
+
+// Server answers the requests of one editor about its open documents
+type Server struct {
+	Log *log.Logger // where the failures of the session are logged, nil to drop them
+
+	out         io.Writer
+	documents   map[string]*document
+	utf16       bool // the client counts the characters of the positions in UTF-16 code units
+	initialized bool
+	shutdown    bool
+}
+
+func NewServer() *Server {
+	return &Server{documents: make(map[string]*document), utf16: true}
+}
+
+// Serve reads the messages of the client from r and answers them on w until
+// the exit notification or the end of r. It returns nil when the client
+// exits after asking the server to shut down.
+func (s *Server) Serve(r io.Reader, w io.Writer) error {
+	s.out = w
+	reader := bufio.NewReader(r)
+	for {
+		m, err := readMessage(reader)
+		var invalid *responseError
+		switch {
+		case err == io.EOF:
+			if s.shutdown {
+				return nil
+			}
+			return io.ErrUnexpectedEOF
+		case errors.As(err, &invalid):
+			if err := writeMessage(s.out, &message{ID: json.RawMessage("null"), Error: invalid}); err != nil {
+				return err
+			}
+			continue
+		case err != nil:
+			return err
+		}
+
+		if m.Method == "exit" {
+			if s.shutdown {
+				return nil
+			}
+			return ExitBeforeShutdown
+		}
+		if err := s.handle(m); err != nil {
+			return err
+		}
+	}
+}
+
+// handle answers a request, or acts on a notification, an error is only
+// returned when the answer cannot be written
+func (s *Server) handle(m *message) error {
+	if m.ID == nil {
+		if err := s.notify(m); err != nil {
+			s.logf("%s: %v", m.Method, err)
+		}
+		return nil
+	}
+
+	var result any
+	var err error
+	switch {
+	case m.Method == "initialize":
+		result, err = s.initialize(m.Params)
+	case !s.initialized:
+		err = &responseError{Code: codeNotInitialized, Message: "Server is not initialized"}
+	case s.shutdown:
+		err = &responseError{Code: codeInvalidRequest, Message: "Server is shut down"}
+	case m.Method == "shutdown":
+		s.shutdown = true
+	case m.Method == "textDocument/completion":
+		result, err = withDocument(s, m.Params, (*document).completion)
+	case m.Method == "textDocument/hover":
+		result, err = withDocument(s, m.Params, (*document).hover)
+	case m.Method == "textDocument/definition":
+		result, err = withDocument(s, m.Params, (*document).definition)
+	case m.Method == "textDocument/documentSymbol":
+		var params documentSymbolParams
+		if err = unmarshal(m.Params, &params); err == nil {
+			result = []documentSymbol{}
+			if d, ok := s.documents[params.TextDocument.URI]; ok {
+				result = d.symbols()
+			}
+		}
+	default:
+		err = &responseError{Code: codeMethodNotFound, Message: "Method not found: " + m.Method}
+	}
+
+	response := &message{ID: m.ID}
+	if err != nil {
+		var e *responseError
+		if !errors.As(err, &e) {
+			e = &responseError{Code: codeInvalidRequest, Message: err.Error()}
+		}
+		response.Error = e
+	} else if response.Result, err = json.Marshal(result); err != nil {
+		return err
+	}
+	return writeMessage(s.out, response)
+}
+
+func (s *Server) initialize(params json.RawMessage) (any, error) {
+	var p initializeParams
+	if err := unmarshal(params, &p); err != nil {
+		return nil, err
+	}
+	s.initialized = true
+
+	// the columns of the source count characters, they are converted to
+	// the code units of either encoding
+	encoding := "utf-16"
+	for _, e := range p.Capabilities.General.PositionEncodings {
+		if e == "utf-32" {
+			encoding, s.utf16 = e, false
+		}
+	}
+	return map[string]any{
+		"capabilities": map[string]any{
+			"positionEncoding":       encoding,
+			"textDocumentSync":       1, // the full text on every change
+			"completionProvider":     map[string]any{"triggerCharacters": []string{".", "]", ">"}},
+			"hoverProvider":          true,
+			"definitionProvider":     true,
+			"documentSymbolProvider": true,
+		},
+		"serverInfo": map[string]string{"name": "vortex-lsp"},
+	}, nil
+}
+
+// notify acts on a notification, the unknown ones are ignored
+func (s *Server) notify(m *message) error {
+	switch m.Method {
+	case "initialized":
+	case "textDocument/didOpen":
+		var params didOpenParams
+		if err := unmarshal(m.Params, &params); err != nil {
+			return err
+		}
+		document := params.TextDocument
+		return s.open(analyse(document.URI, document.Version, document.Text, s.utf16))
+	case "textDocument/didChange":
+		var params didChangeParams
+		if err := unmarshal(m.Params, &params); err != nil {
+			return err
+		}
+		if len(params.ContentChanges) == 0 {
+			return nil
+		}
+		document := params.TextDocument
+		text := params.ContentChanges[len(params.ContentChanges)-1].Text
+		return s.open(analyse(document.URI, document.Version, text, s.utf16))
+	case "textDocument/didClose":
+		var params didCloseParams
+		if err := unmarshal(m.Params, &params); err != nil {
+			return err
+		}
+		delete(s.documents, params.TextDocument.URI)
+		return s.publish(publishDiagnosticsParams{URI: params.TextDocument.URI, Diagnostics: []diagnostic{}})
+	}
+	return nil
+}
+
+// open keeps the document and publishes its diagnostics
+func (s *Server) open(d *document) error {
+	s.documents[d.uri] = d
+	diagnostics := d.diagnostics
+	if diagnostics == nil {
+		diagnostics = []diagnostic{}
+	}
+	return s.publish(publishDiagnosticsParams{URI: d.uri, Version: &d.version, Diagnostics: diagnostics})
+}
+
+func (s *Server) publish(params publishDiagnosticsParams) error {
+	content, err := json.Marshal(params)
+	if err != nil {
+		return err
+	}
+	return writeMessage(s.out, &message{Method: "textDocument/publishDiagnostics", Params: content})
+}
+
+func (s *Server) logf(format string, args ...any) {
+	if s.Log != nil {
+		s.Log.Printf(format, args...)
+	}
+}
+
+// withDocument answers a request about a position of a document, with null
+// when the document is not open
+func withDocument[T any](s *Server, params json.RawMessage, answer func(*document, nodes.Position) T) (any, error) {
+	var p textDocumentPositionParams
+	if err := unmarshal(params, &p); err != nil {
+		return nil, err
+	}
+	d, ok := s.documents[p.TextDocument.URI]
+	if !ok {
+		return nil, nil
+	}
+	return answer(d, d.sourcePosition(p.Position)), nil
+}
+
+func unmarshal(params json.RawMessage, v any) error {
+	if len(params) == 0 {
+		return &responseError{Code: codeInvalidParams, Message: "Params are missing"}
+	}
+	if err := json.Unmarshal(params, v); err != nil {
+		return &responseError{Code: codeInvalidParams, Message: err.Error()}
+	}
+	return nil
+}
+
+var (
+	// the name typed before the cursor
+	partialName = regexp.MustCompile(`\pL[\pL\pN\pM]*$`)
+	// the end of a relation term, where an edge is named: [] []-> [1..2]<-
+	edgeContext = regexp.MustCompile(`(\]\s*(->|<->|<-)?|\bRelation)\s*$`)
+	// the alias of a property: P.
+	aliasContext = regexp.MustCompile(`(\pL[\pL\pN\pM]*)\.$`)
+)
+
+// completion returns the names that can be written at the position, the
+// properties after a dot, the edges after a relation term, and the keywords
+// and the names of the document anywhere else
+func (d *document) completion(p nodes.Position) []completionItem {
+	before := d.prefix(p)
+	before = before[:len(before)-len(partialName.FindString(before))]
+
+	items := []completionItem{}
+	switch {
+	case strings.HasSuffix(before, "."):
+		// properties of the schema of the alias, of every schema otherwise
+		var schema *visitors.Symbol
+		if alias := aliasContext.FindStringSubmatchIndex(before); alias != nil {
+			at := nodes.Position{Row: p.Row, Col: lexer.Characters(before[:alias[2]])}
+			if symbol, _ := d.resolver.SymbolAt(at); symbol != nil && symbol.Kind == visitors.AliasSymbol {
+				schema = symbol.Schema
+			}
+		}
+		for _, symbol := range d.resolver.Symbols {
+			if symbol.Kind == visitors.PropertySymbol && (schema == nil || symbol.Schema == schema) {
+				items = append(items, completionItem{Label: symbol.Name, Kind: completionField, Detail: describeProperty(symbol)})
+			}
+		}
+	case edgeContext.MatchString(before):
+		for _, name := range d.resolver.Names(visitors.EdgeSymbol) {
+			items = append(items, completionItem{Label: name, Kind: completionInterface, Detail: "Edge"})
+		}
+	default:
+		keywords := make([]string, 0, len(lexer.ReservedKeywords))
+		for keyword := range lexer.ReservedKeywords {
+			keywords = append(keywords, keyword)
+		}
+		sort.Strings(keywords)
+		for _, keyword := range keywords {
+			kind := completionKeyword
+			if lexer.ReservedKeywords[keyword] == lexer.TokenFunction {
+				kind = completionFunction
+			}
+			items = append(items, completionItem{Label: keyword, Kind: kind})
+		}
+
+		for _, k := range []struct {
+			kind       visitors.SymbolKind
+			completion int
+		}{
+			{visitors.SchemaSymbol, completionClass},
+			{visitors.VertexSymbol, completionConstant},
+			{visitors.EdgeSymbol, completionInterface},
+			{visitors.AliasSymbol, completionVariable},
+		} {
+			for _, name := range d.resolver.Names(k.kind) {
+				items = append(items, completionItem{Label: name, Kind: k.completion, Detail: k.kind.String()})
+			}
+		}
+	}
+	return items
+}
+
+// hover describes the name at the position, nil when there is none
+func (d *document) hover(p nodes.Position) *hover {
+	symbol, span := d.resolver.SymbolAt(p)
+	if symbol == nil {
+		return nil
+	}
+
+	var value string
+	switch symbol.Kind {
+	case visitors.SchemaSymbol:
+		buffer := new(strings.Builder)
+		fmt.Fprintf(buffer, "Schema %s {\n", symbol.Name)
+		for _, property := range symbol.Properties {
+			if property.Type != "" {
+				fmt.Fprintf(buffer, "  %s %s\n", property.Name, property.Type)
+			}
+		}
+		buffer.WriteString("}")
+		value = code(buffer.String())
+	case visitors.PropertySymbol:
+		value = code(describeProperty(symbol))
+		if symbol.Type == "" {
+			value += "\nComputed by an algorithm"
+		}
+	case visitors.VertexSymbol:
+		value = code(fmt.Sprintf("Vertex %s %s", symbol.Name, symbolName(symbol.Schema)))
+	case visitors.EdgeSymbol:
+		value = code(fmt.Sprintf("Edge %s %s", symbol.Name, symbol.Node.(*nodes.EdgeDefNode).EdgeType))
+	case visitors.AliasSymbol:
+		value = code(fmt.Sprintf("%s as %s", symbolName(symbol.Schema), symbol.Name))
+		if symbol.Schema == nil {
+			value = code("as " + symbol.Name)
+		}
+	}
+	return &hover{Contents: markupContent{Kind: "markdown", Value: value}, Range: d.lspRange(span)}
+}
+
+// definition returns where the name at the position is defined, nil when
+// there is no name there
+func (d *document) definition(p nodes.Position) *location {
+	symbol, _ := d.resolver.SymbolAt(p)
+	if symbol == nil {
+		return nil
+	}
+	return &location{URI: d.uri, Range: d.lspRange(symbol.Span)}
+}
+
+// symbols returns the outline of the document, a symbol for every statement
+func (d *document) symbols() []documentSymbol {
+	symbols := []documentSymbol{}
+	for _, node := range d.statements {
+		statement := d.lspRange(node.GetSpan())
+		symbol := documentSymbol{Range: statement, SelectionRange: statement}
+		switch n := node.(type) {
+		case *nodes.SchemaDefNode:
+			symbol.Name, symbol.Kind = n.SchemaName.Value, symbolClass
+			symbol.SelectionRange = d.lspRange(n.SchemaName.Span)
+			for _, p := range n.Properties {
+				property := p.(*nodes.PropertyDefNode)
+				symbol.Children = append(symbol.Children, documentSymbol{
+					Name:           property.PropertyName.Value,
+					Detail:         property.PropertyType.Value,
+					Kind:           symbolField,
+					Range:          d.lspRange(property.Span),
+					SelectionRange: d.lspRange(property.PropertyName.Span),
+				})
+			}
+		case *nodes.VertexInitNode:
+			symbol.Name, symbol.Detail, symbol.Kind = n.VertexName.Value, n.SchemaName.Value, symbolObject
+			symbol.SelectionRange = d.lspRange(n.VertexName.Span)
+		case *nodes.EdgeDefNode:
+			symbol.Name, symbol.Detail, symbol.Kind = n.EdgeName.Value, n.EdgeType.String(), symbolInterface
+			symbol.SelectionRange = d.lspRange(n.EdgeName.Span)
+		case *nodes.RelationInitNode:
+			symbol.Name, symbol.Kind = d.firstLine(n.Span), symbolEvent
+		default:
+			symbol.Name, symbol.Kind = d.firstLine(node.GetSpan()), symbolFunction
+		}
+		if symbol.Name == "" {
+			continue
+		}
+		symbols = append(symbols, symbol)
+	}
+	return symbols
+}
+
+// describeProperty returns the definition of a property, eg: age int
+func describeProperty(property *visitors.Symbol) string {
+	description := property.Name
+	if property.Type != "" {
+		description += " " + property.Type
+	}
+	if property.Schema != nil {
+		description += " (" + property.Schema.Name + ")"
+	}
+	return description
+}
+
+func symbolName(s *visitors.Symbol) string {
+	if s == nil {
+		return ""
+	}
+	return s.Name
+}
+
+func code(source string) string {
+	return "```vortex\n" + source + "\n```"
+}
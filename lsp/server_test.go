@@ -0,0 +1,331 @@
+package lsp
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const testProgram = `Schema Person {
+  name string
+  age  int
+}
+Schema City {
+  name string
+}
+
+Vertex Ann Person { .name = "Ann" .age = 30 }
+Vertex Bob Person { .name = "Bob" .age = 20 }
+Vertex Cid Person { .name = "Cid" .age = "old" }
+Vertex Paris City { .name = "Paris" }
+
+Edge FriendsWith TwoWay
+Edge LivesIn OneWay
+
+Relation FriendsWith { Ann Bob }
+Query Person as P { []FriendWith Person { .age < P.age } }
+`
+
+const uri = "file:///graph.vtx"
+
+// client is an editor talking to a server through pipes
+type client struct {
+	t      *testing.T
+	in     io.WriteCloser
+	out    *bufio.Reader
+	served chan error
+	id     int
+}
+
+// start serves a session and initializes it with the position encodings the
+// client supports
+func start(t *testing.T, encodings ...string) *client {
+	serverIn, in := io.Pipe()
+	out, serverOut := io.Pipe()
+	c := &client{t: t, in: in, out: bufio.NewReader(out), served: make(chan error, 1)}
+	go func() {
+		c.served <- NewServer().Serve(serverIn, serverOut)
+		serverOut.Close()
+	}()
+	t.Cleanup(func() { in.Close() })
+
+	var result struct {
+		Capabilities struct {
+			PositionEncoding string `json:"positionEncoding"`
+		} `json:"capabilities"`
+		ServerInfo struct {
+			Name string `json:"name"`
+		} `json:"serverInfo"`
+	}
+	params := map[string]any{"capabilities": map[string]any{"general": map[string]any{"positionEncodings": encodings}}}
+	assert.Nil(t, c.request("initialize", params, &result))
+	assert.Equal(t, "vortex-lsp", result.ServerInfo.Name)
+	c.notify("initialized", map[string]any{})
+	return c
+}
+
+func (c *client) send(m *message) {
+	assert.NoError(c.t, writeMessage(c.in, m))
+}
+
+func (c *client) notify(method string, params any) {
+	content, err := json.Marshal(params)
+	assert.NoError(c.t, err)
+	c.send(&message{Method: method, Params: content})
+}
+
+// request sends a request and decodes its result into v, the notifications
+// received before the answer are dropped
+func (c *client) request(method string, params any, v any) *responseError {
+	c.id++
+	content, err := json.Marshal(params)
+	assert.NoError(c.t, err)
+	id := json.RawMessage(fmt.Sprint(c.id))
+	c.send(&message{ID: id, Method: method, Params: content})
+
+	for {
+		m, err := readMessage(c.out)
+		assert.NoError(c.t, err)
+		if m.Method != "" {
+			continue
+		}
+		assert.Equal(c.t, string(id), string(m.ID))
+		if m.Error != nil {
+			return m.Error
+		}
+		assert.NoError(c.t, json.Unmarshal(m.Result, v))
+		return nil
+	}
+}
+
+// diagnostics reads the next diagnostics published
+func (c *client) diagnostics() publishDiagnosticsParams {
+	for {
+		m, err := readMessage(c.out)
+		assert.NoError(c.t, err)
+		if m.Method == "textDocument/publishDiagnostics" {
+			var params publishDiagnosticsParams
+			assert.NoError(c.t, json.Unmarshal(m.Params, &params))
+			return params
+		}
+	}
+}
+
+func (c *client) open(text string) publishDiagnosticsParams {
+	c.notify("textDocument/didOpen", map[string]any{
+		"textDocument": map[string]any{"uri": uri, "languageId": "vortex", "version": 1, "text": text},
+	})
+	return c.diagnostics()
+}
+
+// at returns the position of the n-th occurrence of s in testProgram,
+// counted from 0, with the characters counted in runes
+func at(s string, n int) position {
+	offset := -1
+	for i := 0; i <= n; i++ {
+		offset += 1 + strings.Index(testProgram[offset+1:], s)
+	}
+	line := strings.Count(testProgram[:offset], "\n")
+	start := strings.LastIndex(testProgram[:offset], "\n") + 1
+	return position{Line: line, Character: len([]rune(testProgram[start:offset]))}
+}
+
+func positionParams(p position) map[string]any {
+	return map[string]any{"textDocument": map[string]any{"uri": uri}, "position": p}
+}
+
+func TestDiagnostics(t *testing.T) {
+	c := start(t)
+	published := c.open(testProgram)
+	assert.Equal(t, uri, published.URI)
+
+	var messages []string
+	for _, d := range published.Diagnostics {
+		assert.Equal(t, "vortex", d.Source)
+		assert.Equal(t, severityError, d.Severity)
+		messages = append(messages, fmt.Sprintf("%d:%d %s %s", d.Range.Start.Line, d.Range.Start.Character, d.Code, d.Message))
+	}
+	old := at(`"old"`, 0)
+	edge := at("FriendWith ", 0)
+	assert.Equal(t, []string{
+		fmt.Sprintf("%d:%d V0111 Property value does not match its type in the schema", old.Line, old.Character),
+		fmt.Sprintf("%d:%d V0108 Edge missing: FriendWith\nDid you mean \"FriendsWith\"?", edge.Line, edge.Character),
+	}, messages)
+
+	// the type of the property is related to the mismatch
+	related := published.Diagnostics[0].RelatedInformation
+	assert.Len(t, related, 1)
+	assert.Equal(t, at("age  int", 0), related[0].Location.Range.Start)
+
+	// a syntax error does not hide the errors of the statements after it
+	c.notify("textDocument/didChange", map[string]any{
+		"textDocument":   map[string]any{"uri": uri, "version": 2},
+		"contentChanges": []map[string]any{{"text": "Schema {\n}\nQuery Persn"}},
+	})
+	published = c.diagnostics()
+	assert.Equal(t, 2, *published.Version)
+	assert.Len(t, published.Diagnostics, 2)
+	assert.Equal(t, "V0001", published.Diagnostics[0].Code)
+	assert.Equal(t, position{Line: 2, Character: 6}, published.Diagnostics[1].Range.Start)
+
+	// closing the document clears them
+	c.notify("textDocument/didClose", map[string]any{"textDocument": map[string]any{"uri": uri}})
+	assert.Empty(t, c.diagnostics().Diagnostics)
+}
+
+func TestCompletion(t *testing.T) {
+	c := start(t)
+	c.open(testProgram + "Query Person as Q { []Li")
+
+	labels := func(p position) map[string]string {
+		var items []completionItem
+		assert.Nil(t, c.request("textDocument/completion", positionParams(p), &items))
+		labels := make(map[string]string)
+		for _, item := range items {
+			labels[item.Label] = item.Detail
+		}
+		return labels
+	}
+
+	// keywords and names
+	end := position{Line: at("Query", 0).Line + 1, Character: 0}
+	names := labels(end)
+	for _, name := range []string{"Query", "Schema", "Count", "Person", "Ann", "FriendsWith", "P"} {
+		assert.Contains(t, names, name)
+	}
+
+	// edges after a relation term
+	assert.Equal(t, map[string]string{"FriendsWith": "Edge", "LivesIn": "Edge"}, labels(position{Line: end.Line, Character: 24}))
+
+	// properties of the schema of an alias
+	dot := at("P.age", 0)
+	dot.Character += 2
+	assert.Equal(t, map[string]string{"name": "name string (Person)", "age": "age int (Person)"}, labels(dot))
+}
+
+func TestHoverAndDefinition(t *testing.T) {
+	c := start(t)
+	c.open(testProgram)
+
+	var h hover
+	assert.Nil(t, c.request("textDocument/hover", positionParams(at("age <", 0)), &h))
+	assert.Equal(t, "markdown", h.Contents.Kind)
+	assert.Equal(t, "```vortex\nage int (Person)\n```", h.Contents.Value)
+
+	assert.Nil(t, c.request("textDocument/hover", positionParams(at("FriendsWith {", 0)), &h))
+	assert.Equal(t, "```vortex\nEdge FriendsWith TwoWay\n```", h.Contents.Value)
+
+	// from an alias to its binding and from a vertex to its definition
+	var l location
+	assert.Nil(t, c.request("textDocument/definition", positionParams(at("P.age", 0)), &l))
+	assert.Equal(t, uri, l.URI)
+	assert.Equal(t, at("P {", 0), l.Range.Start)
+
+	assert.Nil(t, c.request("textDocument/definition", positionParams(at("Bob }", 0)), &l))
+	assert.Equal(t, at("Bob Person", 0), l.Range.Start)
+
+	// nothing to show between the names
+	var none *location
+	assert.Nil(t, c.request("textDocument/definition", positionParams(position{Line: 6, Character: 0}), &none))
+	assert.Nil(t, none)
+}
+
+func TestDocumentSymbols(t *testing.T) {
+	c := start(t)
+	c.open(testProgram)
+
+	var symbols []documentSymbol
+	assert.Nil(t, c.request("textDocument/documentSymbol", map[string]any{"textDocument": map[string]any{"uri": uri}}, &symbols))
+	var names []string
+	for _, s := range symbols {
+		names = append(names, s.Name)
+	}
+	assert.Equal(t, []string{
+		"Person", "City", "Ann", "Bob", "Cid", "Paris", "FriendsWith", "LivesIn",
+		"Relation FriendsWith { Ann Bob }",
+		"Query Person as P { []FriendWith Person { .age < P.age } }",
+	}, names)
+
+	assert.Equal(t, symbolClass, symbols[0].Kind)
+	assert.Equal(t, "age", symbols[0].Children[1].Name)
+	assert.Equal(t, "int", symbols[0].Children[1].Detail)
+	assert.Equal(t, lspRange{Start: position{0, 0}, End: position{3, 1}}, symbols[0].Range)
+	assert.Equal(t, lspRange{Start: position{0, 7}, End: position{0, 13}}, symbols[0].SelectionRange)
+}
+
+func TestPositionEncodings(t *testing.T) {
+	// the schema and the property are after a character of two UTF-16 code units
+	text := "Schema Café { name string }\nVertex 𝒜 Café { .nom = \"x\" }"
+
+	c := start(t)
+	published := c.open(text)
+	assert.Len(t, published.Diagnostics, 1)
+	assert.Equal(t, position{Line: 1, Character: 18}, published.Diagnostics[0].Range.Start)
+
+	var h hover
+	assert.Nil(t, c.request("textDocument/hover", positionParams(position{Line: 1, Character: 11}), &h))
+	assert.Equal(t, "```vortex\nSchema Café {\n  name string\n}\n```", h.Contents.Value)
+	assert.Equal(t, lspRange{Start: position{1, 10}, End: position{1, 14}}, h.Range)
+
+	// with UTF-32 the characters are the runes
+	c = start(t, "utf-16", "utf-32")
+	published = c.open(text)
+	assert.Equal(t, position{Line: 1, Character: 17}, published.Diagnostics[0].Range.Start)
+	assert.Nil(t, c.request("textDocument/hover", positionParams(position{Line: 1, Character: 10}), &h))
+	assert.Equal(t, lspRange{Start: position{1, 9}, End: position{1, 13}}, h.Range)
+}
+
+func TestPositionEncodingsWithCombiningMarks(t *testing.T) {
+	// नमस्ते is 4 characters of the source, 6 runes and 6 UTF-16 code units
+	text := "Schema नमस्ते { name string }\nVertex 𝒜 नमस्ते { .nom = \"x\" }"
+	symbols := func(c *client) []documentSymbol {
+		var symbols []documentSymbol
+		assert.Nil(t, c.request("textDocument/documentSymbol", map[string]any{"textDocument": map[string]any{"uri": uri}}, &symbols))
+		return symbols
+	}
+
+	c := start(t)
+	published := c.open(text)
+	assert.Len(t, published.Diagnostics, 1)
+	assert.Equal(t, position{Line: 1, Character: 20}, published.Diagnostics[0].Range.Start)
+	schema := symbols(c)[0]
+	assert.Equal(t, lspRange{Start: position{0, 7}, End: position{0, 13}}, schema.SelectionRange)
+	assert.Equal(t, lspRange{Start: position{0, 16}, End: position{0, 20}}, schema.Children[0].SelectionRange)
+	assert.Equal(t, position{0, 29}, schema.Range.End)
+
+	var h hover
+	assert.Nil(t, c.request("textDocument/hover", positionParams(position{Line: 1, Character: 12}), &h))
+	assert.Equal(t, lspRange{Start: position{1, 10}, End: position{1, 16}}, h.Range)
+
+	// with UTF-32 only 𝒜 is counted differently
+	c = start(t, "utf-32")
+	published = c.open(text)
+	assert.Equal(t, position{Line: 1, Character: 19}, published.Diagnostics[0].Range.Start)
+	schema = symbols(c)[0]
+	assert.Equal(t, lspRange{Start: position{0, 7}, End: position{0, 13}}, schema.SelectionRange)
+	assert.Equal(t, lspRange{Start: position{0, 16}, End: position{0, 20}}, schema.Children[0].SelectionRange)
+	assert.Equal(t, position{0, 29}, schema.Range.End)
+
+	assert.Nil(t, c.request("textDocument/hover", positionParams(position{Line: 1, Character: 11}), &h))
+	assert.Equal(t, lspRange{Start: position{1, 9}, End: position{1, 15}}, h.Range)
+}
+
+func TestLifecycle(t *testing.T) {
+	c := start(t)
+	err := c.request("textDocument/formatting", positionParams(position{}), new(any))
+	assert.Equal(t, codeMethodNotFound, err.Code)
+
+	assert.Nil(t, c.request("shutdown", nil, new(any)))
+	c.notify("exit", nil)
+	assert.NoError(t, <-c.served)
+
+	// exiting without shutting down is a failure
+	c = start(t)
+	c.notify("exit", nil)
+	assert.ErrorIs(t, <-c.served, ExitBeforeShutdown)
+}
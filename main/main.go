@@ -1,9 +1,10 @@
 package main
 
 import (
-	"log"
+	"os"
 	"strings"
 
+	"github.com/Jintumoni/vortex/errors"
 	"github.com/Jintumoni/vortex/executor"
 	"github.com/Jintumoni/vortex/lexer"
 	"github.com/Jintumoni/vortex/manager"
@@ -18,30 +19,37 @@ func main() {
     age int
   }
 
+  Schema City {
+    name string
+  }
+
   Vertex Harry Person {
     .name = "Harry"
     .age = 1
   }
 
+  Vertex London City {
+    .name = "London"
+  }
+
   Edge LivesIn OneWay
 
   Relation LivesIn {
     Harry London
   }
 
-  Query Sum(Person as P {
-      []FriendsWith Person {
-        2 + .income + 100 > P.income + 1
-        and .name = "John" and (.age = 1 or P.salary > 100)
-        and []FriendsWith P
-      }
-  })
+  Query Person as P {
+      []LivesIn City as C
+  }
+  Group By C.name
+  Return C.name, Count(P), Sum(P.age)
   `
 
 	lexer := lexer.NewLexer(strings.NewReader(input))
 	parser := parser.NewParser(lexer)
 	executor := executor.NewExecutor(appManager, parser)
 	if err := executor.Execute(); err != nil {
-		log.Fatal(err)
+		errors.Render(os.Stderr, errors.TextFormat(os.Stderr), errors.Diagnose(err))
+		os.Exit(1)
 	}
 }
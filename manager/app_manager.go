@@ -2,7 +2,11 @@ package manager
 
 import (
 	"errors"
+	"fmt"
+	"strconv"
 
+	vortexerrors "github.com/Jintumoni/vortex/errors"
+	"github.com/Jintumoni/vortex/lexer"
 	"github.com/Jintumoni/vortex/nodes"
 )
 
@@ -15,11 +19,45 @@ var (
 	RelationDoesNotExist = errors.New("Relation missing")
 	EdgeAlreadyExist     = errors.New("Edge already exist")
 	EdgeDoesNotExist     = errors.New("Edge missing")
+	PropertyDoesNotExist = errors.New("Property missing")
+	PropertyAlreadyExist = errors.New("Property already exist in the schema")
+	PropertyTypeMismatch = errors.New("Property value does not match its type in the schema")
 )
 
+func init() {
+	vortexerrors.RegisterCode("V0101", SchemaAlreadyExist)
+	vortexerrors.RegisterCode("V0102", SchemaDoesNotExist)
+	vortexerrors.RegisterCode("V0103", VertexAlreadyExist)
+	vortexerrors.RegisterCode("V0104", VertexDoesNotExist)
+	vortexerrors.RegisterCode("V0105", RelationAlreadyExist)
+	vortexerrors.RegisterCode("V0106", RelationDoesNotExist)
+	vortexerrors.RegisterCode("V0107", EdgeAlreadyExist)
+	vortexerrors.RegisterCode("V0108", EdgeDoesNotExist)
+	vortexerrors.RegisterCode("V0109", PropertyDoesNotExist)
+	vortexerrors.RegisterCode("V0110", PropertyAlreadyExist)
+	vortexerrors.RegisterCode("V0111", PropertyTypeMismatch)
+}
+
+// misspelt attaches the position of a name that does not exist to err, so
+// it can be shown in the source of the statement, with the names closest to it
+func misspelt(name *nodes.StringNode, err error, names []string) error {
+	return &vortexerrors.SemanticError{Span: name.Span, Err: err, Suggestions: vortexerrors.Suggest(name.Value, names)}
+}
+
+// duplicate attaches the position of a name written again to err, with the
+// position of the first one
+func duplicate(name, first *nodes.StringNode, err error) error {
+	e := &vortexerrors.SemanticError{Span: name.Span, Err: err}
+	if !first.Span.IsZero() {
+		e.Secondary = []vortexerrors.Label{{Span: first.Span, Message: "first written here"}}
+	}
+	return e
+}
+
 type NodeRelationPair struct {
-	Vertex   *nodes.VertexInitNode
-	Relation *nodes.EdgeDefNode
+	Vertex       *nodes.VertexInitNode
+	Relation     *nodes.EdgeDefNode
+	RelationInit *nodes.RelationInitNode // the relation statement that joined the vertices
 }
 type AdjacencyList map[*nodes.VertexInitNode][]*NodeRelationPair
 
@@ -29,21 +67,33 @@ type AppManager struct {
 	edgeStore     map[string]*nodes.EdgeDefNode
 	relationStore map[string]*nodes.RelationInitNode
 	graphStore    AdjacencyList
+	// the adjacency list with every edge reversed, for incoming traversals
+	reverseGraphStore AdjacencyList
+	// vertices in the order they were written, maps do not keep any order
+	vertexOrder []*nodes.VertexInitNode
+	// properties computed by algorithms, they are not part of the schema
+	computedStore map[*nodes.VertexInitNode]map[string]nodes.ASTNode
+	// cardinalities used by the query planner
+	statistics statistics
 }
 
 func NewAppManager() *AppManager {
 	return &AppManager{
-		schemaStore:   make(map[string]*nodes.SchemaDefNode),
-		vertexStore:   make(map[string]*nodes.VertexInitNode),
-		edgeStore:     make(map[string]*nodes.EdgeDefNode),
-		relationStore: make(map[string]*nodes.RelationInitNode),
+		schemaStore:       make(map[string]*nodes.SchemaDefNode),
+		vertexStore:       make(map[string]*nodes.VertexInitNode),
+		edgeStore:         make(map[string]*nodes.EdgeDefNode),
+		relationStore:     make(map[string]*nodes.RelationInitNode),
+		graphStore:        make(AdjacencyList),
+		reverseGraphStore: make(AdjacencyList),
+		computedStore:     make(map[*nodes.VertexInitNode]map[string]nodes.ASTNode),
+		statistics:        newStatistics(),
 	}
 }
 
 func (a *AppManager) WriteSchema(s *nodes.SchemaDefNode) error {
-	_, ok := a.schemaStore[s.SchemaName.Value]
+	first, ok := a.schemaStore[s.SchemaName.Value]
 	if ok {
-		return SchemaAlreadyExist
+		return duplicate(s.SchemaName, first.SchemaName, SchemaAlreadyExist)
 	}
 
 	a.schemaStore[s.SchemaName.Value] = s
@@ -59,13 +109,53 @@ func (a *AppManager) ReadSchema(s string) (*nodes.SchemaDefNode, error) {
 	return schemaNode, nil
 }
 
+// WriteVertex adds a vertex, its schema has to exist and hold the
+// properties it sets, with values of their type
 func (a *AppManager) WriteVertex(v *nodes.VertexInitNode) error {
-	_, ok := a.vertexStore[v.VertexName.Value]
+	first, ok := a.vertexStore[v.VertexName.Value]
 	if ok {
-		return VertexAlreadyExist
+		return duplicate(v.VertexName, first.VertexName, VertexAlreadyExist)
+	}
+	if err := a.checkProperties(v); err != nil {
+		return err
 	}
 
 	a.vertexStore[v.VertexName.Value] = v
+	a.vertexOrder = append(a.vertexOrder, v)
+	a.statistics.addVertex(v, len(a.vertexOrder)-1)
+	return nil
+}
+
+// checkProperties reports the first property of v that its schema does not
+// define or that has a value of another type
+func (a *AppManager) checkProperties(v *nodes.VertexInitNode) error {
+	schema, err := a.ReadSchema(v.SchemaName.Value)
+	if err != nil {
+		return misspelt(v.SchemaName, err, a.SchemaNames())
+	}
+
+	defs := make(map[string]*nodes.PropertyDefNode, len(schema.Properties))
+	for _, p := range schema.Properties {
+		def := p.(*nodes.PropertyDefNode)
+		defs[def.PropertyName.Value] = def
+	}
+	for _, p := range v.Properties {
+		init := p.(*nodes.PropertyInitNode)
+		def, ok := defs[init.PropertyName.Value]
+		if !ok {
+			return misspelt(init.PropertyName, PropertyDoesNotExist, a.PropertyNames(v))
+		}
+		if _, err := strconv.Atoi(init.PropertyValue.Value); def.PropertyType.Type == lexer.TokenInteger && err != nil {
+			e := &vortexerrors.SemanticError{Span: init.PropertyValue.Span, Err: PropertyTypeMismatch}
+			if !def.Span.IsZero() {
+				e.Secondary = []vortexerrors.Label{{
+					Span:    def.Span,
+					Message: fmt.Sprintf("%s is declared as %s here", def.PropertyName.Value, def.PropertyType.Value),
+				}}
+			}
+			return e
+		}
+	}
 	return nil
 }
 
@@ -75,6 +165,7 @@ func (a *AppManager) ReadVertex(s string) (*nodes.VertexInitNode, error) {
 		return nil, VertexDoesNotExist
 	}
 
+	a.statistics.indexHits.Add(1)
 	return vertexNode, nil
 }
 
@@ -88,9 +179,9 @@ func (a *AppManager) ReadRelation(s string) (*nodes.RelationInitNode, error) {
 }
 
 func (a *AppManager) WriteRelation(r *nodes.RelationInitNode) error {
-	_, ok := a.relationStore[r.Relation.Value]
+	first, ok := a.relationStore[r.Relation.Value]
 	if ok {
-		return RelationAlreadyExist
+		return duplicate(r.Relation, first.Relation, RelationAlreadyExist)
 	}
 	return a.JoinVertex(r)
 }
@@ -105,31 +196,139 @@ func (a *AppManager) ReadEdge(s string) (*nodes.EdgeDefNode, error) {
 }
 
 func (a *AppManager) WriteEdge(r *nodes.EdgeDefNode) error {
-	_, ok := a.edgeStore[r.EdgeName.Value]
+	first, ok := a.edgeStore[r.EdgeName.Value]
 	if ok {
-		return EdgeAlreadyExist
+		return duplicate(r.EdgeName, first.EdgeName, EdgeAlreadyExist)
 	}
 
+	a.edgeStore[r.EdgeName.Value] = r
 	return nil
 }
 
 func (a *AppManager) JoinVertex(r *nodes.RelationInitNode) error {
 	leftVertex, err := a.ReadVertex(r.LeftVertex.Value)
 	if err != nil {
-		return err
+		return misspelt(r.LeftVertex, err, a.VertexNames())
 	}
 	rightVertex, err := a.ReadVertex(r.RightVertex.Value)
 	if err != nil {
-		return err
+		return misspelt(r.RightVertex, err, a.VertexNames())
 	}
 	relation, err := a.ReadEdge(r.Relation.Value)
 	if err != nil {
-		return err
+		return misspelt(r.Relation, err, a.EdgeNames())
 	}
 
-	a.graphStore[leftVertex] = append(a.graphStore[leftVertex], &NodeRelationPair{Vertex: rightVertex, Relation: relation})
+	a.graphStore[leftVertex] = append(a.graphStore[leftVertex], &NodeRelationPair{Vertex: rightVertex, Relation: relation, RelationInit: r})
+	a.reverseGraphStore[rightVertex] = append(a.reverseGraphStore[rightVertex], &NodeRelationPair{Vertex: leftVertex, Relation: relation, RelationInit: r})
 	if relation.EdgeType == nodes.TwoWayEdge {
-		a.graphStore[rightVertex] = append(a.graphStore[rightVertex], &NodeRelationPair{Vertex: leftVertex, Relation: relation})
+		a.graphStore[rightVertex] = append(a.graphStore[rightVertex], &NodeRelationPair{Vertex: leftVertex, Relation: relation, RelationInit: r})
+		a.reverseGraphStore[leftVertex] = append(a.reverseGraphStore[leftVertex], &NodeRelationPair{Vertex: rightVertex, Relation: relation, RelationInit: r})
 	}
+	a.statistics.addRelation(relation)
 	return nil
 }
+
+// ReadVertices returns every vertex in the order it was written
+func (a *AppManager) ReadVertices() []*nodes.VertexInitNode {
+	return a.vertexOrder
+}
+
+// ReadVerticesBySchema returns the vertices initialized from the schema s
+func (a *AppManager) ReadVerticesBySchema(s string) []*nodes.VertexInitNode {
+	var vertices []*nodes.VertexInitNode
+	for _, v := range a.vertexOrder {
+		if v.SchemaName.Value == s {
+			vertices = append(vertices, v)
+		}
+	}
+
+	return vertices
+}
+
+// Neighbours returns the adjacency list entries of v
+func (a *AppManager) Neighbours(v *nodes.VertexInitNode) []*NodeRelationPair {
+	return a.graphStore[v]
+}
+
+// ReverseNeighbours returns the entries of the vertices with an edge towards v
+func (a *AppManager) ReverseNeighbours(v *nodes.VertexInitNode) []*NodeRelationPair {
+	return a.reverseGraphStore[v]
+}
+
+// ReadProperty returns the value of the property of v typed according to its schema
+func (a *AppManager) ReadProperty(v *nodes.VertexInitNode, property string) (nodes.ASTNode, error) {
+	schema, err := a.ReadSchema(v.SchemaName.Value)
+	if err != nil {
+		return nil, err
+	}
+
+	var propertyType lexer.TokenType
+	for _, p := range schema.Properties {
+		def := p.(*nodes.PropertyDefNode)
+		if def.PropertyName.Value == property {
+			propertyType = def.PropertyType.Type
+		}
+	}
+	if propertyType == 0 {
+		if value, ok := a.computedStore[v][property]; ok {
+			return value, nil
+		}
+		return nil, PropertyDoesNotExist
+	}
+
+	for _, p := range v.Properties {
+		init := p.(*nodes.PropertyInitNode)
+		if init.PropertyName.Value != property {
+			continue
+		}
+
+		if propertyType == lexer.TokenInteger {
+			number, err := strconv.Atoi(init.PropertyValue.Value)
+			if err != nil {
+				return nil, err
+			}
+			return &nodes.IntNode{Value: number}, nil
+		}
+		return &nodes.StringNode{Value: init.PropertyValue.Value}, nil
+	}
+
+	return nil, PropertyDoesNotExist
+}
+
+// WriteProperty sets a computed property on v, the properties of the schema can not be overwritten
+func (a *AppManager) WriteProperty(v *nodes.VertexInitNode, property string, value nodes.ASTNode) error {
+	schema, err := a.ReadSchema(v.SchemaName.Value)
+	if err != nil {
+		return err
+	}
+	for _, p := range schema.Properties {
+		if p.(*nodes.PropertyDefNode).PropertyName.Value == property {
+			return PropertyAlreadyExist
+		}
+	}
+
+	if a.computedStore[v] == nil {
+		a.computedStore[v] = make(map[string]nodes.ASTNode)
+	}
+	a.computedStore[v][property] = value
+	return nil
+}
+
+// ReadRelationProperty returns the value of a property set on the relation.
+// Edges have no schema, so a value that parses as an integer is an int.
+func (a *AppManager) ReadRelationProperty(r *nodes.RelationInitNode, property string) (nodes.ASTNode, error) {
+	for _, p := range r.Properties {
+		init := p.(*nodes.PropertyInitNode)
+		if init.PropertyName.Value != property {
+			continue
+		}
+
+		if number, err := strconv.Atoi(init.PropertyValue.Value); err == nil {
+			return &nodes.IntNode{Value: number}, nil
+		}
+		return &nodes.StringNode{Value: init.PropertyValue.Value}, nil
+	}
+
+	return nil, PropertyDoesNotExist
+}
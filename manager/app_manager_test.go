@@ -0,0 +1,72 @@
+package manager
+
+import (
+	"testing"
+
+	vortexerrors "github.com/Jintumoni/vortex/errors"
+	"github.com/Jintumoni/vortex/lexer"
+	"github.com/Jintumoni/vortex/nodes"
+	"github.com/stretchr/testify/assert"
+)
+
+func name(value string, col int) *nodes.StringNode {
+	return &nodes.StringNode{Value: value, Span: nodes.Span{Start: nodes.Position{Col: col}, End: nodes.Position{Col: col + len(value)}}}
+}
+
+func property(key, value string, col int) nodes.ASTNode {
+	return &nodes.PropertyInitNode{PropertyName: name(key, col), PropertyValue: name(value, col+len(key)+3)}
+}
+
+func TestWriteVertexChecksItsSchema(t *testing.T) {
+	a := NewAppManager()
+	assert.NoError(t, a.WriteSchema(&nodes.SchemaDefNode{
+		SchemaName: name("Person", 7),
+		Properties: []nodes.ASTNode{
+			&nodes.PropertyDefNode{PropertyName: name("age", 16), PropertyType: lexer.Token{Type: lexer.TokenInteger, Value: "int"}},
+		},
+	}))
+
+	tests := []struct {
+		vertex *nodes.VertexInitNode
+		err    error
+		span   *nodes.StringNode
+	}{
+		{&nodes.VertexInitNode{VertexName: name("Ann", 7), SchemaName: name("Human", 11)}, SchemaDoesNotExist, name("Human", 11)},
+		{&nodes.VertexInitNode{VertexName: name("Ann", 7), SchemaName: name("Person", 11), Properties: []nodes.ASTNode{property("name", "Ann", 20)}}, PropertyDoesNotExist, name("name", 20)},
+		{&nodes.VertexInitNode{VertexName: name("Ann", 7), SchemaName: name("Person", 11), Properties: []nodes.ASTNode{property("age", "old", 20)}}, PropertyTypeMismatch, name("old", 26)},
+	}
+	for _, test := range tests {
+		err := a.WriteVertex(test.vertex)
+		assert.ErrorIs(t, err, test.err)
+		var semantic *vortexerrors.SemanticError
+		assert.ErrorAs(t, err, &semantic)
+		assert.Equal(t, test.span.Span, semantic.Span)
+	}
+	assert.Empty(t, a.ReadVertices())
+
+	assert.NoError(t, a.WriteVertex(&nodes.VertexInitNode{VertexName: name("Ann", 7), SchemaName: name("Person", 11), Properties: []nodes.ASTNode{property("age", "30", 20)}}))
+	err := a.WriteVertex(&nodes.VertexInitNode{VertexName: name("Ann", 7), SchemaName: name("Person", 11)})
+	assert.ErrorIs(t, err, VertexAlreadyExist)
+	assert.Equal(t, "1:8: Vertex already exist", err.Error())
+	var semantic *vortexerrors.SemanticError
+	assert.ErrorAs(t, err, &semantic)
+	assert.Equal(t, []vortexerrors.Label{{Span: name("Ann", 7).Span, Message: "first written here"}}, semantic.Secondary)
+}
+
+func TestErrorsSuggestTheClosestNames(t *testing.T) {
+	a := NewAppManager()
+	assert.NoError(t, a.WriteSchema(&nodes.SchemaDefNode{SchemaName: name("Person", 7)}))
+	assert.NoError(t, a.WriteEdge(&nodes.EdgeDefNode{EdgeName: name("FriendsWith", 5)}))
+	assert.NoError(t, a.WriteVertex(&nodes.VertexInitNode{VertexName: name("Ann", 7), SchemaName: name("Person", 11)}))
+	assert.Equal(t, []string{"Person"}, a.SchemaNames())
+	assert.Equal(t, []string{"FriendsWith"}, a.EdgeNames())
+	assert.Equal(t, []string{"Ann"}, a.VertexNames())
+
+	err := a.WriteVertex(&nodes.VertexInitNode{VertexName: name("Bob", 7), SchemaName: name("Persn", 11)})
+	assert.ErrorIs(t, err, SchemaDoesNotExist)
+	assert.Equal(t, `1:12: Schema missing; did you mean "Person"?`, err.Error())
+
+	err = a.JoinVertex(&nodes.RelationInitNode{LeftVertex: name("Ann", 0), Relation: name("FriendWith", 9), RightVertex: name("Ann", 4)})
+	assert.ErrorIs(t, err, EdgeDoesNotExist)
+	assert.Equal(t, `1:10: Edge missing; did you mean "FriendsWith"?`, err.Error())
+}
@@ -0,0 +1,49 @@
+package manager
+
+import (
+	"sort"
+
+	"github.com/Jintumoni/vortex/nodes"
+)
+
+// SchemaNames returns the names of the schemas, sorted
+func (a *AppManager) SchemaNames() []string {
+	return sortedKeys(a.schemaStore)
+}
+
+// VertexNames returns the names of the vertices, sorted
+func (a *AppManager) VertexNames() []string {
+	return sortedKeys(a.vertexStore)
+}
+
+// EdgeNames returns the names of the edges, sorted
+func (a *AppManager) EdgeNames() []string {
+	return sortedKeys(a.edgeStore)
+}
+
+// PropertyNames returns the names of the properties defined by the schema of
+// v and of the ones computed for it, sorted
+func (a *AppManager) PropertyNames(v *nodes.VertexInitNode) []string {
+	var names []string
+	if schema, err := a.ReadSchema(v.SchemaName.Value); err == nil {
+		for _, p := range schema.Properties {
+			names = append(names, p.(*nodes.PropertyDefNode).PropertyName.Value)
+		}
+	}
+	for name := range a.computedStore[v] {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+	return names
+}
+
+func sortedKeys[T any](store map[string]T) []string {
+	names := make([]string, 0, len(store))
+	for name := range store {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+	return names
+}
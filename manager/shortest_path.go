@@ -0,0 +1,233 @@
+package manager
+
+import (
+	"container/heap"
+	"errors"
+	"math"
+
+	"github.com/Jintumoni/vortex/nodes"
+)
+
+var (
+	WeightNotNumeric = errors.New("Edge weight is not an int")
+	NegativeWeight   = errors.New("Edge weight is negative")
+)
+
+// ShortestPaths returns the shortest path from any of the sources to any of
+// the targets among the paths walked by the traversal. When all is set every
+// path of the shortest length is returned instead of the first one found.
+//
+// The length of a path is its number of edges, or the sum of the weight
+// property of its relations when weight is not empty.
+func (a *AppManager) ShortestPaths(sources, targets []*nodes.VertexInitNode, t Traversal, weight string, all bool) ([]*Path, error) {
+	isTarget := make(map[*nodes.VertexInitNode]bool, len(targets))
+	for _, v := range targets {
+		isTarget[v] = true
+	}
+
+	var shortest []*Path
+	shortestCost := -1
+	consider := func(path *Path, cost int) {
+		if shortestCost < 0 || cost < shortestCost {
+			shortest, shortestCost = []*Path{path}, cost
+		} else if cost == shortestCost && all {
+			shortest = append(shortest, path)
+		}
+	}
+
+	for _, source := range sources {
+		if t.LowerBound <= 1 && (weight == "" || t.UpperBound == math.MaxInt) {
+			paths, cost, err := a.dijkstra(source, isTarget, t, weight, all)
+			if err != nil {
+				return nil, err
+			}
+			for _, path := range paths {
+				consider(path, cost)
+			}
+			continue
+		}
+
+		// the bounds constrain the number of hops of a weighted path or
+		// exclude the shortest paths, so every path has to be walked
+		var err error
+		a.Traverse(source, t, func(path *Path) bool {
+			if !isTarget[path.Last()] {
+				return true
+			}
+			cost, e := a.pathCost(path, weight)
+			if e != nil {
+				err = e
+				return false
+			}
+			consider(path, cost)
+			return true
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return shortest, nil
+}
+
+// step is an entry in the list of predecessors of a vertex on its shortest paths
+type step struct {
+	from *nodes.VertexInitNode
+	pair *NodeRelationPair
+}
+
+// dijkstra returns the shortest paths from source to the closest targets and their length
+func (a *AppManager) dijkstra(source *nodes.VertexInitNode, isTarget map[*nodes.VertexInitNode]bool, t Traversal, weight string, all bool) ([]*Path, int, error) {
+	dist := map[*nodes.VertexInitNode]int{source: 0}
+	predecessors := make(map[*nodes.VertexInitNode][]step)
+	done := make(map[*nodes.VertexInitNode]bool)
+	queue := &priorityQueue{{vertex: source, cost: 0}}
+
+	var found []*nodes.VertexInitNode
+	foundCost := -1
+	for queue.Len() > 0 {
+		item := heap.Pop(queue).(queueItem)
+		if done[item.vertex] || item.cost > dist[item.vertex] {
+			continue
+		}
+		if foundCost >= 0 && (item.cost > foundCost || !all) {
+			break
+		}
+		done[item.vertex] = true
+
+		// a path never comes back to its source, so the source is only a
+		// target when the traversal accepts paths without edges
+		if isTarget[item.vertex] && (item.vertex != source || t.LowerBound == 0) {
+			found, foundCost = append(found, item.vertex), item.cost
+			continue
+		}
+		// without weights the cost of a path is its number of hops
+		if weight == "" && item.cost >= t.UpperBound {
+			continue
+		}
+
+		for _, pair := range a.follow(item.vertex, t) {
+			if pair.Vertex == source {
+				continue
+			}
+			w := 1
+			if weight != "" {
+				var err error
+				if w, err = a.weight(pair.RelationInit, weight); err != nil {
+					return nil, 0, err
+				}
+			}
+
+			cost := item.cost + w
+			d, ok := dist[pair.Vertex]
+			if !ok || cost < d {
+				dist[pair.Vertex] = cost
+				predecessors[pair.Vertex] = []step{{from: item.vertex, pair: pair}}
+				heap.Push(queue, queueItem{vertex: pair.Vertex, cost: cost})
+			} else if cost == d && all {
+				predecessors[pair.Vertex] = append(predecessors[pair.Vertex], step{from: item.vertex, pair: pair})
+			}
+		}
+	}
+
+	var paths []*Path
+	for _, target := range found {
+		paths = append(paths, unwind(source, target, predecessors, all)...)
+	}
+	return paths, foundCost, nil
+}
+
+// unwind rebuilds the paths from source to target out of the predecessors
+// recorded by dijkstra
+func unwind(source, target *nodes.VertexInitNode, predecessors map[*nodes.VertexInitNode][]step, all bool) []*Path {
+	var paths []*Path
+	var reversed []step
+	onPath := map[*nodes.VertexInitNode]bool{target: true}
+
+	var walk func(v *nodes.VertexInitNode) bool
+	walk = func(v *nodes.VertexInitNode) bool {
+		if v == source {
+			path := &Path{Vertices: []*nodes.VertexInitNode{source}}
+			for i := len(reversed) - 1; i >= 0; i-- {
+				path = path.extend(reversed[i].pair)
+			}
+			paths = append(paths, path)
+			return all
+		}
+
+		for _, s := range predecessors[v] {
+			// zero weight edges can record predecessors in a cycle
+			if onPath[s.from] {
+				continue
+			}
+			onPath[s.from] = true
+			reversed = append(reversed, s)
+			next := walk(s.from)
+			reversed = reversed[:len(reversed)-1]
+			onPath[s.from] = false
+			if !next {
+				return false
+			}
+		}
+		return true
+	}
+	walk(target)
+
+	return paths
+}
+
+func (a *AppManager) pathCost(path *Path, weight string) (int, error) {
+	if weight == "" {
+		return path.Len(), nil
+	}
+
+	cost := 0
+	for _, r := range path.Relations {
+		w, err := a.weight(r, weight)
+		if err != nil {
+			return 0, err
+		}
+		cost += w
+	}
+	return cost, nil
+}
+
+// weight returns the cost of walking through the relation
+func (a *AppManager) weight(r *nodes.RelationInitNode, property string) (int, error) {
+	value, err := a.ReadRelationProperty(r, property)
+	if err != nil {
+		return 0, err
+	}
+
+	number, ok := value.(*nodes.IntNode)
+	if !ok {
+		return 0, WeightNotNumeric
+	}
+	if number.Value < 0 {
+		return 0, NegativeWeight
+	}
+	return number.Value, nil
+}
+
+type queueItem struct {
+	vertex *nodes.VertexInitNode
+	cost   int
+}
+
+// priorityQueue is a min heap of vertices ordered by the cost to reach them
+type priorityQueue []queueItem
+
+func (q priorityQueue) Len() int           { return len(q) }
+func (q priorityQueue) Less(i, j int) bool { return q[i].cost < q[j].cost }
+func (q priorityQueue) Swap(i, j int)      { q[i], q[j] = q[j], q[i] }
+
+func (q *priorityQueue) Push(x any) {
+	*q = append(*q, x.(queueItem))
+}
+
+func (q *priorityQueue) Pop() any {
+	old := *q
+	item := old[len(old)-1]
+	*q = old[:len(old)-1]
+	return item
+}
@@ -0,0 +1,88 @@
+package manager
+
+import (
+	"math"
+	"testing"
+
+	"github.com/Jintumoni/vortex/nodes"
+	"github.com/stretchr/testify/assert"
+)
+
+// newRoadGraph builds a square A - B - D, A - C - D with a long direct road A - D
+func newRoadGraph(t *testing.T) *AppManager {
+	a := NewAppManager()
+	assert.NoError(t, a.WriteSchema(&nodes.SchemaDefNode{SchemaName: &nodes.StringNode{Value: "City"}}))
+	for _, name := range []string{"A", "B", "C", "D"} {
+		assert.NoError(t, a.WriteVertex(&nodes.VertexInitNode{
+			SchemaName: &nodes.StringNode{Value: "City"},
+			VertexName: &nodes.StringNode{Value: name},
+		}))
+	}
+	assert.NoError(t, a.WriteEdge(&nodes.EdgeDefNode{EdgeName: &nodes.StringNode{Value: "Road"}, EdgeType: nodes.TwoWayEdge}))
+
+	roads := []struct {
+		from, to, distance string
+	}{{"A", "B", "5"}, {"B", "D", "5"}, {"A", "C", "1"}, {"C", "D", "2"}, {"A", "D", "20"}}
+	for _, r := range roads {
+		assert.NoError(t, a.WriteRelation(&nodes.RelationInitNode{
+			LeftVertex:  &nodes.StringNode{Value: r.from},
+			Relation:    &nodes.StringNode{Value: "Road"},
+			RightVertex: &nodes.StringNode{Value: r.to},
+			Properties: []nodes.ASTNode{&nodes.PropertyInitNode{
+				PropertyName:  &nodes.StringNode{Value: "distance"},
+				PropertyValue: &nodes.StringNode{Value: r.distance},
+			}},
+		}))
+	}
+	return a
+}
+
+func shortestPaths(t *testing.T, a *AppManager, traversal Traversal, weight string, all bool) [][]string {
+	from, _ := a.ReadVertex("A")
+	to, _ := a.ReadVertex("D")
+	paths, err := a.ShortestPaths([]*nodes.VertexInitNode{from}, []*nodes.VertexInitNode{to}, traversal, weight, all)
+	assert.NoError(t, err)
+
+	var names [][]string
+	for _, p := range paths {
+		names = append(names, vertexNames(p.Vertices))
+	}
+	return names
+}
+
+func TestShortestPathByHops(t *testing.T) {
+	a := newRoadGraph(t)
+	unbounded := Traversal{EdgeName: "Road", LowerBound: 0, UpperBound: math.MaxInt}
+
+	assert.Equal(t, [][]string{{"A", "D"}}, shortestPaths(t, a, unbounded, "", false))
+}
+
+func TestShortestPathByWeight(t *testing.T) {
+	a := newRoadGraph(t)
+	unbounded := Traversal{EdgeName: "Road", LowerBound: 0, UpperBound: math.MaxInt}
+
+	assert.Equal(t, [][]string{{"A", "C", "D"}}, shortestPaths(t, a, unbounded, "distance", false))
+}
+
+func TestAllShortestPathsWithLowerBound(t *testing.T) {
+	a := newRoadGraph(t)
+	atLeastTwo := Traversal{EdgeName: "Road", LowerBound: 2, UpperBound: math.MaxInt}
+
+	assert.Equal(t, [][]string{{"A", "B", "D"}, {"A", "C", "D"}}, shortestPaths(t, a, atLeastTwo, "", true))
+}
+
+func TestShortestPathWithHopLimitAndWeight(t *testing.T) {
+	a := newRoadGraph(t)
+	oneHop := Traversal{EdgeName: "Road", LowerBound: 1, UpperBound: 1}
+
+	assert.Equal(t, [][]string{{"A", "D"}}, shortestPaths(t, a, oneHop, "distance", false))
+}
+
+func TestShortestPathWithMissingWeight(t *testing.T) {
+	a := newRoadGraph(t)
+	from, _ := a.ReadVertex("A")
+	to, _ := a.ReadVertex("D")
+
+	_, err := a.ShortestPaths([]*nodes.VertexInitNode{from}, []*nodes.VertexInitNode{to}, Traversal{LowerBound: 1, UpperBound: math.MaxInt}, "toll", false)
+	assert.ErrorIs(t, err, PropertyDoesNotExist)
+}
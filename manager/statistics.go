@@ -0,0 +1,98 @@
+package manager
+
+import (
+	"sync/atomic"
+
+	"github.com/Jintumoni/vortex/nodes"
+)
+
+// statistics are the cardinalities of the graph, they are kept up to date
+// as the graph is written so that planning a query never scans it
+type statistics struct {
+	schemas  map[string]int // number of vertices of every schema
+	edges    map[string]int // number of adjacency list entries of every edge
+	entries  int            // number of adjacency list entries of all edges
+	position map[*nodes.VertexInitNode]int
+	// changed by every write of a vertex or a relation
+	generation int
+
+	// work done by reads, they only ever grow
+	adjacencyEntries atomic.Int64
+	indexHits        atomic.Int64
+}
+
+// Counters is the work done reading the graph since the AppManager was created
+type Counters struct {
+	AdjacencyEntries int64 // adjacency list entries read by traversals
+	IndexHits        int64 // vertices found by their name
+}
+
+// Sub returns the work done between the counters c and since
+func (c Counters) Sub(since Counters) Counters {
+	return Counters{
+		AdjacencyEntries: c.AdjacencyEntries - since.AdjacencyEntries,
+		IndexHits:        c.IndexHits - since.IndexHits,
+	}
+}
+
+func newStatistics() statistics {
+	return statistics{
+		schemas:  make(map[string]int),
+		edges:    make(map[string]int),
+		position: make(map[*nodes.VertexInitNode]int),
+	}
+}
+
+func (s *statistics) addVertex(v *nodes.VertexInitNode, position int) {
+	s.schemas[v.SchemaName.Value]++
+	s.position[v] = position
+	s.generation++
+}
+
+func (s *statistics) addRelation(edge *nodes.EdgeDefNode) {
+	entries := 1
+	if edge.EdgeType == nodes.TwoWayEdge {
+		entries = 2
+	}
+	s.edges[edge.EdgeName.Value] += entries
+	s.entries += entries
+	s.generation++
+}
+
+// VertexCardinality returns the number of vertices in the graph
+func (a *AppManager) VertexCardinality() int {
+	return len(a.vertexOrder)
+}
+
+// SchemaCardinality returns the number of vertices initialized from the schema s
+func (a *AppManager) SchemaCardinality(s string) int {
+	return a.statistics.schemas[s]
+}
+
+// EdgeCardinality returns the number of adjacency list entries of the edge e,
+// or of every edge when e is empty. A TwoWay relation has an entry per direction.
+func (a *AppManager) EdgeCardinality(e string) int {
+	if e == "" {
+		return a.statistics.entries
+	}
+	return a.statistics.edges[e]
+}
+
+// Generation returns a number that changes whenever the cardinalities do,
+// a plan made before it changed may no longer be the cheapest one
+func (a *AppManager) Generation() int {
+	return a.statistics.generation
+}
+
+// Position returns the index of v in the order the vertices were written
+func (a *AppManager) Position(v *nodes.VertexInitNode) int {
+	return a.statistics.position[v]
+}
+
+// Counters returns the work done reading the graph so far
+func (a *AppManager) Counters() Counters {
+	return Counters{
+		AdjacencyEntries: a.statistics.adjacencyEntries.Load(),
+		IndexHits:        a.statistics.indexHits.Load(),
+	}
+}
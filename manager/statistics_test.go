@@ -0,0 +1,22 @@
+package manager
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStatistics(t *testing.T) {
+	a := newTestGraph(t)
+
+	assert.Equal(t, 4, a.VertexCardinality())
+	assert.Equal(t, 4, a.SchemaCardinality("Place"))
+	assert.Equal(t, 0, a.SchemaCardinality("City"))
+	assert.Equal(t, 3, a.EdgeCardinality("Next"))
+	// a TwoWay relation has an entry in both directions
+	assert.Equal(t, 2, a.EdgeCardinality("Near"))
+	assert.Equal(t, 5, a.EdgeCardinality(""))
+
+	c, _ := a.ReadVertex("C")
+	assert.Equal(t, 2, a.Position(c))
+}
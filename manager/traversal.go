@@ -0,0 +1,227 @@
+package manager
+
+import (
+	"math"
+
+	"github.com/Jintumoni/vortex/nodes"
+)
+
+type TraversalStrategy int
+
+const (
+	BreadthFirst TraversalStrategy = iota + 1
+	DepthFirst
+)
+
+func (s TraversalStrategy) String() string {
+	switch s {
+	case BreadthFirst:
+		return "BFS"
+	case DepthFirst:
+		return "DFS"
+	default:
+		return ""
+	}
+}
+
+// Path is a walk through the graph, Edges[i] joins Vertices[i] and Vertices[i+1]
+type Path struct {
+	Vertices  []*nodes.VertexInitNode
+	Edges     []*nodes.EdgeDefNode
+	Relations []*nodes.RelationInitNode // Relations[i] is the instance of Edges[i]
+}
+
+func (p *Path) Len() int {
+	return len(p.Edges)
+}
+
+func (p *Path) Last() *nodes.VertexInitNode {
+	return p.Vertices[len(p.Vertices)-1]
+}
+
+func (p *Path) contains(v *nodes.VertexInitNode) bool {
+	for _, u := range p.Vertices {
+		if u == v {
+			return true
+		}
+	}
+	return false
+}
+
+// extend returns a copy of the path with one more hop
+func (p *Path) extend(pair *NodeRelationPair) *Path {
+	path := &Path{
+		Vertices:  make([]*nodes.VertexInitNode, len(p.Vertices), len(p.Vertices)+1),
+		Edges:     make([]*nodes.EdgeDefNode, len(p.Edges), len(p.Edges)+1),
+		Relations: make([]*nodes.RelationInitNode, len(p.Relations), len(p.Relations)+1),
+	}
+	copy(path.Vertices, p.Vertices)
+	copy(path.Edges, p.Edges)
+	copy(path.Relations, p.Relations)
+	path.Vertices = append(path.Vertices, pair.Vertex)
+	path.Edges = append(path.Edges, pair.Relation)
+	path.Relations = append(path.Relations, pair.RelationInit)
+	return path
+}
+
+// Traversal describes a variable length hop like [lo..hi]Edge
+type Traversal struct {
+	EdgeName   string // name of the edge to follow, empty to follow any edge
+	LowerBound int
+	UpperBound int // math.MaxInt for an unbounded traversal
+	Strategy   TraversalStrategy
+	Direction  nodes.Direction // defaults to nodes.Outgoing
+	// Halt is called with the number of adjacency list entries about to be
+	// read, the walk stops following edges once it returns true. nil never halts.
+	Halt func(entries int) bool
+}
+
+// Traverse walks every path from start whose length lies within the bounds
+// of the traversal and calls visit on it. A path never visits a vertex twice,
+// which also makes every traversal finite. visit returns false to stop the walk.
+func (a *AppManager) Traverse(start *nodes.VertexInitNode, t Traversal, visit func(*Path) bool) {
+	// a path without repeated vertices can not be longer than the number of vertices
+	upper := t.UpperBound
+	if upper == math.MaxInt || upper >= len(a.vertexOrder) {
+		upper = max(len(a.vertexOrder)-1, 0)
+	}
+	if t.LowerBound > upper {
+		return
+	}
+
+	root := &Path{Vertices: []*nodes.VertexInitNode{start}}
+	if t.Strategy == DepthFirst {
+		a.traverseDepthFirst(root, t, upper, visit)
+	} else {
+		a.traverseBreadthFirst(root, t, upper, visit)
+	}
+}
+
+func (a *AppManager) traverseBreadthFirst(root *Path, t Traversal, upper int, visit func(*Path) bool) {
+	queue := []*Path{root}
+	for len(queue) > 0 {
+		path := queue[0]
+		queue = queue[1:]
+
+		if path.Len() >= t.LowerBound && !visit(path) {
+			return
+		}
+		if path.Len() == upper {
+			continue
+		}
+
+		for _, pair := range a.follow(path.Last(), t) {
+			if !path.contains(pair.Vertex) {
+				queue = append(queue, path.extend(pair))
+			}
+		}
+	}
+}
+
+func (a *AppManager) traverseDepthFirst(path *Path, t Traversal, upper int, visit func(*Path) bool) bool {
+	if path.Len() >= t.LowerBound && !visit(path) {
+		return false
+	}
+	if path.Len() == upper {
+		return true
+	}
+
+	for _, pair := range a.follow(path.Last(), t) {
+		if path.contains(pair.Vertex) {
+			continue
+		}
+		if !a.traverseDepthFirst(path.extend(pair), t, upper, visit) {
+			return false
+		}
+	}
+	return true
+}
+
+// adjacency identifies an entry of an adjacency list, the same relation is
+// in the forward list of a vertex and in the reverse one when it is TwoWay
+type adjacency struct {
+	vertex   *nodes.VertexInitNode
+	relation *nodes.RelationInitNode
+}
+
+// follow returns the adjacency list entries of v through the edge of the
+// traversal, in the direction of the traversal
+func (a *AppManager) follow(v *nodes.VertexInitNode, t Traversal) []*NodeRelationPair {
+	var candidates []*NodeRelationPair
+	switch t.Direction {
+	case nodes.Incoming:
+		candidates = a.reverseGraphStore[v]
+	case nodes.AnyDirection:
+		// a TwoWay relation is in both lists, it is only followed once
+		seen := make(map[adjacency]bool, len(a.graphStore[v]))
+		for _, pair := range a.graphStore[v] {
+			seen[adjacency{pair.Vertex, pair.RelationInit}] = true
+		}
+		candidates = append(candidates, a.graphStore[v]...)
+		for _, pair := range a.reverseGraphStore[v] {
+			if !seen[adjacency{pair.Vertex, pair.RelationInit}] {
+				candidates = append(candidates, pair)
+			}
+		}
+	default:
+		candidates = a.graphStore[v]
+	}
+	if t.Halt != nil && t.Halt(len(candidates)) {
+		return nil
+	}
+	a.statistics.adjacencyEntries.Add(int64(len(candidates)))
+
+	if t.EdgeName == "" {
+		return candidates
+	}
+
+	var pairs []*NodeRelationPair
+	for _, pair := range candidates {
+		if pair.Relation.EdgeName.Value == t.EdgeName {
+			pairs = append(pairs, pair)
+		}
+	}
+	return pairs
+}
+
+// Reach returns the distinct vertices at the end of the paths walked by the
+// traversal, in the order they are first reached.
+func (a *AppManager) Reach(start *nodes.VertexInitNode, t Traversal) []*nodes.VertexInitNode {
+	var reached []*nodes.VertexInitNode
+	seen := make(map[*nodes.VertexInitNode]bool)
+
+	if t.UpperBound == math.MaxInt && t.LowerBound <= 1 {
+		// every vertex reachable from start is the end of a path without
+		// repeated vertices, so a plain graph search is enough
+		seen[start] = true
+		if t.LowerBound == 0 {
+			reached = append(reached, start)
+		}
+		frontier := []*nodes.VertexInitNode{start}
+		for len(frontier) > 0 {
+			var v *nodes.VertexInitNode
+			if t.Strategy == DepthFirst {
+				v, frontier = frontier[len(frontier)-1], frontier[:len(frontier)-1]
+			} else {
+				v, frontier = frontier[0], frontier[1:]
+			}
+			for _, pair := range a.follow(v, t) {
+				if !seen[pair.Vertex] {
+					seen[pair.Vertex] = true
+					reached = append(reached, pair.Vertex)
+					frontier = append(frontier, pair.Vertex)
+				}
+			}
+		}
+		return reached
+	}
+
+	a.Traverse(start, t, func(p *Path) bool {
+		if !seen[p.Last()] {
+			seen[p.Last()] = true
+			reached = append(reached, p.Last())
+		}
+		return true
+	})
+	return reached
+}
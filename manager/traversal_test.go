@@ -0,0 +1,145 @@
+package manager
+
+import (
+	"math"
+	"testing"
+
+	"github.com/Jintumoni/vortex/nodes"
+	"github.com/stretchr/testify/assert"
+)
+
+// newTestGraph builds the cycle A -> B -> C -> A through the OneWay edge Next
+// and the TwoWay edge Near between C and D
+func newTestGraph(t *testing.T) *AppManager {
+	a := NewAppManager()
+	assert.NoError(t, a.WriteSchema(&nodes.SchemaDefNode{SchemaName: &nodes.StringNode{Value: "Place"}}))
+	for _, name := range []string{"A", "B", "C", "D"} {
+		assert.NoError(t, a.WriteVertex(&nodes.VertexInitNode{
+			SchemaName: &nodes.StringNode{Value: "Place"},
+			VertexName: &nodes.StringNode{Value: name},
+		}))
+	}
+	assert.NoError(t, a.WriteEdge(&nodes.EdgeDefNode{EdgeName: &nodes.StringNode{Value: "Next"}, EdgeType: nodes.OneWayEdge}))
+	assert.NoError(t, a.WriteEdge(&nodes.EdgeDefNode{EdgeName: &nodes.StringNode{Value: "Near"}, EdgeType: nodes.TwoWayEdge}))
+
+	for _, r := range [][3]string{{"A", "Next", "B"}, {"B", "Next", "C"}, {"C", "Next", "A"}, {"C", "Near", "D"}} {
+		assert.NoError(t, a.WriteRelation(&nodes.RelationInitNode{
+			LeftVertex:  &nodes.StringNode{Value: r[0]},
+			Relation:    &nodes.StringNode{Value: r[1]},
+			RightVertex: &nodes.StringNode{Value: r[2]},
+		}))
+	}
+	return a
+}
+
+func vertexNames(vertices []*nodes.VertexInitNode) []string {
+	var names []string
+	for _, v := range vertices {
+		names = append(names, v.VertexName.Value)
+	}
+	return names
+}
+
+func TestTraverseExactLength(t *testing.T) {
+	a := newTestGraph(t)
+	start, _ := a.ReadVertex("A")
+
+	var paths []*Path
+	a.Traverse(start, Traversal{EdgeName: "Next", LowerBound: 2, UpperBound: 2}, func(p *Path) bool {
+		paths = append(paths, p)
+		return true
+	})
+
+	assert.Len(t, paths, 1)
+	assert.Equal(t, []string{"A", "B", "C"}, vertexNames(paths[0].Vertices))
+	assert.Equal(t, 2, paths[0].Len())
+}
+
+func TestTraverseDoesNotRevisitVertices(t *testing.T) {
+	a := newTestGraph(t)
+	start, _ := a.ReadVertex("A")
+
+	var ends []*nodes.VertexInitNode
+	a.Traverse(start, Traversal{EdgeName: "Next", LowerBound: 0, UpperBound: math.MaxInt}, func(p *Path) bool {
+		ends = append(ends, p.Last())
+		return true
+	})
+
+	// the cycle back to A is never taken
+	assert.Equal(t, []string{"A", "B", "C"}, vertexNames(ends))
+}
+
+func TestTraverseStopsWhenVisitReturnsFalse(t *testing.T) {
+	a := newTestGraph(t)
+	start, _ := a.ReadVertex("A")
+
+	visited := 0
+	a.Traverse(start, Traversal{LowerBound: 0, UpperBound: math.MaxInt, Strategy: DepthFirst}, func(p *Path) bool {
+		visited++
+		return visited < 2
+	})
+	assert.Equal(t, 2, visited)
+}
+
+func TestReach(t *testing.T) {
+	a := newTestGraph(t)
+	start, _ := a.ReadVertex("A")
+
+	assert.Equal(t, []string{"B", "C", "D"}, vertexNames(a.Reach(start, Traversal{LowerBound: 1, UpperBound: math.MaxInt})))
+	assert.Equal(t, []string{"A", "B", "C"}, vertexNames(a.Reach(start, Traversal{EdgeName: "Next", LowerBound: 0, UpperBound: math.MaxInt})))
+	assert.Equal(t, []string{"C", "D"}, vertexNames(a.Reach(start, Traversal{LowerBound: 2, UpperBound: 3, Strategy: DepthFirst})))
+}
+
+func TestReachWithDirection(t *testing.T) {
+	a := newTestGraph(t)
+	start, _ := a.ReadVertex("B")
+
+	assert.Equal(t, []string{"C"}, vertexNames(a.Reach(start, Traversal{EdgeName: "Next", LowerBound: 1, UpperBound: 1, Direction: nodes.Outgoing})))
+	assert.Equal(t, []string{"A"}, vertexNames(a.Reach(start, Traversal{EdgeName: "Next", LowerBound: 1, UpperBound: 1, Direction: nodes.Incoming})))
+	assert.Equal(t, []string{"C", "A"}, vertexNames(a.Reach(start, Traversal{EdgeName: "Next", LowerBound: 1, UpperBound: 1, Direction: nodes.AnyDirection})))
+
+	// a TwoWay relation is walked once in either direction
+	d, _ := a.ReadVertex("D")
+	assert.Equal(t, []string{"C"}, vertexNames(a.Reach(d, Traversal{EdgeName: "Near", LowerBound: 1, UpperBound: 1, Direction: nodes.Incoming})))
+	assert.Len(t, a.follow(d, Traversal{EdgeName: "Near", Direction: nodes.AnyDirection}), 1)
+}
+
+func TestFollowAnyDirectionOfHub(t *testing.T) {
+	a := newTestGraph(t)
+	assert.NoError(t, a.WriteVertex(&nodes.VertexInitNode{
+		SchemaName: &nodes.StringNode{Value: "Place"},
+		VertexName: &nodes.StringNode{Value: "H"},
+	}))
+	for _, r := range [][3]string{{"H", "Near", "A"}, {"B", "Near", "H"}, {"H", "Near", "D"}, {"H", "Near", "D"}, {"A", "Next", "H"}, {"H", "Next", "B"}} {
+		assert.NoError(t, a.WriteRelation(&nodes.RelationInitNode{
+			LeftVertex:  &nodes.StringNode{Value: r[0]},
+			Relation:    &nodes.StringNode{Value: r[1]},
+			RightVertex: &nodes.StringNode{Value: r[2]},
+		}))
+	}
+
+	// every relation once, the two relations joining H and D are distinct
+	h, _ := a.ReadVertex("H")
+	var names []string
+	for _, pair := range a.follow(h, Traversal{Direction: nodes.AnyDirection}) {
+		names = append(names, pair.Relation.EdgeName.Value+" "+pair.Vertex.VertexName.Value)
+	}
+	assert.ElementsMatch(t, []string{"Near A", "Near B", "Near D", "Near D", "Next A", "Next B"}, names)
+}
+
+func TestTraversalHalts(t *testing.T) {
+	a := newTestGraph(t)
+	start, _ := a.ReadVertex("A")
+
+	// A, B and C have one entry each, the walk halts before following C
+	read := 0
+	halt := func(entries int) bool {
+		if read+entries > 2 {
+			return true
+		}
+		read += entries
+		return false
+	}
+	assert.Equal(t, []string{"B", "C"}, vertexNames(a.Reach(start, Traversal{EdgeName: "Next", LowerBound: 1, UpperBound: math.MaxInt, Halt: halt})))
+	assert.Equal(t, 2, read)
+}
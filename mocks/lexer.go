@@ -18,3 +18,8 @@ func (m *MockLexer) GetSourceContext() string {
 	args := m.Called()
 	return args.Get(0).(string)
 }
+
+func (m *MockLexer) GetSourceContextAt(row int) string {
+	args := m.Called(row)
+	return args.Get(0).(string)
+}
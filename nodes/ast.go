@@ -3,6 +3,8 @@ package nodes
 type ASTNode interface {
 	// TODO: implement visitor class
 	Accept(v Visitor)
+	// GetSpan returns the part of the source the node was parsed from, zero for values computed by queries
+	GetSpan() Span
 }
 
 type BuiltinFuncNode interface {
@@ -36,6 +36,30 @@ func (node *IntNode) Accept(visitor Visitor) {
 	visitor.VisitIntNode(node)
 }
 
+func (node *FloatNode) Accept(visitor Visitor) {
+	visitor.VisitFloatNode(node)
+}
+
+func (node *BoolNode) Accept(visitor Visitor) {
+	visitor.VisitBoolNode(node)
+}
+
+func (node *NullNode) Accept(visitor Visitor) {
+	visitor.VisitNullNode(node)
+}
+
+func (node *ListNode) Accept(visitor Visitor) {
+	visitor.VisitListNode(node)
+}
+
+func (node *ParameterNode) Accept(visitor Visitor) {
+	visitor.VisitParameterNode(node)
+}
+
+func (node *PathNode) Accept(visitor Visitor) {
+	visitor.VisitPathNode(node)
+}
+
 func (node *EdgeNode) Accept(visitor Visitor) {
 	visitor.VisitEdgeNode(node)
 }
@@ -64,6 +88,54 @@ func (node *QueryStatementNode) Accept(visitor Visitor) {
 	visitor.VisitQueryStatement(node)
 }
 
+func (node *ExplainStatementNode) Accept(visitor Visitor) {
+	visitor.VisitExplainStatement(node)
+}
+
+func (node *ProfileStatementNode) Accept(visitor Visitor) {
+	visitor.VisitProfileStatement(node)
+}
+
+func (node *CallStatementNode) Accept(visitor Visitor) {
+	visitor.VisitCallStatement(node)
+}
+
 func (node *SumFuncNode) Accept(visitor Visitor) {
 	visitor.VisitSumFunc(node)
 }
+
+func (node *CountFuncNode) Accept(visitor Visitor) {
+	visitor.VisitCountFunc(node)
+}
+
+func (node *AvgFuncNode) Accept(visitor Visitor) {
+	visitor.VisitAvgFunc(node)
+}
+
+func (node *MaxFuncNode) Accept(visitor Visitor) {
+	visitor.VisitMaxFunc(node)
+}
+
+func (node *MinFuncNode) Accept(visitor Visitor) {
+	visitor.VisitMinFunc(node)
+}
+
+func (node *ShortestPathFuncNode) Accept(visitor Visitor) {
+	visitor.VisitShortestPathFunc(node)
+}
+
+func (node *AllShortestPathsFuncNode) Accept(visitor Visitor) {
+	visitor.VisitAllShortestPathsFunc(node)
+}
+
+func (node *LengthFuncNode) Accept(visitor Visitor) {
+	visitor.VisitLengthFunc(node)
+}
+
+func (node *NodesFuncNode) Accept(visitor Visitor) {
+	visitor.VisitNodesFunc(node)
+}
+
+func (node *EdgesFuncNode) Accept(visitor Visitor) {
+	visitor.VisitEdgesFunc(node)
+}
@@ -4,108 +4,226 @@ import "github.com/Jintumoni/vortex/lexer"
 
 type StringNode struct {
 	Value string
+	Span  Span // where the name was written, zero for values computed by queries
 }
 
 type IntNode struct {
 	Value int
+	Span  Span
 }
 
 type BoolNode struct {
 	Value bool
+	Span  Span
+}
+
+type FloatNode struct {
+	Value float64
+	Span  Span
+}
+
+// NullNode is the value of an expression that has no result, eg: a path that does not exist
+type NullNode struct {
+	Span Span
+}
+
+type ListNode struct {
+	Values []ASTNode
+	Span   Span
+}
+
+// ParameterNode is a value supplied when the statement is executed, eg: $name
+type ParameterNode struct {
+	Name *StringNode
+	Span Span
+}
+
+// PathNode is an ordered walk through the graph, Edges[i] joins Vertices[i] and Vertices[i+1]
+type PathNode struct {
+	Vertices []*VertexInitNode
+	Edges    []*EdgeDefNode
+	Span     Span
 }
 
 type BinaryNode struct {
 	LeftChild  ASTNode
 	Operator   lexer.Token
 	RightChild ASTNode
+	Span       Span
 }
 
 type ProgramStatementNode struct {
 	Children []ASTNode
+	Span     Span
 }
 
 type QueryStatementNode struct {
 	Expression ASTNode
+	GroupBy    []ASTNode // Group By expressions, nil when the query is not grouped
+	Having     ASTNode   // Having condition evaluated on every group
+	Return     []ASTNode // Return expressions, nil for the default projection
+	Span       Span
+}
+
+// ExplainStatementNode prints the plan of a query without running it, eg: Explain Query Person
+type ExplainStatementNode struct {
+	Query *QueryStatementNode
+	Span  Span
+}
+
+// ProfileStatementNode runs a query and reports the work done by every step of its plan, eg: Profile Query Person
+type ProfileStatementNode struct {
+	Query *QueryStatementNode
+	Span  Span
+}
+
+// CallStatementNode runs a graph algorithm, eg: Call PageRank(Person, FriendsWith) Write .rank
+type CallStatementNode struct {
+	Procedure *StringNode
+	Args      []ASTNode   // StringNode for the schema and the edge, IntNode for parameters
+	Write     *StringNode // property the results are written to, nil to return them
+	Span      Span
 }
 
 type SumFuncNode struct {
 	FunctionName FuncType
 	Args         []ASTNode
+	Span         Span
+}
+
+type CountFuncNode struct {
+	FunctionName FuncType
+	Args         []ASTNode
+	Span         Span
+}
+
+type AvgFuncNode struct {
+	FunctionName FuncType
+	Args         []ASTNode
+	Span         Span
+}
+
+type ShortestPathFuncNode struct {
+	FunctionName FuncType
+	Args         []ASTNode
+	Span         Span
+}
+
+type AllShortestPathsFuncNode struct {
+	FunctionName FuncType
+	Args         []ASTNode
+	Span         Span
+}
+
+type LengthFuncNode struct {
+	FunctionName FuncType
+	Args         []ASTNode
+	Span         Span
+}
+
+type NodesFuncNode struct {
+	FunctionName FuncType
+	Args         []ASTNode
+	Span         Span
+}
+
+type EdgesFuncNode struct {
+	FunctionName FuncType
+	Args         []ASTNode
+	Span         Span
 }
 
 type MaxFuncNode struct {
-	FunctionName *StringNode
+	FunctionName FuncType
 	Args         []ASTNode
+	Span         Span
 }
 
 type MinFuncNode struct {
-	FunctionName *StringNode
+	FunctionName FuncType
 	Args         []ASTNode
+	Span         Span
 }
 
 type StartsWithFuncNode struct {
 	FunctionName *StringNode
 	Args         []ASTNode
+	Span         Span
 }
 
 type VertexTermNode struct {
 	Vertex     *VertexNode
 	Conditions ASTNode
+	Span       Span
 }
 
 type VertexNode struct {
 	VertexName *StringNode
 	Alias      *StringNode
+	Span       Span
 }
 
 type RelationNode struct {
 	Edge   ASTNode
 	Vertex ASTNode
+	Span   Span
 }
 
 type ConditionNode struct {
 	Child ASTNode
+	Span  Span
 }
 
 type SchemaDefNode struct {
 	SchemaName *StringNode `json:"schema_name"`
 	Properties []ASTNode   `json:"properties"`
+	Span       Span        `json:"span"`
 }
 
 type VertexInitNode struct {
 	SchemaName *StringNode
 	VertexName *StringNode
 	Properties []ASTNode
+	Span       Span
 }
 
 type PropertyNode struct {
 	PropertyName *StringNode
 	Alias        *StringNode
+	Span         Span
 }
 
 type PropertyDefNode struct {
 	PropertyName *StringNode `json:"property_name"`
 	PropertyType lexer.Token `json:"property_type"`
+	Span         Span        `json:"span"`
 }
 
 type PropertyInitNode struct {
 	PropertyName  *StringNode
 	PropertyValue *StringNode
+	Literal       lexer.TokenType // TokenStringConstant or TokenIntegerConstant, how the value is written
+	Span          Span
 }
 
 type EdgeNode struct {
 	EdgeName   *StringNode
 	LowerBound *IntNode
 	UpperBound *IntNode
+	Direction  Direction
+	Span       Span
 }
 
 type EdgeDefNode struct {
 	EdgeName *StringNode // Store the name of the edge
 	EdgeType EdgeType    // Type of the edge (enum?)
+	Span     Span
 }
 
 type RelationInitNode struct {
 	LeftVertex  *StringNode
 	Relation    *StringNode
 	RightVertex *StringNode
+	Properties  []ASTNode
+	Span        Span
 }
@@ -0,0 +1,149 @@
+package nodes
+
+func (node *StringNode) GetSpan() Span {
+	return node.Span
+}
+
+func (node *IntNode) GetSpan() Span {
+	return node.Span
+}
+
+func (node *BoolNode) GetSpan() Span {
+	return node.Span
+}
+
+func (node *FloatNode) GetSpan() Span {
+	return node.Span
+}
+
+func (node *ListNode) GetSpan() Span {
+	return node.Span
+}
+
+func (node *ParameterNode) GetSpan() Span {
+	return node.Span
+}
+
+func (node *PathNode) GetSpan() Span {
+	return node.Span
+}
+
+func (node *BinaryNode) GetSpan() Span {
+	return node.Span
+}
+
+func (node *ProgramStatementNode) GetSpan() Span {
+	return node.Span
+}
+
+func (node *QueryStatementNode) GetSpan() Span {
+	return node.Span
+}
+
+func (node *ExplainStatementNode) GetSpan() Span {
+	return node.Span
+}
+
+func (node *ProfileStatementNode) GetSpan() Span {
+	return node.Span
+}
+
+func (node *CallStatementNode) GetSpan() Span {
+	return node.Span
+}
+
+func (node *SumFuncNode) GetSpan() Span {
+	return node.Span
+}
+
+func (node *CountFuncNode) GetSpan() Span {
+	return node.Span
+}
+
+func (node *AvgFuncNode) GetSpan() Span {
+	return node.Span
+}
+
+func (node *ShortestPathFuncNode) GetSpan() Span {
+	return node.Span
+}
+
+func (node *AllShortestPathsFuncNode) GetSpan() Span {
+	return node.Span
+}
+
+func (node *LengthFuncNode) GetSpan() Span {
+	return node.Span
+}
+
+func (node *NodesFuncNode) GetSpan() Span {
+	return node.Span
+}
+
+func (node *EdgesFuncNode) GetSpan() Span {
+	return node.Span
+}
+
+func (node *MaxFuncNode) GetSpan() Span {
+	return node.Span
+}
+
+func (node *MinFuncNode) GetSpan() Span {
+	return node.Span
+}
+
+func (node *StartsWithFuncNode) GetSpan() Span {
+	return node.Span
+}
+
+func (node *VertexTermNode) GetSpan() Span {
+	return node.Span
+}
+
+func (node *VertexNode) GetSpan() Span {
+	return node.Span
+}
+
+func (node *RelationNode) GetSpan() Span {
+	return node.Span
+}
+
+func (node *ConditionNode) GetSpan() Span {
+	return node.Span
+}
+
+func (node *SchemaDefNode) GetSpan() Span {
+	return node.Span
+}
+
+func (node *VertexInitNode) GetSpan() Span {
+	return node.Span
+}
+
+func (node *PropertyNode) GetSpan() Span {
+	return node.Span
+}
+
+func (node *PropertyDefNode) GetSpan() Span {
+	return node.Span
+}
+
+func (node *PropertyInitNode) GetSpan() Span {
+	return node.Span
+}
+
+func (node *EdgeNode) GetSpan() Span {
+	return node.Span
+}
+
+func (node *EdgeDefNode) GetSpan() Span {
+	return node.Span
+}
+
+func (node *RelationInitNode) GetSpan() Span {
+	return node.Span
+}
+
+func (node *NullNode) GetSpan() Span {
+	return node.Span
+}
@@ -1,5 +1,7 @@
 package nodes
 
+import "fmt"
+
 type EdgeType int
 
 const (
@@ -25,6 +27,49 @@ func GetAllEdgeTypes() []EdgeType {
 	}
 }
 
+// Position is a place in the source, rows and columns count from 0
+type Position struct {
+	Row int `json:"row"`
+	Col int `json:"col"`
+}
+
+// Span is the part of the source a node was parsed from, End is exclusive
+type Span struct {
+	Start Position `json:"start"`
+	End   Position `json:"end"`
+}
+
+// IsZero reports whether the span is unknown, eg: for a node that was not parsed
+func (s Span) IsZero() bool {
+	return s == Span{}
+}
+
+func (s Span) String() string {
+	return fmt.Sprintf("%d:%d", s.Start.Row+1, s.Start.Col+1)
+}
+
+// Direction is the direction an edge is walked in a query
+type Direction int
+
+const (
+	Outgoing Direction = iota + 1
+	Incoming
+	AnyDirection
+)
+
+func (d Direction) String() string {
+	switch d {
+	case Outgoing:
+		return "->"
+	case Incoming:
+		return "<-"
+	case AnyDirection:
+		return "<->"
+	default:
+		return ""
+	}
+}
+
 type FuncType int
 
 const (
@@ -32,6 +77,13 @@ const (
 	MaxFunc
 	MinFunc
 	StartWithFunc
+	CountFunc
+	AvgFunc
+	ShortestPathFunc
+	AllShortestPathsFunc
+	LengthFunc
+	NodesFunc
+	EdgesFunc
 )
 
 func (e FuncType) String() string {
@@ -44,6 +96,20 @@ func (e FuncType) String() string {
 		return "Min"
 	case StartWithFunc:
 		return "StartsWith"
+	case CountFunc:
+		return "Count"
+	case AvgFunc:
+		return "Avg"
+	case ShortestPathFunc:
+		return "ShortestPath"
+	case AllShortestPathsFunc:
+		return "AllShortestPaths"
+	case LengthFunc:
+		return "Length"
+	case NodesFunc:
+		return "Nodes"
+	case EdgesFunc:
+		return "Edges"
 	default:
 		return ""
 	}
@@ -55,5 +121,12 @@ func GetAllFuncTypes() []FuncType {
 		MaxFunc,
 		MinFunc,
 		StartWithFunc,
+		CountFunc,
+		AvgFunc,
+		ShortestPathFunc,
+		AllShortestPathsFunc,
+		LengthFunc,
+		NodesFunc,
+		EdgesFunc,
 	}
 }
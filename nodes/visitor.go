@@ -3,6 +3,12 @@ package nodes
 type Visitor interface {
 	VisitProgramNode(node *ProgramStatementNode)
 	VisitIntNode(node *IntNode)
+	VisitFloatNode(node *FloatNode)
+	VisitBoolNode(node *BoolNode)
+	VisitNullNode(node *NullNode)
+	VisitListNode(node *ListNode)
+	VisitParameterNode(node *ParameterNode)
+	VisitPathNode(node *PathNode)
 	VisitStringNode(node *StringNode)
 	VisitSchemaDefNode(node *SchemaDefNode)
 	VisitEdgeDefNode(node *EdgeDefNode)
@@ -17,5 +23,17 @@ type Visitor interface {
 	VisitVertexTermNode(node *VertexTermNode)
 	VisitRelationNode(node *RelationNode)
 	VisitQueryStatement(node *QueryStatementNode)
+	VisitExplainStatement(node *ExplainStatementNode)
+	VisitProfileStatement(node *ProfileStatementNode)
+	VisitCallStatement(node *CallStatementNode)
 	VisitSumFunc(node *SumFuncNode)
+	VisitCountFunc(node *CountFuncNode)
+	VisitAvgFunc(node *AvgFuncNode)
+	VisitMaxFunc(node *MaxFuncNode)
+	VisitMinFunc(node *MinFuncNode)
+	VisitShortestPathFunc(node *ShortestPathFuncNode)
+	VisitAllShortestPathsFunc(node *AllShortestPathsFuncNode)
+	VisitLengthFunc(node *LengthFuncNode)
+	VisitNodesFunc(node *NodesFuncNode)
+	VisitEdgesFunc(node *EdgesFuncNode)
 }
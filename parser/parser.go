@@ -1,6 +1,7 @@
 package parser
 
 import (
+	"io"
 	"math"
 	"strconv"
 
@@ -19,11 +20,15 @@ type ParserInterface interface {
 	// schemaDef() nodes.ASTNode
 	// vertexInit() nodes.ASTNode
 	Parse() (nodes.ASTNode, error)
+	Next() (nodes.ASTNode, error)
+	GetSourceContextAt(row int) string
 }
 
 type Parser struct {
 	Lexer        lexer.LexerInterface
 	CurrentToken *lexer.Token
+
+	previous *lexer.Token // the last token eaten, where the node being parsed ends
 }
 
 func NewParser(lexer lexer.LexerInterface) *Parser {
@@ -33,6 +38,36 @@ func NewParser(lexer lexer.LexerInterface) *Parser {
 	}
 }
 
+// identifier returns the name held by an identifier token with its position
+func identifier(token *lexer.Token) *nodes.StringNode {
+	return &nodes.StringNode{Value: token.Value, Span: tokenSpan(token)}
+}
+
+// tokenSpan returns the part of the source a token was read from, the
+// quotes of a string included. It ends where the lexer left the token, a
+// string can span lines.
+func tokenSpan(token *lexer.Token) nodes.Span {
+	span := nodes.Span{
+		Start: nodes.Position{Row: token.Row, Col: token.Col},
+		End:   nodes.Position{Row: token.EndRow, Col: token.EndCol},
+	}
+	if token.Type == lexer.TokenStringConstant {
+		span.Start.Col--
+	}
+	return span
+}
+
+// span returns the part of the source from the start of the first token of
+// a node to the end of the last token eaten
+func (p *Parser) span(first *lexer.Token) nodes.Span {
+	return nodes.Span{Start: tokenSpan(first).Start, End: tokenSpan(p.previous).End}
+}
+
+// join returns the part of the source from the start of left to the end of right
+func join(left, right nodes.ASTNode) nodes.Span {
+	return nodes.Span{Start: left.GetSpan().Start, End: right.GetSpan().End}
+}
+
 func (p *Parser) eat(tokenType lexer.TokenType) error {
 	if tokenType != p.CurrentToken.Type {
 		return &errors.UnexpectedToken{
@@ -41,12 +76,14 @@ func (p *Parser) eat(tokenType lexer.TokenType) error {
 			ExpectedToken: tokenType,
 		}
 	}
+	p.previous = p.CurrentToken
 	p.CurrentToken = p.Lexer.GetNextToken()
 	return nil
 }
 
 // edge_def: EDGE ID EDGE_TYPE
 func (p *Parser) edgeDef() (nodes.ASTNode, error) {
+	start := p.CurrentToken
 	// EDGE
 	if err := p.eat(lexer.TokenEdge); err != nil {
 		return new(nodes.EdgeDefNode), err
@@ -78,18 +115,20 @@ func (p *Parser) edgeDef() (nodes.ASTNode, error) {
 	// } // AS
 
 	return &nodes.EdgeDefNode{
-		EdgeName: &nodes.StringNode{Value: rightToken.Value},
+		EdgeName: identifier(rightToken),
 		EdgeType: edgeType,
+		Span:     p.span(start),
 	}, nil
 }
 
 // schema_def: SCHEMA ID LCB property_def RCB
 func (p *Parser) schemaDef() (nodes.ASTNode, error) {
+	start := p.CurrentToken
 	if err := p.eat(lexer.TokenSchema); err != nil {
 		return new(nodes.SchemaDefNode), err
 	} // SCHEMA
 
-	schemaName := p.CurrentToken.Value
+	schemaName := p.CurrentToken
 	if err := p.eat(lexer.TokenIdentifier); err != nil {
 		return new(nodes.SchemaDefNode), err
 	} // ID
@@ -106,8 +145,9 @@ func (p *Parser) schemaDef() (nodes.ASTNode, error) {
 	} // RCB
 
 	return &nodes.SchemaDefNode{
-		SchemaName: &nodes.StringNode{Value: schemaName},
+		SchemaName: identifier(schemaName),
 		Properties: properties,
+		Span:       p.span(start),
 	}, nil
 }
 
@@ -116,7 +156,7 @@ func (p *Parser) propertyDef() ([]nodes.ASTNode, error) {
 	var properties []nodes.ASTNode
 
 	for p.CurrentToken.Type == lexer.TokenIdentifier {
-		propertyName := p.CurrentToken.Value
+		propertyName := p.CurrentToken
 		if err := p.eat(lexer.TokenIdentifier); err != nil {
 			return nil, err
 		}
@@ -140,8 +180,9 @@ func (p *Parser) propertyDef() ([]nodes.ASTNode, error) {
 		}
 
 		properties = append(properties, &nodes.PropertyDefNode{
-			PropertyName: &nodes.StringNode{Value: propertyName},
+			PropertyName: identifier(propertyName),
 			PropertyType: *property,
+			Span:         p.span(propertyName),
 		})
 
 	}
@@ -153,10 +194,11 @@ func (p *Parser) propertyInit() ([]nodes.ASTNode, error) {
 	var arguments []nodes.ASTNode
 
 	for p.CurrentToken.Type == lexer.TokenDot {
+		start := p.CurrentToken
 		if err := p.eat(lexer.TokenDot); err != nil {
 			return nil, err
 		}
-		propertyName := p.CurrentToken.Value
+		propertyName := p.CurrentToken
 		if err := p.eat(lexer.TokenIdentifier); err != nil {
 			return nil, err
 		}
@@ -183,8 +225,10 @@ func (p *Parser) propertyInit() ([]nodes.ASTNode, error) {
 		}
 
 		arguments = append(arguments, &nodes.PropertyInitNode{
-			PropertyName:  &nodes.StringNode{Value: propertyName},
-			PropertyValue: &nodes.StringNode{Value: literalToken.Value},
+			PropertyName:  identifier(propertyName),
+			PropertyValue: &nodes.StringNode{Value: literalToken.Value, Span: tokenSpan(literalToken)},
+			Literal:       literalToken.Type,
+			Span:          p.span(start),
 		})
 	}
 	return arguments, nil
@@ -192,16 +236,17 @@ func (p *Parser) propertyInit() ([]nodes.ASTNode, error) {
 
 // vertex_init: VERTEX ID ID LCB property_init RCB
 func (p *Parser) vertexInit() (nodes.ASTNode, error) {
+	start := p.CurrentToken
 	if err := p.eat(lexer.TokenVertex); err != nil {
 		return nil, err
 	}
 
-	vertexName := p.CurrentToken.Value
+	vertexName := p.CurrentToken
 	if err := p.eat(lexer.TokenIdentifier); err != nil {
 		return nil, err
 	}
 
-	schemaName := p.CurrentToken.Value
+	schemaName := p.CurrentToken
 	if err := p.eat(lexer.TokenIdentifier); err != nil {
 		return nil, err
 	}
@@ -224,14 +269,16 @@ func (p *Parser) vertexInit() (nodes.ASTNode, error) {
 	}
 
 	return &nodes.VertexInitNode{
-		SchemaName: &nodes.StringNode{Value: schemaName},
-		VertexName: &nodes.StringNode{Value: vertexName},
+		SchemaName: identifier(schemaName),
+		VertexName: identifier(vertexName),
 		Properties: properties,
+		Span:       p.span(start),
 	}, nil
 }
 
-// relation_init: RELATION ID LCB ID ID RCB
+// relation_init: RELATION ID LCB ID ID property_init RCB
 func (p *Parser) relationInit() (nodes.ASTNode, error) {
+	start := p.CurrentToken
 	if err := p.eat(lexer.TokenRelation); err != nil {
 		return new(nodes.RelationInitNode), err
 	}
@@ -255,56 +302,100 @@ func (p *Parser) relationInit() (nodes.ASTNode, error) {
 		return new(nodes.RelationInitNode), err
 	}
 
+	properties, err := p.propertyInit()
+	if err != nil {
+		return nil, err
+	}
+
 	if err := p.eat(lexer.TokenRCB); err != nil {
 		return nil, err
 	}
 
 	return &nodes.RelationInitNode{
-		LeftVertex:  &nodes.StringNode{Value: leftVertex.Value},
-		Relation:    &nodes.StringNode{Value: relation.Value},
-		RightVertex: &nodes.StringNode{Value: rightVertex.Value},
+		LeftVertex:  identifier(leftVertex),
+		Relation:    identifier(relation),
+		RightVertex: identifier(rightVertex),
+		Properties:  properties,
+		Span:        p.span(start),
 	}, nil
 }
 
+// programStatement parses every statement of the program, a statement that
+// fails to parse is skipped so that the errors of the ones after it are
+// found too
 func (p *Parser) programStatement() (nodes.ASTNode, error) {
 	var programNodes []nodes.ASTNode
-	for p.CurrentToken.Type != lexer.TokenEOF {
-		switch p.CurrentToken.Type {
-		case lexer.TokenSchema:
-			schemaNode, err := p.schemaDef()
-			if err != nil {
-				return nil, err
-			}
-			programNodes = append(programNodes, schemaNode)
-		case lexer.TokenEdge:
-			edgeNode, err := p.edgeDef()
-			if err != nil {
-				return nil, err
-			}
-			programNodes = append(programNodes, edgeNode)
-		case lexer.TokenVertex:
-			vertexNode, err := p.vertexInit()
-			if err != nil {
-				return nil, err
-			}
-			programNodes = append(programNodes, vertexNode)
-		case lexer.TokenRelation:
-			relationNode, err := p.relationInit()
-			if err != nil {
-				return nil, err
-			}
-			programNodes = append(programNodes, relationNode)
-		case lexer.TokenQuery:
-			queryNode, err := p.queryStatement()
-			if err != nil {
-				return nil, err
-			}
-			programNodes = append(programNodes, queryNode)
-		default:
-			return nil, &errors.UnknownStatement{SourceContext: p.Lexer.GetSourceContext(), ActualToken: p.CurrentToken}
+	var syntaxErrors errors.SyntaxErrors
+	for {
+		node, err := p.Next()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			syntaxErrors = append(syntaxErrors, err)
+			continue
+		}
+		programNodes = append(programNodes, node)
+	}
+	if syntaxErrors != nil {
+		return nil, syntaxErrors
+	}
+	program := &nodes.ProgramStatementNode{Children: programNodes}
+	if len(programNodes) > 0 {
+		program.Span = join(programNodes[0], programNodes[len(programNodes)-1])
+	}
+	return program, nil
+}
+
+// synchronise skips the rest of a statement that failed to parse, up to the
+// next statement keyword or past the closing brace of the block that holds
+// the error when a statement follows it. start is the first token of the
+// statement, it is skipped when the statement failed on it.
+func (p *Parser) synchronise(start *lexer.Token) {
+	if p.CurrentToken == start {
+		p.CurrentToken = p.Lexer.GetNextToken()
+	}
+	for p.CurrentToken.Type != lexer.TokenEOF && !isStatement(p.CurrentToken.Type) {
+		closing := p.CurrentToken.Type == lexer.TokenRCB
+		p.CurrentToken = p.Lexer.GetNextToken()
+		if closing && isStatement(p.CurrentToken.Type) {
+			return
+		}
+	}
+}
+
+func isStatement(tokenType lexer.TokenType) bool {
+	for _, t := range lexer.GetAllStatementTypes() {
+		if t == tokenType {
+			return true
 		}
 	}
-	return &nodes.ProgramStatementNode{Children: programNodes}, nil
+	return false
+}
+
+// statement:
+//
+//	schema_def | edge_def | vertex_init | relation_init | query | call | explain | profile
+func (p *Parser) statement() (nodes.ASTNode, error) {
+	switch p.CurrentToken.Type {
+	case lexer.TokenSchema:
+		return p.schemaDef()
+	case lexer.TokenEdge:
+		return p.edgeDef()
+	case lexer.TokenVertex:
+		return p.vertexInit()
+	case lexer.TokenRelation:
+		return p.relationInit()
+	case lexer.TokenQuery:
+		return p.queryStatement()
+	case lexer.TokenCall:
+		return p.callStatement()
+	case lexer.TokenExplain:
+		return p.explainStatement()
+	case lexer.TokenProfile:
+		return p.profileStatement()
+	default:
+		return nil, &errors.UnknownStatement{SourceContext: p.Lexer.GetSourceContext(), ActualToken: p.CurrentToken}
+	}
 }
 
 // clause:
@@ -332,7 +423,7 @@ func (p *Parser) clause() (nodes.ASTNode, error) {
 			return nil, err
 		}
 
-		operationLeft = &nodes.BinaryNode{LeftChild: operationLeft, Operator: *operator, RightChild: operationRight}
+		operationLeft = &nodes.BinaryNode{LeftChild: operationLeft, Operator: *operator, RightChild: operationRight, Span: join(operationLeft, operationRight)}
 	}
 
 	return operationLeft, nil
@@ -357,7 +448,7 @@ func (p *Parser) expression() (nodes.ASTNode, error) {
 			return nil, err
 		}
 
-		leftClause = &nodes.BinaryNode{LeftChild: leftClause, Operator: *operator, RightChild: rightClause}
+		leftClause = &nodes.BinaryNode{LeftChild: leftClause, Operator: *operator, RightChild: rightClause, Span: join(leftClause, rightClause)}
 	}
 
 	return leftClause, nil
@@ -366,7 +457,11 @@ func (p *Parser) expression() (nodes.ASTNode, error) {
 // query_statement:
 //
 //	Query expression
+//	(Group By expression (COMMA expression)*)?
+//	(Having expression)?
+//	(Return expression (COMMA expression)*)?
 func (p *Parser) queryStatement() (nodes.ASTNode, error) {
+	start := p.CurrentToken
 	if err := p.eat(lexer.TokenQuery); err != nil {
 		return nil, err
 	}
@@ -381,35 +476,208 @@ func (p *Parser) queryStatement() (nodes.ASTNode, error) {
 	// 	return nil, err
 	// }
 
-	return &nodes.QueryStatementNode{Expression: expression}, nil
+	query := &nodes.QueryStatementNode{Expression: expression}
+
+	if p.CurrentToken.Type == lexer.TokenGroup {
+		if err := p.eat(lexer.TokenGroup); err != nil {
+			return nil, err
+		}
+		if err := p.eat(lexer.TokenBy); err != nil {
+			return nil, err
+		}
+		query.GroupBy, err = p.expressionList()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if p.CurrentToken.Type == lexer.TokenHaving {
+		if err := p.eat(lexer.TokenHaving); err != nil {
+			return nil, err
+		}
+		query.Having, err = p.expression()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if p.CurrentToken.Type == lexer.TokenReturn {
+		if err := p.eat(lexer.TokenReturn); err != nil {
+			return nil, err
+		}
+		query.Return, err = p.expressionList()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	query.Span = p.span(start)
+	return query, nil
+}
+
+// explain_statement:
+//
+//	Explain query_statement
+func (p *Parser) explainStatement() (nodes.ASTNode, error) {
+	start := p.CurrentToken
+	if err := p.eat(lexer.TokenExplain); err != nil {
+		return nil, err
+	}
+
+	query, err := p.queryStatement()
+	if err != nil {
+		return nil, err
+	}
+	return &nodes.ExplainStatementNode{Query: query.(*nodes.QueryStatementNode), Span: p.span(start)}, nil
+}
+
+// profile_statement:
+//
+//	Profile query_statement
+func (p *Parser) profileStatement() (nodes.ASTNode, error) {
+	start := p.CurrentToken
+	if err := p.eat(lexer.TokenProfile); err != nil {
+		return nil, err
+	}
+
+	query, err := p.queryStatement()
+	if err != nil {
+		return nil, err
+	}
+	return &nodes.ProfileStatementNode{Query: query.(*nodes.QueryStatementNode), Span: p.span(start)}, nil
+}
+
+// call_statement:
+//
+//	Call ID LRB (call_arg (COMMA call_arg)*)? RRB (Write DOT ID)?
+//
+// call_arg: ID | INT | Unit
+func (p *Parser) callStatement() (nodes.ASTNode, error) {
+	start := p.CurrentToken
+	if err := p.eat(lexer.TokenCall); err != nil {
+		return nil, err
+	}
+
+	procedure := p.CurrentToken
+	if err := p.eat(lexer.TokenIdentifier); err != nil {
+		return nil, err
+	}
+	if err := p.eat(lexer.TokenLRB); err != nil {
+		return nil, err
+	}
+
+	var args []nodes.ASTNode
+	for p.CurrentToken.Type != lexer.TokenRRB {
+		if len(args) > 0 {
+			if err := p.eat(lexer.TokenComma); err != nil {
+				return nil, err
+			}
+		}
+
+		switch p.CurrentToken.Type {
+		case lexer.TokenIdentifier:
+			args = append(args, identifier(p.CurrentToken))
+			if err := p.eat(lexer.TokenIdentifier); err != nil {
+				return nil, err
+			}
+		case lexer.TokenIntegerConstant:
+			number, err := p.integer()
+			if err != nil {
+				return nil, err
+			}
+			args = append(args, number)
+		case lexer.TokenLRB:
+			// Unit matches any vertex or edge
+			unit := p.CurrentToken
+			if err := p.eat(lexer.TokenLRB); err != nil {
+				return nil, err
+			}
+			if err := p.eat(lexer.TokenRRB); err != nil {
+				return nil, err
+			}
+			args = append(args, &nodes.StringNode{Value: "", Span: p.span(unit)})
+		default:
+			return nil, &errors.UnexpectedToken{
+				SourceContext:   p.Lexer.GetSourceContext(),
+				ActualToken:     p.CurrentToken,
+				SuggestedTokens: []lexer.TokenType{lexer.TokenIdentifier, lexer.TokenIntegerConstant, lexer.TokenLRB, lexer.TokenRRB},
+			}
+		}
+	}
+	if err := p.eat(lexer.TokenRRB); err != nil {
+		return nil, err
+	}
+
+	call := &nodes.CallStatementNode{Procedure: identifier(procedure), Args: args}
+	if p.CurrentToken.Type == lexer.TokenWrite {
+		if err := p.eat(lexer.TokenWrite); err != nil {
+			return nil, err
+		}
+		if err := p.eat(lexer.TokenDot); err != nil {
+			return nil, err
+		}
+		call.Write = identifier(p.CurrentToken)
+		if err := p.eat(lexer.TokenIdentifier); err != nil {
+			return nil, err
+		}
+	}
+
+	call.Span = p.span(start)
+	return call, nil
+}
+
+// expression_list:
+//
+//	expression (COMMA expression)*
+func (p *Parser) expressionList() ([]nodes.ASTNode, error) {
+	expression, err := p.expression()
+	if err != nil {
+		return nil, err
+	}
+	expressions := []nodes.ASTNode{expression}
+
+	for p.CurrentToken.Type == lexer.TokenComma {
+		if err := p.eat(lexer.TokenComma); err != nil {
+			return nil, err
+		}
+		expression, err := p.expression()
+		if err != nil {
+			return nil, err
+		}
+		expressions = append(expressions, expression)
+	}
+
+	return expressions, nil
 }
 
 // factor:
 //
 //	(INT | STRING | BOOL)
+//	| PARAMETER
 //	| property_id
 //	| vertex_term
 //	| relation_term vertex_term
-//	| builtin_func LCB clause RCB
+//	| builtin_func LRB expression_list RRB
 //	| LRB expression RRB
 
 func (p *Parser) integer() (nodes.ASTNode, error) {
-	number, err := strconv.Atoi(p.CurrentToken.Value)
+	token := p.CurrentToken
+	number, err := strconv.Atoi(token.Value)
 	if err != nil {
 		return nil, err
 	}
 	if err := p.eat(lexer.TokenIntegerConstant); err != nil {
 		return nil, err
 	}
-	return &nodes.IntNode{Value: number}, nil
+	return &nodes.IntNode{Value: number, Span: tokenSpan(token)}, nil
 }
 
 func (p *Parser) string() (nodes.ASTNode, error) {
-	str := p.CurrentToken.Value
+	token := p.CurrentToken
 	if err := p.eat(lexer.TokenStringConstant); err != nil {
 		return nil, err
 	}
-	return &nodes.StringNode{Value: str}, nil
+	return &nodes.StringNode{Value: token.Value, Span: tokenSpan(token)}, nil
 }
 
 func (p *Parser) builtinFunc() (nodes.ASTNode, error) {
@@ -427,21 +695,92 @@ func (p *Parser) builtinFunc() (nodes.ASTNode, error) {
 		return nil, err
 	}
 
+	// LRB (expression_list | path_args) RRB
+	if err := p.eat(lexer.TokenLRB); err != nil {
+		return nil, err
+	}
+	var args []nodes.ASTNode
+	var err error
+	if function.Value == nodes.ShortestPathFunc.String() || function.Value == nodes.AllShortestPathsFunc.String() {
+		args, err = p.pathArgs()
+	} else {
+		args, err = p.expressionList()
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := p.eat(lexer.TokenRRB); err != nil {
+		return nil, err
+	}
+
+	span := p.span(function)
 	switch function.Value {
-	case "Sum":
-		expression, err := p.expression()
-		if err != nil {
+	case nodes.SumFunc.String():
+		return &nodes.SumFuncNode{FunctionName: nodes.SumFunc, Args: args, Span: span}, nil
+	case nodes.CountFunc.String():
+		return &nodes.CountFuncNode{FunctionName: nodes.CountFunc, Args: args, Span: span}, nil
+	case nodes.AvgFunc.String():
+		return &nodes.AvgFuncNode{FunctionName: nodes.AvgFunc, Args: args, Span: span}, nil
+	case nodes.ShortestPathFunc.String():
+		return &nodes.ShortestPathFuncNode{FunctionName: nodes.ShortestPathFunc, Args: args, Span: span}, nil
+	case nodes.AllShortestPathsFunc.String():
+		return &nodes.AllShortestPathsFuncNode{FunctionName: nodes.AllShortestPathsFunc, Args: args, Span: span}, nil
+	case nodes.LengthFunc.String():
+		return &nodes.LengthFuncNode{FunctionName: nodes.LengthFunc, Args: args, Span: span}, nil
+	case nodes.NodesFunc.String():
+		return &nodes.NodesFuncNode{FunctionName: nodes.NodesFunc, Args: args, Span: span}, nil
+	case nodes.EdgesFunc.String():
+		return &nodes.EdgesFuncNode{FunctionName: nodes.EdgesFunc, Args: args, Span: span}, nil
+	case nodes.MaxFunc.String():
+		return &nodes.MaxFuncNode{FunctionName: nodes.MaxFunc, Args: args, Span: span}, nil
+	case nodes.MinFunc.String():
+		return &nodes.MinFuncNode{FunctionName: nodes.MinFunc, Args: args, Span: span}, nil
+	default:
+		return nil, &errors.UnknownBuiltinFunc{SourceContext: p.Lexer.GetSourceContext(), ActualToken: function}
+	}
+}
+
+// path_args:
+//
+//	expression COMMA expression COMMA relation_term (COMMA property_id)?
+func (p *Parser) pathArgs() ([]nodes.ASTNode, error) {
+	from, err := p.expression()
+	if err != nil {
+		return nil, err
+	}
+	if err := p.eat(lexer.TokenComma); err != nil {
+		return nil, err
+	}
+	to, err := p.expression()
+	if err != nil {
+		return nil, err
+	}
+	if err := p.eat(lexer.TokenComma); err != nil {
+		return nil, err
+	}
+	edge, err := p.relationTerm()
+	if err != nil {
+		return nil, err
+	}
+	args := []nodes.ASTNode{from, to, edge}
+
+	// weight of the edges (eg: .distance)
+	if p.CurrentToken.Type == lexer.TokenComma {
+		if err := p.eat(lexer.TokenComma); err != nil {
 			return nil, err
 		}
-		args := []nodes.ASTNode{expression}
-
-		for p.CurrentToken.Type == lexer.TokenComma {
-			args = append(args, &nodes.StringNode{Value: p.CurrentToken.Value})
+		dot := p.CurrentToken
+		if err := p.eat(lexer.TokenDot); err != nil {
+			return nil, err
 		}
-		return &nodes.SumFuncNode{FunctionName: nodes.SumFunc, Args: args}, nil
-	default:
-		return nil, &errors.UnknownBuiltinFunc{SourceContext: p.Lexer.GetSourceContext(), ActualToken: p.CurrentToken}
+		property := p.CurrentToken
+		if err := p.eat(lexer.TokenIdentifier); err != nil {
+			return nil, err
+		}
+		args = append(args, &nodes.PropertyNode{PropertyName: identifier(property), Span: p.span(dot)})
 	}
+
+	return args, nil
 }
 
 func (p *Parser) factor() (nodes.ASTNode, error) {
@@ -481,7 +820,7 @@ func (p *Parser) factor() (nodes.ASTNode, error) {
 			return nil, err
 		}
 
-		return &nodes.RelationNode{Edge: relationTerm, Vertex: vertexTerm}, nil
+		return &nodes.RelationNode{Edge: relationTerm, Vertex: vertexTerm, Span: join(relationTerm, vertexTerm)}, nil
 	}
 
 	// INT
@@ -494,41 +833,56 @@ func (p *Parser) factor() (nodes.ASTNode, error) {
 		return p.string()
 	}
 
+	// PARAMETER (eg: $name)
+	if p.CurrentToken.Type == lexer.TokenParameter {
+		token := p.CurrentToken
+		if err := p.eat(lexer.TokenParameter); err != nil {
+			return nil, err
+		}
+		return &nodes.ParameterNode{Name: identifier(token), Span: tokenSpan(token)}, nil
+	}
+
 	// property_id (eg: .name)
 	if p.CurrentToken.Type == lexer.TokenDot {
+		dot := p.CurrentToken
 		if err := p.eat(lexer.TokenDot); err != nil {
 			return nil, err
 		}
-		property := p.CurrentToken.Value
+		property := p.CurrentToken
 		if err := p.eat(lexer.TokenIdentifier); err != nil {
 			return nil, err
 		}
 
 		return &nodes.PropertyNode{
-			PropertyName: &nodes.StringNode{Value: property}, Alias: nil,
+			PropertyName: identifier(property), Alias: nil, Span: p.span(dot),
 		}, nil
 	}
 
 	// property_id (eg: A.name)
 	// vertex_term  (eg: Person)
 	if p.CurrentToken.Type == lexer.TokenIdentifier {
-		id := p.CurrentToken.Value
+		id := p.CurrentToken
 		if err := p.eat(lexer.TokenIdentifier); err != nil {
 			return nil, err
 		}
 
+		if p.CurrentToken.Type == lexer.TokenLRB {
+			// a call of a function that is not built in (eg: Cuont(P))
+			return nil, &errors.UnknownBuiltinFunc{SourceContext: p.Lexer.GetSourceContext(), ActualToken: id}
+		}
+
 		if p.CurrentToken.Type == lexer.TokenDot {
 			// property_id (eg: A.name)
 			if err := p.eat(lexer.TokenDot); err != nil {
 				return nil, err
 			}
-			property := p.CurrentToken.Value
+			property := p.CurrentToken
 			if err := p.eat(lexer.TokenIdentifier); err != nil {
 				return nil, err
 			}
 
 			return &nodes.PropertyNode{
-				PropertyName: &nodes.StringNode{Value: property}, Alias: &nodes.StringNode{Value: id},
+				PropertyName: identifier(property), Alias: identifier(id), Span: p.span(id),
 			}, nil
 		}
 
@@ -541,15 +895,17 @@ func (p *Parser) factor() (nodes.ASTNode, error) {
 				return nil, err
 			}
 
-			alias.Value = p.CurrentToken.Value
+			token := p.CurrentToken
 			if err := p.eat(lexer.TokenIdentifier); err != nil {
 				return nil, err
 			}
+			alias = identifier(token)
 		}
 
 		vertex := &nodes.VertexNode{
-			VertexName: &nodes.StringNode{Value: id},
+			VertexName: identifier(id),
 			Alias:      alias,
+			Span:       p.span(id),
 		}
 
 		// (LCB expression RCB)?
@@ -564,9 +920,9 @@ func (p *Parser) factor() (nodes.ASTNode, error) {
 			if err := p.eat(lexer.TokenRCB); err != nil {
 				return nil, err
 			}
-			return &nodes.VertexTermNode{Vertex: vertex, Conditions: condition}, nil
+			return &nodes.VertexTermNode{Vertex: vertex, Conditions: condition, Span: p.span(id)}, nil
 		}
-		return &nodes.VertexTermNode{Vertex: vertex, Conditions: nil}, nil
+		return &nodes.VertexTermNode{Vertex: vertex, Conditions: nil, Span: p.span(id)}, nil
 	}
 
 	// vertex_term  (Unit)
@@ -583,6 +939,7 @@ func (p *Parser) factor() (nodes.ASTNode, error) {
 			lexer.TokenEdge,
 			lexer.TokenIntegerConstant,
 			lexer.TokenStringConstant,
+			lexer.TokenParameter,
 			lexer.TokenDot,
 			lexer.TokenIdentifier,
 		},
@@ -609,7 +966,7 @@ func (p *Parser) term() (nodes.ASTNode, error) {
 			return nil, err
 		}
 
-		factorLeft = &nodes.BinaryNode{LeftChild: factorLeft, Operator: *operator, RightChild: factorRight}
+		factorLeft = &nodes.BinaryNode{LeftChild: factorLeft, Operator: *operator, RightChild: factorRight, Span: join(factorLeft, factorRight)}
 	}
 
 	return factorLeft, nil
@@ -635,20 +992,23 @@ func (p *Parser) operation() (nodes.ASTNode, error) {
 			return nil, err
 		}
 
-		termLeft = &nodes.BinaryNode{LeftChild: termLeft, Operator: *operator, RightChild: termRight}
+		termLeft = &nodes.BinaryNode{LeftChild: termLeft, Operator: *operator, RightChild: termRight, Span: join(termLeft, termRight)}
 	}
 
 	return termLeft, nil
 }
 
-// relation_term: (LSB (integer | (integer? DOT DOT integer?))? RSB) relation
+// relation_term: (LSB (integer | (integer? DOT DOT integer?))? RSB) direction? relation
+// direction: MINUS GT | LT MINUS | LT MINUS GT
 // relation: ID | Unit
 // Unit: LRB RRB
 func (p *Parser) relationTerm() (nodes.ASTNode, error) {
+	start := p.CurrentToken
 	relation := new(nodes.EdgeNode)
 	// default Upper/Lower bounds
 	relation.LowerBound = &nodes.IntNode{Value: 0}
 	relation.UpperBound = &nodes.IntNode{Value: math.MaxInt}
+	relation.Direction = nodes.Outgoing
 
 	// relation_term: (LSB (integer | (integer? DOT DOT integer?))? RSB) relation
 	if err := p.eat(lexer.TokenLSB); err != nil {
@@ -670,8 +1030,8 @@ func (p *Parser) relationTerm() (nodes.ASTNode, error) {
 			if err != nil {
 				return nil, err
 			}
-			relation.LowerBound = &nodes.IntNode{Value: number}
-			relation.UpperBound = &nodes.IntNode{Value: number}
+			relation.LowerBound = &nodes.IntNode{Value: number, Span: tokenSpan(p.CurrentToken)}
+			relation.UpperBound = &nodes.IntNode{Value: number, Span: tokenSpan(p.CurrentToken)}
 
 			if err := p.eat(lexer.TokenIntegerConstant); err != nil {
 				return nil, err
@@ -694,7 +1054,7 @@ func (p *Parser) relationTerm() (nodes.ASTNode, error) {
 				}
 
 				// Lower bound is already set
-				relation.UpperBound = &nodes.IntNode{Value: number}
+				relation.UpperBound = &nodes.IntNode{Value: number, Span: tokenSpan(p.CurrentToken)}
 
 				if err := p.eat(lexer.TokenIntegerConstant); err != nil {
 					return nil, err
@@ -707,9 +1067,33 @@ func (p *Parser) relationTerm() (nodes.ASTNode, error) {
 		}
 	}
 
+	// direction: -> (default), <- or <->
+	if p.CurrentToken.Type == lexer.TokenMinus {
+		if err := p.eat(lexer.TokenMinus); err != nil {
+			return nil, err
+		}
+		if err := p.eat(lexer.TokenGreaterThan); err != nil {
+			return nil, err
+		}
+	} else if p.CurrentToken.Type == lexer.TokenLessThan {
+		if err := p.eat(lexer.TokenLessThan); err != nil {
+			return nil, err
+		}
+		if err := p.eat(lexer.TokenMinus); err != nil {
+			return nil, err
+		}
+		relation.Direction = nodes.Incoming
+		if p.CurrentToken.Type == lexer.TokenGreaterThan {
+			if err := p.eat(lexer.TokenGreaterThan); err != nil {
+				return nil, err
+			}
+			relation.Direction = nodes.AnyDirection
+		}
+	}
+
 	if p.CurrentToken.Type == lexer.TokenIdentifier {
 		// EdgeName
-		relation.EdgeName = &nodes.StringNode{Value: p.CurrentToken.Value}
+		relation.EdgeName = identifier(p.CurrentToken)
 		if err := p.eat(lexer.TokenIdentifier); err != nil {
 			return nil, err
 		}
@@ -723,7 +1107,6 @@ func (p *Parser) relationTerm() (nodes.ASTNode, error) {
 		}
 		relation.EdgeName = nil
 	} else {
-    panic(p.CurrentToken)
 		return nil, &errors.UnexpectedToken{
 			SourceContext:   p.Lexer.GetSourceContext(),
 			ActualToken:     p.CurrentToken,
@@ -731,15 +1114,17 @@ func (p *Parser) relationTerm() (nodes.ASTNode, error) {
 		}
 	}
 
+	relation.Span = p.span(start)
 	return relation, nil
 }
 
 // vertex_term: vertex (LCB expression RCB)?
 func (p *Parser) vertexTerm() (nodes.ASTNode, error) {
 	// vertex: ID (as ID)? | unit
+	start := p.CurrentToken
 	vertex := new(nodes.VertexNode)
 	if p.CurrentToken.Type == lexer.TokenIdentifier {
-		vertex.VertexName = &nodes.StringNode{Value: p.CurrentToken.Value}
+		vertex.VertexName = identifier(p.CurrentToken)
 		if err := p.eat(lexer.TokenIdentifier); err != nil {
 			return nil, err
 		}
@@ -748,7 +1133,7 @@ func (p *Parser) vertexTerm() (nodes.ASTNode, error) {
 			if err := p.eat(lexer.TokenAlias); err != nil {
 				return nil, err
 			}
-			vertex.Alias = &nodes.StringNode{Value: p.CurrentToken.Value}
+			vertex.Alias = identifier(p.CurrentToken)
 			if err := p.eat(lexer.TokenIdentifier); err != nil {
 				return nil, err
 			}
@@ -768,6 +1153,7 @@ func (p *Parser) vertexTerm() (nodes.ASTNode, error) {
 			SuggestedTokens: []lexer.TokenType{lexer.TokenIdentifier, lexer.TokenLRB},
 		}
 	}
+	vertex.Span = p.span(start)
 
 	// (LCB expression RCB)?
 	if p.CurrentToken.Type == lexer.TokenLCB {
@@ -781,9 +1167,33 @@ func (p *Parser) vertexTerm() (nodes.ASTNode, error) {
 		if err := p.eat(lexer.TokenRCB); err != nil {
 			return nil, err
 		}
-		return &nodes.VertexTermNode{Vertex: vertex, Conditions: condition}, nil
+		return &nodes.VertexTermNode{Vertex: vertex, Conditions: condition, Span: p.span(start)}, nil
+	}
+	return &nodes.VertexTermNode{Vertex: vertex, Conditions: nil, Span: p.span(start)}, nil
+}
+
+// Next parses the next statement of the program and returns it, io.EOF once
+// every statement was returned. The statements are read from the lexer as
+// they are parsed, so a program of any size is parsed in constant memory.
+// A statement that fails to parse is skipped, the following call parses
+// the statement after it.
+func (p *Parser) Next() (nodes.ASTNode, error) {
+	if p.CurrentToken.Type == lexer.TokenEOF {
+		return nil, io.EOF
 	}
-	return &nodes.VertexTermNode{Vertex: vertex, Conditions: nil}, nil
+	start := p.CurrentToken
+	node, err := p.statement()
+	if err != nil {
+		p.synchronise(start)
+		return nil, err
+	}
+	return node, nil
+}
+
+// GetSourceContextAt returns the source around a row of the program, see
+// lexer.Lexer.GetSourceContextAt
+func (p *Parser) GetSourceContextAt(row int) string {
+	return p.Lexer.GetSourceContextAt(row)
 }
 
 func (p *Parser) Parse() (nodes.ASTNode, error) {
@@ -1,7 +1,10 @@
 package parser
 
 import (
+	"fmt"
+	"io"
 	"math"
+	"strings"
 	"testing"
 
 	"github.com/Jintumoni/vortex/errors"
@@ -496,6 +499,48 @@ func TestFactorRelationWithAnyEdgeAndAnyVertex(t *testing.T) {
 	assert.Nil(t, vertex.Vertex.VertexName)
 }
 
+func TestFactorRelationWithDirection(t *testing.T) {
+	// []->LivesIn India, []<-LivesIn India and []<->LivesIn India
+	for _, tc := range []struct {
+		arrow     []*lexer.Token
+		direction nodes.Direction
+	}{
+		{[]*lexer.Token{{Type: lexer.TokenMinus, Value: "-"}, {Type: lexer.TokenGreaterThan, Value: ">"}}, nodes.Outgoing},
+		{[]*lexer.Token{{Type: lexer.TokenLessThan, Value: "<"}, {Type: lexer.TokenMinus, Value: "-"}}, nodes.Incoming},
+		{[]*lexer.Token{{Type: lexer.TokenLessThan, Value: "<"}, {Type: lexer.TokenMinus, Value: "-"}, {Type: lexer.TokenGreaterThan, Value: ">"}}, nodes.AnyDirection},
+	} {
+		mockLexer := new(mocks.MockLexer)
+		mockLexer.On("GetNextToken").Return(&lexer.Token{Type: lexer.TokenLSB, Value: "["}).Once()
+		mockLexer.On("GetNextToken").Return(&lexer.Token{Type: lexer.TokenRSB, Value: "]"}).Once()
+		for _, token := range tc.arrow {
+			mockLexer.On("GetNextToken").Return(token).Once()
+		}
+		mockLexer.On("GetNextToken").Return(&lexer.Token{Type: lexer.TokenIdentifier, Value: "LivesIn"}).Once()
+		mockLexer.On("GetNextToken").Return(&lexer.Token{Type: lexer.TokenIdentifier, Value: "India"}).Once()
+		mockLexer.On("GetNextToken").Return(&lexer.Token{Type: lexer.TokenEOF, Value: "EOF"}).Once()
+		p := NewParser(mockLexer)
+		relationNode, err := p.factor()
+		assert.NoError(t, err)
+		edge := relationNode.(*nodes.RelationNode).Edge.(*nodes.EdgeNode)
+
+		assert.Equal(t, "LivesIn", edge.EdgeName.Value)
+		assert.Equal(t, tc.direction, edge.Direction)
+	}
+}
+
+func TestFactorParameter(t *testing.T) {
+	mockLexer := new(mocks.MockLexer)
+	mockLexer.On("GetNextToken").Return(&lexer.Token{Type: lexer.TokenParameter, Value: "name", Row: 2, Col: 4, Span: 5}).Once()
+	mockLexer.On("GetNextToken").Return(&lexer.Token{Type: lexer.TokenEOF, Value: "EOF"}).Once()
+	p := NewParser(mockLexer)
+	parameterNode, err := p.factor()
+	assert.NoError(t, err)
+
+	name := parameterNode.(*nodes.ParameterNode).Name
+	assert.Equal(t, "name", name.Value)
+	assert.Equal(t, nodes.Position{Row: 2, Col: 4}, name.Span.Start)
+}
+
 func TestFactorVertexTermWithCondition(t *testing.T) {
 	mockLexer := new(mocks.MockLexer)
 
@@ -636,7 +681,7 @@ func TestUnexpectedTokenErrorMessage(t *testing.T) {
 	mockLexer.On("GetNextToken").Return(&lexer.Token{Type: lexer.TokenEOF, Value: "EOF"}).Once()
 
   // There are tab characters in the string
-  expectedErrMsg := `Error: Unexpected "{" found
+  expectedErrMsg := `Error[V0001]: Unexpected "{" found
 1	|	Query  Person as  {
 		                  ^--Did you mean "<identifier>"?
 `
@@ -651,3 +696,339 @@ func TestUnexpectedTokenErrorMessage(t *testing.T) {
   assert.ErrorAs(t, err, &expectedErr)
   assert.Equal(t, expectedErrMsg, err.Error())
 }
+
+func TestUnknownBuiltinFunctionSuggestsTheClosest(t *testing.T) {
+	_, err := NewParser(lexer.NewLexer(strings.NewReader(`Query Person { Cuont(Person) > 1 }`))).Parse()
+
+	var unknown *errors.UnknownBuiltinFunc
+	assert.ErrorAs(t, err, &unknown)
+	assert.Equal(t, "Cuont", unknown.ActualToken.Value)
+	assert.Equal(t, []string{"Count"}, unknown.Suggestions())
+	assert.Equal(t, `Error[V0004]: Unknown "Cuont" found
+1	|	Query Person { Cuont(Person) > 1 }
+		               ^^^^^--Did you mean "Count"?
+`, unknown.Error())
+}
+
+func TestReservedWordAsNameErrorMessage(t *testing.T) {
+	_, err := NewParser(lexer.NewLexer(strings.NewReader(`Schema Group { name string }`))).Parse()
+	assert.Equal(t, `Error[V0001]: Unexpected "Group" found
+1	|	Schema Group { name string }
+		       ^^^^^--Did you mean "<identifier>"?
+		= note: "Group" is a reserved word and cannot be used as a name
+`, err.Error())
+
+	// the words of the statements added to the language are reserved too
+	for _, src := range []string{
+		`Vertex Write Person { .name = "Ann" }`,
+		`Query Person as Length { Length.age > 1 }`,
+		`Query Person { .Having = 1 }`,
+		`Edge Profile OneWay`,
+	} {
+		_, err := NewParser(lexer.NewLexer(strings.NewReader(src))).Parse()
+		assert.ErrorContains(t, err, "is a reserved word and cannot be used as a name", src)
+	}
+}
+
+func TestFactorWithBuiltinFunctionArguments(t *testing.T) {
+	mockLexer := new(mocks.MockLexer)
+
+	// Sum([]FriendsWith Person, .salary)
+	mockLexer.On("GetNextToken").Return(&lexer.Token{Type: lexer.TokenFunction, Value: "Sum"}).Once()
+	mockLexer.On("GetNextToken").Return(&lexer.Token{Type: lexer.TokenLRB, Value: "("}).Once()
+	mockLexer.On("GetNextToken").Return(&lexer.Token{Type: lexer.TokenLSB, Value: "["}).Once()
+	mockLexer.On("GetNextToken").Return(&lexer.Token{Type: lexer.TokenRSB, Value: "]"}).Once()
+	mockLexer.On("GetNextToken").Return(&lexer.Token{Type: lexer.TokenIdentifier, Value: "FriendsWith"}).Once()
+	mockLexer.On("GetNextToken").Return(&lexer.Token{Type: lexer.TokenIdentifier, Value: "Person"}).Once()
+	mockLexer.On("GetNextToken").Return(&lexer.Token{Type: lexer.TokenComma, Value: ","}).Once()
+	mockLexer.On("GetNextToken").Return(&lexer.Token{Type: lexer.TokenDot, Value: "."}).Once()
+	mockLexer.On("GetNextToken").Return(&lexer.Token{Type: lexer.TokenIdentifier, Value: "salary"}).Once()
+	mockLexer.On("GetNextToken").Return(&lexer.Token{Type: lexer.TokenRRB, Value: ")"}).Once()
+	mockLexer.On("GetNextToken").Return(&lexer.Token{Type: lexer.TokenEOF, Value: "EOF"}).Once()
+
+	p := NewParser(mockLexer)
+	functionNode, err := p.factor()
+	assert.NoError(t, err)
+
+	function := functionNode.(*nodes.SumFuncNode)
+	assert.Len(t, function.Args, 2)
+	assert.IsType(t, &nodes.RelationNode{}, function.Args[0])
+	assert.Equal(t, "salary", function.Args[1].(*nodes.PropertyNode).PropertyName.Value)
+}
+
+func TestQueryStatementWithGroupBy(t *testing.T) {
+	mockLexer := new(mocks.MockLexer)
+
+	// Query Person as P Group By P.age Having Count(P) > 1 Return P.age, Count(P)
+	mockLexer.On("GetNextToken").Return(&lexer.Token{Type: lexer.TokenQuery, Value: "Query"}).Once()
+	mockLexer.On("GetNextToken").Return(&lexer.Token{Type: lexer.TokenIdentifier, Value: "Person"}).Once()
+	mockLexer.On("GetNextToken").Return(&lexer.Token{Type: lexer.TokenAlias, Value: "as"}).Once()
+	mockLexer.On("GetNextToken").Return(&lexer.Token{Type: lexer.TokenIdentifier, Value: "P"}).Once()
+	mockLexer.On("GetNextToken").Return(&lexer.Token{Type: lexer.TokenGroup, Value: "Group"}).Once()
+	mockLexer.On("GetNextToken").Return(&lexer.Token{Type: lexer.TokenBy, Value: "By"}).Once()
+	mockLexer.On("GetNextToken").Return(&lexer.Token{Type: lexer.TokenIdentifier, Value: "P"}).Once()
+	mockLexer.On("GetNextToken").Return(&lexer.Token{Type: lexer.TokenDot, Value: "."}).Once()
+	mockLexer.On("GetNextToken").Return(&lexer.Token{Type: lexer.TokenIdentifier, Value: "age"}).Once()
+	mockLexer.On("GetNextToken").Return(&lexer.Token{Type: lexer.TokenHaving, Value: "Having"}).Once()
+	mockLexer.On("GetNextToken").Return(&lexer.Token{Type: lexer.TokenFunction, Value: "Count"}).Once()
+	mockLexer.On("GetNextToken").Return(&lexer.Token{Type: lexer.TokenLRB, Value: "("}).Once()
+	mockLexer.On("GetNextToken").Return(&lexer.Token{Type: lexer.TokenIdentifier, Value: "P"}).Once()
+	mockLexer.On("GetNextToken").Return(&lexer.Token{Type: lexer.TokenRRB, Value: ")"}).Once()
+	mockLexer.On("GetNextToken").Return(&lexer.Token{Type: lexer.TokenGreaterThan, Value: ">"}).Once()
+	mockLexer.On("GetNextToken").Return(&lexer.Token{Type: lexer.TokenIntegerConstant, Value: "1"}).Once()
+	mockLexer.On("GetNextToken").Return(&lexer.Token{Type: lexer.TokenReturn, Value: "Return"}).Once()
+	mockLexer.On("GetNextToken").Return(&lexer.Token{Type: lexer.TokenIdentifier, Value: "P"}).Once()
+	mockLexer.On("GetNextToken").Return(&lexer.Token{Type: lexer.TokenDot, Value: "."}).Once()
+	mockLexer.On("GetNextToken").Return(&lexer.Token{Type: lexer.TokenIdentifier, Value: "age"}).Once()
+	mockLexer.On("GetNextToken").Return(&lexer.Token{Type: lexer.TokenComma, Value: ","}).Once()
+	mockLexer.On("GetNextToken").Return(&lexer.Token{Type: lexer.TokenFunction, Value: "Count"}).Once()
+	mockLexer.On("GetNextToken").Return(&lexer.Token{Type: lexer.TokenLRB, Value: "("}).Once()
+	mockLexer.On("GetNextToken").Return(&lexer.Token{Type: lexer.TokenIdentifier, Value: "P"}).Once()
+	mockLexer.On("GetNextToken").Return(&lexer.Token{Type: lexer.TokenRRB, Value: ")"}).Once()
+	mockLexer.On("GetNextToken").Return(&lexer.Token{Type: lexer.TokenEOF, Value: "EOF"}).Once()
+
+	p := NewParser(mockLexer)
+	queryNode, err := p.queryStatement()
+	assert.NoError(t, err)
+
+	query := queryNode.(*nodes.QueryStatementNode)
+	assert.Equal(t, "Person", query.Expression.(*nodes.VertexTermNode).Vertex.VertexName.Value)
+
+	assert.Len(t, query.GroupBy, 1)
+	assert.Equal(t, "age", query.GroupBy[0].(*nodes.PropertyNode).PropertyName.Value)
+
+	having := query.Having.(*nodes.BinaryNode)
+	assert.Equal(t, lexer.TokenGreaterThan, having.Operator.Type)
+	assert.Equal(t, nodes.CountFunc, having.LeftChild.(*nodes.CountFuncNode).FunctionName)
+
+	assert.Len(t, query.Return, 2)
+	assert.Equal(t, "P", query.Return[0].(*nodes.PropertyNode).Alias.Value)
+	assert.Equal(t, nodes.CountFunc, query.Return[1].(*nodes.CountFuncNode).FunctionName)
+}
+
+func TestRelationInitWithProperties(t *testing.T) {
+	mockLexer := new(mocks.MockLexer)
+
+	// Relation Road { London Paris .distance = 340 }
+	mockLexer.On("GetNextToken").Return(&lexer.Token{Type: lexer.TokenRelation, Value: "Relation"}).Once()
+	mockLexer.On("GetNextToken").Return(&lexer.Token{Type: lexer.TokenIdentifier, Value: "Road"}).Once()
+	mockLexer.On("GetNextToken").Return(&lexer.Token{Type: lexer.TokenLCB, Value: "{"}).Once()
+	mockLexer.On("GetNextToken").Return(&lexer.Token{Type: lexer.TokenIdentifier, Value: "London"}).Once()
+	mockLexer.On("GetNextToken").Return(&lexer.Token{Type: lexer.TokenIdentifier, Value: "Paris"}).Once()
+	mockLexer.On("GetNextToken").Return(&lexer.Token{Type: lexer.TokenDot, Value: "."}).Once()
+	mockLexer.On("GetNextToken").Return(&lexer.Token{Type: lexer.TokenIdentifier, Value: "distance"}).Once()
+	mockLexer.On("GetNextToken").Return(&lexer.Token{Type: lexer.TokenEqual, Value: "="}).Once()
+	mockLexer.On("GetNextToken").Return(&lexer.Token{Type: lexer.TokenIntegerConstant, Value: "340"}).Once()
+	mockLexer.On("GetNextToken").Return(&lexer.Token{Type: lexer.TokenRCB, Value: "}"}).Once()
+	mockLexer.On("GetNextToken").Return(&lexer.Token{Type: lexer.TokenEOF, Value: "EOF"}).Once()
+
+	p := NewParser(mockLexer)
+	relationNode, err := p.relationInit()
+	assert.NoError(t, err)
+
+	node := relationNode.(*nodes.RelationInitNode)
+	assert.Len(t, node.Properties, 1)
+	property := node.Properties[0].(*nodes.PropertyInitNode)
+	assert.Equal(t, "distance", property.PropertyName.Value)
+	assert.Equal(t, "340", property.PropertyValue.Value)
+}
+
+func TestFactorWithShortestPath(t *testing.T) {
+	mockLexer := new(mocks.MockLexer)
+
+	// ShortestPath(A, B, [..]Road, .distance)
+	mockLexer.On("GetNextToken").Return(&lexer.Token{Type: lexer.TokenFunction, Value: "ShortestPath"}).Once()
+	mockLexer.On("GetNextToken").Return(&lexer.Token{Type: lexer.TokenLRB, Value: "("}).Once()
+	mockLexer.On("GetNextToken").Return(&lexer.Token{Type: lexer.TokenIdentifier, Value: "A"}).Once()
+	mockLexer.On("GetNextToken").Return(&lexer.Token{Type: lexer.TokenComma, Value: ","}).Once()
+	mockLexer.On("GetNextToken").Return(&lexer.Token{Type: lexer.TokenIdentifier, Value: "B"}).Once()
+	mockLexer.On("GetNextToken").Return(&lexer.Token{Type: lexer.TokenComma, Value: ","}).Once()
+	mockLexer.On("GetNextToken").Return(&lexer.Token{Type: lexer.TokenLSB, Value: "["}).Once()
+	mockLexer.On("GetNextToken").Return(&lexer.Token{Type: lexer.TokenRange, Value: ".."}).Once()
+	mockLexer.On("GetNextToken").Return(&lexer.Token{Type: lexer.TokenRSB, Value: "]"}).Once()
+	mockLexer.On("GetNextToken").Return(&lexer.Token{Type: lexer.TokenIdentifier, Value: "Road"}).Once()
+	mockLexer.On("GetNextToken").Return(&lexer.Token{Type: lexer.TokenComma, Value: ","}).Once()
+	mockLexer.On("GetNextToken").Return(&lexer.Token{Type: lexer.TokenDot, Value: "."}).Once()
+	mockLexer.On("GetNextToken").Return(&lexer.Token{Type: lexer.TokenIdentifier, Value: "distance"}).Once()
+	mockLexer.On("GetNextToken").Return(&lexer.Token{Type: lexer.TokenRRB, Value: ")"}).Once()
+	mockLexer.On("GetNextToken").Return(&lexer.Token{Type: lexer.TokenEOF, Value: "EOF"}).Once()
+
+	p := NewParser(mockLexer)
+	functionNode, err := p.factor()
+	assert.NoError(t, err)
+
+	function := functionNode.(*nodes.ShortestPathFuncNode)
+	assert.Len(t, function.Args, 4)
+	assert.Equal(t, "A", function.Args[0].(*nodes.VertexTermNode).Vertex.VertexName.Value)
+	assert.Equal(t, "B", function.Args[1].(*nodes.VertexTermNode).Vertex.VertexName.Value)
+
+	edge := function.Args[2].(*nodes.EdgeNode)
+	assert.Equal(t, "Road", edge.EdgeName.Value)
+	assert.Equal(t, 0, edge.LowerBound.Value)
+	assert.Equal(t, math.MaxInt, edge.UpperBound.Value)
+	assert.Equal(t, "distance", function.Args[3].(*nodes.PropertyNode).PropertyName.Value)
+}
+
+func TestCallStatement(t *testing.T) {
+	mockLexer := new(mocks.MockLexer)
+
+	// Call PageRank(Person, (), 10) Write .rank
+	mockLexer.On("GetNextToken").Return(&lexer.Token{Type: lexer.TokenCall, Value: "Call"}).Once()
+	mockLexer.On("GetNextToken").Return(&lexer.Token{Type: lexer.TokenIdentifier, Value: "PageRank"}).Once()
+	mockLexer.On("GetNextToken").Return(&lexer.Token{Type: lexer.TokenLRB, Value: "("}).Once()
+	mockLexer.On("GetNextToken").Return(&lexer.Token{Type: lexer.TokenIdentifier, Value: "Person"}).Once()
+	mockLexer.On("GetNextToken").Return(&lexer.Token{Type: lexer.TokenComma, Value: ","}).Once()
+	mockLexer.On("GetNextToken").Return(&lexer.Token{Type: lexer.TokenLRB, Value: "("}).Once()
+	mockLexer.On("GetNextToken").Return(&lexer.Token{Type: lexer.TokenRRB, Value: ")"}).Once()
+	mockLexer.On("GetNextToken").Return(&lexer.Token{Type: lexer.TokenComma, Value: ","}).Once()
+	mockLexer.On("GetNextToken").Return(&lexer.Token{Type: lexer.TokenIntegerConstant, Value: "10"}).Once()
+	mockLexer.On("GetNextToken").Return(&lexer.Token{Type: lexer.TokenRRB, Value: ")"}).Once()
+	mockLexer.On("GetNextToken").Return(&lexer.Token{Type: lexer.TokenWrite, Value: "Write"}).Once()
+	mockLexer.On("GetNextToken").Return(&lexer.Token{Type: lexer.TokenDot, Value: "."}).Once()
+	mockLexer.On("GetNextToken").Return(&lexer.Token{Type: lexer.TokenIdentifier, Value: "rank"}).Once()
+	mockLexer.On("GetNextToken").Return(&lexer.Token{Type: lexer.TokenEOF, Value: "EOF"}).Once()
+
+	p := NewParser(mockLexer)
+	callNode, err := p.callStatement()
+	assert.NoError(t, err)
+
+	call := callNode.(*nodes.CallStatementNode)
+	assert.Equal(t, "PageRank", call.Procedure.Value)
+	assert.Len(t, call.Args, 3)
+	assert.Equal(t, "Person", call.Args[0].(*nodes.StringNode).Value)
+	assert.Equal(t, "", call.Args[1].(*nodes.StringNode).Value)
+	assert.Equal(t, 10, call.Args[2].(*nodes.IntNode).Value)
+	assert.Equal(t, "rank", call.Write.Value)
+}
+
+func TestExplainStatement(t *testing.T) {
+	mockLexer := new(mocks.MockLexer)
+
+	// Explain Query Person
+	mockLexer.On("GetNextToken").Return(&lexer.Token{Type: lexer.TokenExplain, Value: "Explain"}).Once()
+	mockLexer.On("GetNextToken").Return(&lexer.Token{Type: lexer.TokenQuery, Value: "Query"}).Once()
+	mockLexer.On("GetNextToken").Return(&lexer.Token{Type: lexer.TokenIdentifier, Value: "Person"}).Once()
+	mockLexer.On("GetNextToken").Return(&lexer.Token{Type: lexer.TokenEOF, Value: "EOF"}).Once()
+
+	p := NewParser(mockLexer)
+	explainNode, err := p.explainStatement()
+	assert.NoError(t, err)
+
+	query := explainNode.(*nodes.ExplainStatementNode).Query
+	assert.Equal(t, "Person", query.Expression.(*nodes.VertexTermNode).Vertex.VertexName.Value)
+}
+
+func TestProfileStatement(t *testing.T) {
+	mockLexer := new(mocks.MockLexer)
+
+	// Profile Query Person
+	mockLexer.On("GetNextToken").Return(&lexer.Token{Type: lexer.TokenProfile, Value: "Profile"}).Once()
+	mockLexer.On("GetNextToken").Return(&lexer.Token{Type: lexer.TokenQuery, Value: "Query"}).Once()
+	mockLexer.On("GetNextToken").Return(&lexer.Token{Type: lexer.TokenIdentifier, Value: "Person"}).Once()
+	mockLexer.On("GetNextToken").Return(&lexer.Token{Type: lexer.TokenEOF, Value: "EOF"}).Once()
+
+	p := NewParser(mockLexer)
+	profileNode, err := p.profileStatement()
+	assert.NoError(t, err)
+
+	query := profileNode.(*nodes.ProfileStatementNode).Query
+	assert.Equal(t, "Person", query.Expression.(*nodes.VertexTermNode).Vertex.VertexName.Value)
+}
+
+func TestNext(t *testing.T) {
+	mockLexer := new(mocks.MockLexer)
+
+	// Edge LivesIn OneWay Query Person as {
+	mockLexer.On("GetNextToken").Return(&lexer.Token{Type: lexer.TokenEdge, Value: "Edge"}).Once()
+	mockLexer.On("GetNextToken").Return(&lexer.Token{Type: lexer.TokenIdentifier, Value: "LivesIn"}).Once()
+	mockLexer.On("GetNextToken").Return(&lexer.Token{Type: lexer.TokenIdentifier, Value: "OneWay"}).Once()
+	mockLexer.On("GetNextToken").Return(&lexer.Token{Type: lexer.TokenQuery, Value: "Query"}).Once()
+	mockLexer.On("GetNextToken").Return(&lexer.Token{Type: lexer.TokenIdentifier, Value: "Person"}).Once()
+	mockLexer.On("GetNextToken").Return(&lexer.Token{Type: lexer.TokenAlias, Value: "as"}).Once()
+	mockLexer.On("GetNextToken").Return(&lexer.Token{Type: lexer.TokenLCB, Value: "{"}).Once()
+	mockLexer.On("GetNextToken").Return(&lexer.Token{Type: lexer.TokenEOF, Value: "EOF"}).Once()
+	mockLexer.On("GetSourceContext").Return("1\t|\tEdge LivesIn OneWay Query Person as {\n").Once()
+
+	p := NewParser(mockLexer)
+	node, err := p.Next()
+	assert.NoError(t, err)
+	assert.Equal(t, "LivesIn", node.(*nodes.EdgeDefNode).EdgeName.Value)
+
+	// the statement after it is only read by the next call
+	mockLexer.AssertNumberOfCalls(t, "GetNextToken", 4)
+	_, err = p.Next()
+	var expectedErr *errors.UnexpectedToken
+	assert.ErrorAs(t, err, &expectedErr)
+
+	mockLexer = new(mocks.MockLexer)
+	mockLexer.On("GetNextToken").Return(&lexer.Token{Type: lexer.TokenEOF, Value: "EOF"}).Once()
+	node, err = NewParser(mockLexer).Next()
+	assert.Nil(t, node)
+	assert.Equal(t, io.EOF, err)
+}
+
+func TestParseRecoversFromSyntaxErrors(t *testing.T) {
+	src := `Schema Person { name strin }
+Vertex Ann Person { .name = "Ann" }
+Query Person { [] 5 City } Return .name
+Edge LivesIn OneWay
+Vertex { }`
+
+	node, err := NewParser(lexer.NewLexer(strings.NewReader(src))).Parse()
+	assert.Nil(t, node)
+	var syntaxErrors errors.SyntaxErrors
+	assert.ErrorAs(t, err, &syntaxErrors)
+	assert.Len(t, syntaxErrors, 3)
+	for i, row := range []int{0, 2, 4} {
+		var unexpected *errors.UnexpectedToken
+		assert.ErrorAs(t, syntaxErrors[i], &unexpected)
+		assert.Equal(t, row, unexpected.ActualToken.Row)
+		assert.Contains(t, unexpected.SourceContext, fmt.Sprintf("%d\t|\t%s\n", row+1, strings.Split(src, "\n")[row]))
+	}
+
+	// the statements between the errors are parsed
+	p := NewParser(lexer.NewLexer(strings.NewReader(src)))
+	var statements []nodes.ASTNode
+	for {
+		node, err := p.Next()
+		if err == io.EOF {
+			break
+		} else if err == nil {
+			statements = append(statements, node)
+		}
+	}
+	assert.Len(t, statements, 2)
+	assert.IsType(t, &nodes.VertexInitNode{}, statements[0])
+	assert.IsType(t, &nodes.EdgeDefNode{}, statements[1])
+}
+
+func TestSpans(t *testing.T) {
+	src := `Vertex Ann Person {
+  .age = 30
+}
+Query Person as P { .age > 20 } Return P.age`
+	span := func(startRow, startCol, endRow, endCol int) nodes.Span {
+		return nodes.Span{Start: nodes.Position{Row: startRow, Col: startCol}, End: nodes.Position{Row: endRow, Col: endCol}}
+	}
+
+	root, err := NewParser(lexer.NewLexer(strings.NewReader(src))).Parse()
+	assert.NoError(t, err)
+	program := root.(*nodes.ProgramStatementNode)
+	assert.Equal(t, span(0, 0, 3, 44), program.GetSpan())
+
+	vertex := program.Children[0].(*nodes.VertexInitNode)
+	assert.Equal(t, span(0, 0, 2, 1), vertex.GetSpan())
+	assert.Equal(t, span(0, 7, 0, 10), vertex.VertexName.Span)
+	assert.Equal(t, span(0, 11, 0, 17), vertex.SchemaName.Span)
+	init := vertex.Properties[0].(*nodes.PropertyInitNode)
+	assert.Equal(t, span(1, 2, 1, 11), init.GetSpan())
+	assert.Equal(t, span(1, 9, 1, 11), init.PropertyValue.Span)
+
+	query := program.Children[1].(*nodes.QueryStatementNode)
+	assert.Equal(t, span(3, 0, 3, 44), query.GetSpan())
+	term := query.Expression.(*nodes.VertexTermNode)
+	assert.Equal(t, span(3, 6, 3, 31), term.GetSpan())
+	assert.Equal(t, span(3, 6, 3, 17), term.Vertex.GetSpan())
+	assert.Equal(t, span(3, 20, 3, 29), term.Conditions.GetSpan())
+	assert.Equal(t, span(3, 39, 3, 44), query.Return[0].GetSpan())
+}
@@ -0,0 +1,169 @@
+package vortex
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/Jintumoni/vortex/nodes"
+	"github.com/Jintumoni/vortex/visitors"
+)
+
+var (
+	NoRow          = errors.New("Scan called without a row, call Next first")
+	ColumnMismatch = errors.New("Number of destinations does not match the columns")
+	ScanTypeError  = errors.New("Value can not be scanned into the destination")
+)
+
+// Rows is the result of a query, read one row at a time with Next and Scan
+//
+//	for rows.Next() {
+//		var name string
+//		var age int
+//		if err := rows.Scan(&name, &age); err != nil {
+//			return err
+//		}
+//	}
+type Rows struct {
+	columns []string
+	rows    [][]nodes.ASTNode
+	current []nodes.ASTNode
+}
+
+// Columns returns the names of the columns, as written in the Return clause
+func (r *Rows) Columns() []string {
+	return r.columns
+}
+
+// Next moves to the next row, it returns false when there are no more rows
+func (r *Rows) Next() bool {
+	if len(r.rows) == 0 {
+		r.current = nil
+		return false
+	}
+	r.current, r.rows = r.rows[0], r.rows[1:]
+	return true
+}
+
+// Close releases the rows that were not read
+func (r *Rows) Close() error {
+	r.rows, r.current = nil, nil
+	return nil
+}
+
+// Values returns the Go values of the current row, see Value
+func (r *Rows) Values() ([]any, error) {
+	if r.current == nil {
+		return nil, NoRow
+	}
+	values := make([]any, 0, len(r.current))
+	for _, value := range r.current {
+		values = append(values, Value(value))
+	}
+	return values, nil
+}
+
+// Scan copies the columns of the current row into the values pointed at by dest.
+//
+// An int is scanned into *int or *int64, a float into *float64, a string into
+// *string, a bool into *bool. A vertex, an edge or a path is scanned into
+// *string as it is printed, or into *nodes.VertexInitNode for a vertex. Any
+// value is scanned into *any as returned by Value, or into *nodes.ASTNode as is.
+func (r *Rows) Scan(dest ...any) error {
+	if r.current == nil {
+		return NoRow
+	}
+	if len(dest) != len(r.current) {
+		return fmt.Errorf("%w: %d destinations for %d columns", ColumnMismatch, len(dest), len(r.current))
+	}
+
+	for i, value := range r.current {
+		if err := scan(value, dest[i]); err != nil {
+			return fmt.Errorf("column %s: %w", r.columns[i], err)
+		}
+	}
+	return nil
+}
+
+func scan(value nodes.ASTNode, dest any) error {
+	switch d := dest.(type) {
+	case *any:
+		*d = Value(value)
+		return nil
+	case *nodes.ASTNode:
+		*d = value
+		return nil
+	}
+
+	switch v := value.(type) {
+	case *nodes.IntNode:
+		switch d := dest.(type) {
+		case *int:
+			*d = v.Value
+			return nil
+		case *int64:
+			*d = int64(v.Value)
+			return nil
+		case *float64:
+			*d = float64(v.Value)
+			return nil
+		}
+	case *nodes.FloatNode:
+		if d, ok := dest.(*float64); ok {
+			*d = v.Value
+			return nil
+		}
+	case *nodes.BoolNode:
+		if d, ok := dest.(*bool); ok {
+			*d = v.Value
+			return nil
+		}
+	case *nodes.VertexInitNode:
+		if d, ok := dest.(**nodes.VertexInitNode); ok {
+			*d = v
+			return nil
+		}
+	}
+
+	switch value.(type) {
+	case *nodes.StringNode, *nodes.VertexInitNode, *nodes.EdgeDefNode, *nodes.PathNode:
+		if d, ok := dest.(*string); ok {
+			*d = visitors.FormatValue(value)
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: %s into %T", ScanTypeError, visitors.FormatValue(value), dest)
+}
+
+// Value returns the Go value of a value produced by a query: int, float64,
+// string, bool, nil for null and []any for a list. A vertex is its name, an
+// edge its name and a path is the list of the names of its vertices.
+func Value(value nodes.ASTNode) any {
+	switch v := value.(type) {
+	case *nodes.IntNode:
+		return v.Value
+	case *nodes.FloatNode:
+		return v.Value
+	case *nodes.StringNode:
+		return v.Value
+	case *nodes.BoolNode:
+		return v.Value
+	case *nodes.VertexInitNode:
+		return v.VertexName.Value
+	case *nodes.EdgeDefNode:
+		return v.EdgeName.Value
+	case *nodes.ListNode:
+		values := make([]any, 0, len(v.Values))
+		for _, value := range v.Values {
+			values = append(values, Value(value))
+		}
+		return values
+	case *nodes.PathNode:
+		vertices := make([]any, 0, len(v.Vertices))
+		for _, vertex := range v.Vertices {
+			vertices = append(vertices, vertex.VertexName.Value)
+		}
+		return vertices
+	default:
+		return nil
+	}
+}
@@ -0,0 +1,139 @@
+package server
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	vortexerrors "github.com/Jintumoni/vortex/errors"
+	"github.com/Jintumoni/vortex/lexer"
+	"github.com/Jintumoni/vortex/nodes"
+	"github.com/Jintumoni/vortex/visitors"
+)
+
+// Error describes why a request failed. The position is 1-based and set for
+// syntax errors and for errors caused by a name written in the program.
+type Error struct {
+	Message  string   `json:"message"`
+	Code     string   `json:"code,omitempty"`     // code of the diagnostic, eg: V0001
+	Token    string   `json:"token,omitempty"`    // token the parser did not expect
+	Row      int      `json:"row,omitempty"`      // row of the token or the name
+	Col      int      `json:"col,omitempty"`      // column of the token or the name
+	Expected []string `json:"expected,omitempty"` // tokens the parser expected instead, or names close to the unknown one
+}
+
+func (e *Error) Error() string {
+	if e.Row == 0 {
+		return e.Message
+	}
+	return fmt.Sprintf("%d:%d: %s", e.Row, e.Col, e.Message)
+}
+
+// Errors are the errors of a program with several syntax errors, in the
+// order of the source
+type Errors []*Error
+
+func (e Errors) Error() string {
+	messages := make([]string, 0, len(e))
+	for _, err := range e {
+		messages = append(messages, err.Error())
+	}
+	return strings.Join(messages, "\n")
+}
+
+func (e Errors) Unwrap() []error {
+	errs := make([]error, 0, len(e))
+	for _, err := range e {
+		errs = append(errs, err)
+	}
+	return errs
+}
+
+// NewError describes an error of a program for a response, the first one
+// of the syntax errors of a program
+func NewError(err error) *Error {
+	return NewErrors(err)[0]
+}
+
+// NewErrors describes an error of a program for a response, each syntax
+// error of a program on its own
+func NewErrors(err error) Errors {
+	var syntaxErrors vortexerrors.SyntaxErrors
+	if !errors.As(err, &syntaxErrors) || len(syntaxErrors) == 0 {
+		syntaxErrors = vortexerrors.SyntaxErrors{err}
+	}
+
+	errs := make(Errors, 0, len(syntaxErrors))
+	for _, err := range syntaxErrors {
+		e := newError(err)
+		e.Code = vortexerrors.Diagnose(err)[0].Code
+		errs = append(errs, e)
+	}
+	return errs
+}
+
+func newError(err error) *Error {
+	var unexpected *vortexerrors.UnexpectedToken
+	var edgeType *vortexerrors.UnknownEdgeType
+	var statement *vortexerrors.UnknownStatement
+	var builtinFunc *vortexerrors.UnknownBuiltinFunc
+	var positioned *visitors.PositionedError
+
+	switch {
+	case errors.As(err, &unexpected):
+		expected := unexpected.SuggestedTokens
+		if expected == nil && unexpected.ExpectedToken != 0 {
+			expected = []lexer.TokenType{unexpected.ExpectedToken}
+		}
+		return tokenError("Unexpected", unexpected.ActualToken, tokenNames(expected))
+	case errors.As(err, &edgeType):
+		return tokenError("Unknown", edgeType.ActualToken, stringNames(nodes.GetAllEdgeTypes()))
+	case errors.As(err, &statement):
+		return tokenError("Unknown", statement.ActualToken, tokenNames(lexer.GetAllStatementTypes()))
+	case errors.As(err, &builtinFunc):
+		return tokenError("Unknown", builtinFunc.ActualToken, builtinFunc.Suggestions())
+	case errors.As(err, &positioned):
+		e := &Error{Message: positioned.Err.Error(), Expected: positioned.Suggestions}
+		if !positioned.Span.IsZero() {
+			e.Row, e.Col = positioned.Span.Start.Row+1, positioned.Span.Start.Col+1
+		}
+		return e
+	default:
+		return &Error{Message: err.Error()}
+	}
+}
+
+func isSyntaxError(err error) bool {
+	var unexpected *vortexerrors.UnexpectedToken
+	var edgeType *vortexerrors.UnknownEdgeType
+	var statement *vortexerrors.UnknownStatement
+	var builtinFunc *vortexerrors.UnknownBuiltinFunc
+	return errors.As(err, &unexpected) || errors.As(err, &edgeType) ||
+		errors.As(err, &statement) || errors.As(err, &builtinFunc)
+}
+
+func tokenError(problem string, token *lexer.Token, expected []string) *Error {
+	return &Error{
+		Message:  fmt.Sprintf("%s %q found", problem, token.Value),
+		Token:    token.Value,
+		Row:      token.Row + 1,
+		Col:      token.Col + 1,
+		Expected: expected,
+	}
+}
+
+func tokenNames(tokens []lexer.TokenType) []string {
+	names := make([]string, 0, len(tokens))
+	for _, t := range tokens {
+		names = append(names, t.String())
+	}
+	return names
+}
+
+func stringNames[T fmt.Stringer](values []T) []string {
+	names := make([]string, 0, len(values))
+	for _, v := range values {
+		names = append(names, v.String())
+	}
+	return names
+}
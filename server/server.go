@@ -0,0 +1,271 @@
+// Package server shares a Vortex database over HTTP. Programs are posted to
+// /exec, or to /query for programs that only read the graph, and the result
+// of every statement is returned as JSON.
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"mime"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/Jintumoni/vortex"
+	"github.com/Jintumoni/vortex/executor"
+	"github.com/Jintumoni/vortex/nodes"
+	"github.com/Jintumoni/vortex/visitors"
+)
+
+// MaxProgramSize is the largest request body accepted, in bytes
+const MaxProgramSize = 8 << 20
+
+// Server answers the requests made to one database
+type Server struct {
+	DB       *vortex.DB
+	Timeout  time.Duration // longest a program may run, 0 for no limit
+	ReadOnly bool          // /exec refuses the programs that change the graph
+}
+
+func NewServer(db *vortex.DB) *Server {
+	return &Server{DB: db, Timeout: 30 * time.Second}
+}
+
+// Handler returns the routes of the server:
+//
+//	POST /exec    runs a program
+//	POST /query   runs a program that does not change the graph
+//	GET  /health  reports whether the database can be used
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /exec", func(w http.ResponseWriter, r *http.Request) {
+		s.run(w, r, s.ReadOnly)
+	})
+	mux.HandleFunc("POST /query", func(w http.ResponseWriter, r *http.Request) {
+		s.run(w, r, true)
+	})
+	mux.HandleFunc("GET /health", s.health)
+	return mux
+}
+
+// ListenAndServe serves the database on the address until it fails
+func (s *Server) ListenAndServe(addr string) error {
+	server := &http.Server{
+		Addr:              addr,
+		Handler:           s.Handler(),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	return server.ListenAndServe()
+}
+
+// Request is the body of a JSON request. A request that is not JSON holds the program alone.
+type Request struct {
+	Program    string         `json:"program"`
+	Parameters map[string]any `json:"parameters,omitempty"`
+}
+
+// Response is the body of every answer to /exec and /query
+type Response struct {
+	Results []*Result `json:"results"`          // results of the statements that ran
+	Error   *Error    `json:"error,omitempty"`  // why the next statement failed, nil when all of them ran
+	Errors  Errors    `json:"errors,omitempty"` // every syntax error of the program or the error alone, Error is the first one
+}
+
+// Result is the outcome of one statement
+type Result struct {
+	Statement string `json:"statement"` // keyword of the statement, eg: Query
+	*Table
+	Plan string `json:"plan,omitempty"` // plan of an Explain or a Profile as it is printed
+}
+
+// Table holds the rows of a query, a profile or a Call without Write
+type Table struct {
+	Columns []string `json:"columns"`
+	Rows    [][]any  `json:"rows"`
+}
+
+func (s *Server) health(w http.ResponseWriter, r *http.Request) {
+	if err := s.DB.Ping(); err != nil {
+		writeJSON(w, http.StatusServiceUnavailable, map[string]any{"status": "unavailable", "error": NewError(err)})
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"status": "ok"})
+}
+
+func (s *Server) run(w http.ResponseWriter, r *http.Request, readOnly bool) {
+	request, err := readRequest(r)
+	if err != nil {
+		code := http.StatusBadRequest
+		if _, ok := err.(*http.MaxBytesError); ok {
+			code = http.StatusRequestEntityTooLarge
+		}
+		errs := NewErrors(err)
+		writeJSON(w, code, &Response{Results: []*Result{}, Error: errs[0], Errors: errs})
+		return
+	}
+
+	ctx := r.Context()
+	if s.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, s.Timeout)
+		defer cancel()
+	}
+
+	// a program that changes the graph runs to its end even after the
+	// request timed out, its results are dropped
+	type outcome struct {
+		results []*Result
+		err     error
+	}
+	done := make(chan outcome, 1)
+	go func() {
+		results := []*Result{}
+		emit := func(result *executor.Result) error {
+			results = append(results, NewResult(result))
+			return nil
+		}
+		var err error
+		if readOnly {
+			err = s.DB.RunReadOnly(ctx, request.Program, request.Parameters, emit)
+		} else {
+			err = s.DB.Run(ctx, request.Program, request.Parameters, emit)
+		}
+		done <- outcome{results, err}
+	}()
+
+	response := &Response{Results: []*Result{}}
+	select {
+	case outcome := <-done:
+		response.Results, err = outcome.results, outcome.err
+	case <-ctx.Done():
+		err = ctx.Err()
+	}
+	if err != nil {
+		response.Errors = NewErrors(err)
+		response.Error = response.Errors[0]
+		writeJSON(w, status(err), response)
+		return
+	}
+	writeJSON(w, http.StatusOK, response)
+}
+
+// readRequest reads a JSON request, or a program sent as plain text
+func readRequest(r *http.Request) (*Request, error) {
+	body := http.MaxBytesReader(nil, r.Body, MaxProgramSize)
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if mediaType != "application/json" {
+		program, err := io.ReadAll(body)
+		if err != nil {
+			return nil, err
+		}
+		return &Request{Program: string(program)}, nil
+	}
+
+	var request Request
+	decoder := json.NewDecoder(body)
+	decoder.UseNumber()
+	if err := decoder.Decode(&request); err != nil {
+		return nil, err
+	}
+	for name, value := range request.Parameters {
+		request.Parameters[name] = parameter(value)
+	}
+	return &request, nil
+}
+
+// parameter returns a JSON value with its numbers as int or float64, a
+// number written with a fraction or an exponent is a float
+func parameter(value any) any {
+	switch v := value.(type) {
+	case json.Number:
+		if !strings.ContainsAny(v.String(), ".eE") {
+			if number, err := v.Int64(); err == nil {
+				return int(number)
+			}
+		}
+		number, _ := v.Float64()
+		return number
+	case []any:
+		for i := range v {
+			v[i] = parameter(v[i])
+		}
+		return v
+	default:
+		return value
+	}
+}
+
+// NewResult describes the result of a statement for a response
+func NewResult(result *executor.Result) *Result {
+	r := &Result{Statement: statementName(result.Statement)}
+	if result.Rows != nil {
+		r.Table = &Table{Columns: result.Rows.Columns, Rows: make([][]any, 0, len(result.Rows.Rows))}
+		for _, row := range result.Rows.Rows {
+			values := make([]any, 0, len(row))
+			for _, value := range row {
+				values = append(values, vortex.Value(value))
+			}
+			r.Table.Rows = append(r.Table.Rows, values)
+		}
+	}
+	if result.Plan != nil {
+		plan := new(strings.Builder)
+		visualizer := visitors.NewVisualizer()
+		visualizer.Output = plan
+		if result.Profile != nil {
+			visualizer.VisualizeProfile(result.Plan, result.Profile)
+		} else {
+			visualizer.VisualizePlan(result.Plan)
+		}
+		r.Plan = plan.String()
+	}
+	return r
+}
+
+// statementName returns the keyword a statement is written with
+func statementName(node nodes.ASTNode) string {
+	switch node.(type) {
+	case *nodes.SchemaDefNode:
+		return "Schema"
+	case *nodes.EdgeDefNode:
+		return "Edge"
+	case *nodes.VertexInitNode:
+		return "Vertex"
+	case *nodes.RelationInitNode:
+		return "Relation"
+	case *nodes.QueryStatementNode:
+		return "Query"
+	case *nodes.ExplainStatementNode:
+		return "Explain"
+	case *nodes.ProfileStatementNode:
+		return "Profile"
+	case *nodes.CallStatementNode:
+		return "Call"
+	default:
+		return ""
+	}
+}
+
+// status returns the HTTP status of the response to a program that failed
+func status(err error) int {
+	switch {
+	case errors.Is(err, vortex.ReadOnly):
+		return http.StatusForbidden
+	case errors.Is(err, vortex.Closed):
+		return http.StatusServiceUnavailable
+	case errors.Is(err, context.DeadlineExceeded):
+		return http.StatusGatewayTimeout
+	case isSyntaxError(err):
+		return http.StatusBadRequest
+	default:
+		return http.StatusUnprocessableEntity
+	}
+}
+
+func writeJSON(w http.ResponseWriter, status int, body any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(body)
+}
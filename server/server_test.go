@@ -0,0 +1,146 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Jintumoni/vortex"
+	"github.com/stretchr/testify/assert"
+)
+
+const testGraph = `
+Schema Person {
+  name string
+  age  int
+}
+
+Vertex Ann Person { .name = "Ann" .age = 30 }
+Vertex Bob Person { .name = "Bob" .age = 20 }
+`
+
+func newTestServer(t *testing.T) *httptest.Server {
+	db, err := vortex.Open("", nil)
+	assert.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+
+	server := httptest.NewServer(NewServer(db).Handler())
+	t.Cleanup(server.Close)
+	return server
+}
+
+// post sends the body to the path and decodes the response
+func post(t *testing.T, server *httptest.Server, path, contentType, body string) (int, *Response) {
+	resp, err := http.Post(server.URL+path, contentType, strings.NewReader(body))
+	assert.NoError(t, err)
+	defer resp.Body.Close()
+
+	var response Response
+	assert.NoError(t, json.NewDecoder(resp.Body).Decode(&response))
+	return resp.StatusCode, &response
+}
+
+func TestExec(t *testing.T) {
+	server := newTestServer(t)
+
+	code, response := post(t, server, "/exec", "text/plain", testGraph+`Query Person { .age > 25 } Return .name, .age`)
+	assert.Equal(t, http.StatusOK, code)
+	assert.Nil(t, response.Error)
+	assert.Len(t, response.Results, 4)
+	assert.Equal(t, "Schema", response.Results[0].Statement)
+	assert.Nil(t, response.Results[0].Table)
+
+	query := response.Results[3]
+	assert.Equal(t, "Query", query.Statement)
+	assert.Equal(t, []string{".name", ".age"}, query.Columns)
+	assert.Equal(t, [][]any{{"Ann", float64(30)}}, query.Rows)
+}
+
+func TestQueryWithParameters(t *testing.T) {
+	server := newTestServer(t)
+	post(t, server, "/exec", "text/plain", testGraph)
+
+	code, response := post(t, server, "/query", "application/json",
+		`{"program": "Query Person { .age > $age } Return .name Explain Query Person", "parameters": {"age": 10}}`)
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, [][]any{{"Ann"}, {"Bob"}}, response.Results[0].Rows)
+	assert.Contains(t, response.Results[1].Plan, "Scan Person")
+}
+
+func TestQueryIsReadOnly(t *testing.T) {
+	server := newTestServer(t)
+
+	code, response := post(t, server, "/query", "text/plain", testGraph)
+	assert.Equal(t, http.StatusForbidden, code)
+	assert.Equal(t, vortex.ReadOnly.Error(), response.Error.Message)
+}
+
+func TestSyntaxError(t *testing.T) {
+	server := newTestServer(t)
+
+	code, response := post(t, server, "/exec", "text/plain", "Schema Person {\n  name string\n  age 5\n}")
+	assert.Equal(t, http.StatusBadRequest, code)
+	assert.Empty(t, response.Results)
+	assert.Equal(t, &Error{
+		Message:  `Unexpected "5" found`,
+		Code:     "V0001",
+		Token:    "5",
+		Row:      3,
+		Col:      7,
+		Expected: response.Error.Expected,
+	}, response.Error)
+	assert.NotEmpty(t, response.Error.Expected)
+
+	// every error of a program is reported, the first one is the error
+	code, response = post(t, server, "/exec", "text/plain", "Foo\nSchema Person {\n  age 5\n}")
+	assert.Equal(t, http.StatusBadRequest, code)
+	assert.Equal(t, "Foo", response.Error.Token)
+	assert.Equal(t, 1, response.Error.Row)
+	assert.Len(t, response.Errors, 2)
+	assert.Equal(t, response.Error, response.Errors[0])
+	assert.Equal(t, "5", response.Errors[1].Token)
+	assert.Equal(t, 3, response.Errors[1].Row)
+}
+
+func TestExecutionError(t *testing.T) {
+	server := newTestServer(t)
+
+	code, response := post(t, server, "/exec", "text/plain", testGraph+`Query Person { .age > B.age }`)
+	assert.Equal(t, http.StatusUnprocessableEntity, code)
+	assert.Len(t, response.Results, 3)
+	assert.Equal(t, "Unknown alias, schema or vertex: B", response.Error.Message)
+	assert.Equal(t, "V0203", response.Error.Code)
+	assert.Equal(t, 9, response.Error.Row)
+	assert.Equal(t, 23, response.Error.Col)
+}
+
+func TestHealth(t *testing.T) {
+	server := newTestServer(t)
+
+	resp, err := http.Get(server.URL + "/health")
+	assert.NoError(t, err)
+	defer resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	resp, err = http.Get(server.URL + "/exec")
+	assert.NoError(t, err)
+	defer resp.Body.Close()
+	assert.Equal(t, http.StatusMethodNotAllowed, resp.StatusCode)
+}
+
+func TestTimeout(t *testing.T) {
+	db, err := vortex.Open("", nil)
+	assert.NoError(t, err)
+	defer db.Close()
+	s := NewServer(db)
+	s.Timeout = time.Nanosecond
+	server := httptest.NewServer(s.Handler())
+	defer server.Close()
+
+	code, response := post(t, server, "/query", "text/plain", `Query Person`)
+	assert.Equal(t, http.StatusGatewayTimeout, code)
+	assert.Equal(t, "context deadline exceeded", response.Error.Message)
+}
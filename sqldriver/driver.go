@@ -0,0 +1,253 @@
+// Package sqldriver registers Vortex as the "vortex" database/sql driver.
+//
+//	import _ "github.com/Jintumoni/vortex/sqldriver"
+//
+//	db, err := sql.Open("vortex", "file:/data/graph")
+//	rows, err := db.QueryContext(ctx, `Query Person { .age > ? } Return .name`, 18)
+//
+// The data source name is "file:" followed by the directory of the database,
+// "file::memory:" for a database held only in memory. Adding "?mode=ro"
+// opens the database read only.
+package sqldriver
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"strings"
+
+	"github.com/Jintumoni/vortex"
+	"github.com/Jintumoni/vortex/nodes"
+	"github.com/Jintumoni/vortex/visitors"
+)
+
+var (
+	InvalidDSN       = errors.New("Data source name must start with file:")
+	NoTransactions   = errors.New("Transactions are not supported")
+	ExecWithArgument = errors.New("Exec does not bind arguments, parameters are only used by queries")
+)
+
+// Memory is the path of a data source name opening a database held only in memory
+const Memory = ":memory:"
+
+func init() {
+	sql.Register("vortex", &Driver{})
+}
+
+// Driver opens Vortex databases for database/sql
+type Driver struct{}
+
+// Open opens a new database for the data source name. A sql.DB opened with
+// sql.Open uses OpenConnector instead, so all its connections share one database.
+func (d *Driver) Open(dsn string) (driver.Conn, error) {
+	dir, options, err := parseDSN(dsn)
+	if err != nil {
+		return nil, err
+	}
+	db, err := vortex.Open(dir, options)
+	if err != nil {
+		return nil, err
+	}
+	return &Conn{db: db, owned: true}, nil
+}
+
+// OpenConnector opens the database of the data source name
+func (d *Driver) OpenConnector(dsn string) (driver.Connector, error) {
+	dir, options, err := parseDSN(dsn)
+	if err != nil {
+		return nil, err
+	}
+	db, err := vortex.Open(dir, options)
+	if err != nil {
+		return nil, err
+	}
+	return &Connector{driver: d, db: db}, nil
+}
+
+// parseDSN returns the directory and the options of a data source name
+func parseDSN(dsn string) (string, *vortex.Options, error) {
+	path, ok := strings.CutPrefix(dsn, "file:")
+	if !ok {
+		return "", nil, fmt.Errorf("%w: %s", InvalidDSN, dsn)
+	}
+
+	options := &vortex.Options{}
+	path, query, _ := strings.Cut(path, "?")
+	values, err := url.ParseQuery(query)
+	if err != nil {
+		return "", nil, err
+	}
+	options.ReadOnly = values.Get("mode") == "ro"
+	if path == Memory {
+		path = ""
+	}
+	return path, options, nil
+}
+
+// Connector hands out connections to one database
+type Connector struct {
+	driver *Driver
+	db     *vortex.DB
+}
+
+func (c *Connector) Connect(ctx context.Context) (driver.Conn, error) {
+	return &Conn{db: c.db}, nil
+}
+
+func (c *Connector) Driver() driver.Driver {
+	return c.driver
+}
+
+// Close closes the database, it is called by sql.DB.Close
+func (c *Connector) Close() error {
+	return c.db.Close()
+}
+
+// Conn is a connection to a database. Vortex runs statements without
+// transactions, so a connection only holds the database it runs them on.
+type Conn struct {
+	db    *vortex.DB
+	owned bool // the database was opened for this connection alone
+}
+
+func (c *Conn) Prepare(query string) (driver.Stmt, error) {
+	return c.PrepareContext(context.Background(), query)
+}
+
+func (c *Conn) PrepareContext(ctx context.Context, query string) (driver.Stmt, error) {
+	return &Stmt{conn: c, query: query}, nil
+}
+
+func (c *Conn) Close() error {
+	if c.owned {
+		return c.db.Close()
+	}
+	return nil
+}
+
+func (c *Conn) Begin() (driver.Tx, error) {
+	return nil, NoTransactions
+}
+
+func (c *Conn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	if len(args) > 0 {
+		return nil, ExecWithArgument
+	}
+	if err := c.db.Exec(ctx, query); err != nil {
+		return nil, err
+	}
+	return driver.ResultNoRows, nil
+}
+
+func (c *Conn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	params := make(map[string]any, len(args))
+	for _, arg := range args {
+		params[parameterName(arg.Name, arg.Ordinal)] = arg.Value
+	}
+
+	rows, err := c.db.Query(ctx, bindPlaceholders(query), params)
+	if err != nil {
+		return nil, err
+	}
+	return &Rows{rows: rows}, nil
+}
+
+// CheckNamedValue accepts every value Vortex has a parameter type for
+func (c *Conn) CheckNamedValue(arg *driver.NamedValue) error {
+	if _, err := visitors.ParameterValue(arg.Value); err != nil {
+		return err
+	}
+	return nil
+}
+
+// Stmt is a statement prepared on a connection. Vortex caches the plans of
+// the queries it runs, so preparing only keeps the source.
+type Stmt struct {
+	conn  *Conn
+	query string
+}
+
+func (s *Stmt) Close() error {
+	return nil
+}
+
+// NumInput returns -1, the placeholders are counted when the statement runs
+func (s *Stmt) NumInput() int {
+	return -1
+}
+
+func (s *Stmt) Exec(args []driver.Value) (driver.Result, error) {
+	return s.ExecContext(context.Background(), namedValues(args))
+}
+
+func (s *Stmt) ExecContext(ctx context.Context, args []driver.NamedValue) (driver.Result, error) {
+	return s.conn.ExecContext(ctx, s.query, args)
+}
+
+func (s *Stmt) Query(args []driver.Value) (driver.Rows, error) {
+	return s.QueryContext(context.Background(), namedValues(args))
+}
+
+func (s *Stmt) QueryContext(ctx context.Context, args []driver.NamedValue) (driver.Rows, error) {
+	return s.conn.QueryContext(ctx, s.query, args)
+}
+
+func namedValues(args []driver.Value) []driver.NamedValue {
+	named := make([]driver.NamedValue, 0, len(args))
+	for i, arg := range args {
+		named = append(named, driver.NamedValue{Ordinal: i + 1, Value: arg})
+	}
+	return named
+}
+
+// Rows are the rows of a query, their columns are named after the Return clause
+type Rows struct {
+	rows *vortex.Rows
+}
+
+func (r *Rows) Columns() []string {
+	return r.rows.Columns()
+}
+
+func (r *Rows) Close() error {
+	return r.rows.Close()
+}
+
+// Next copies the next row into dest. Numbers, strings, booleans and null
+// keep their type, the other values are copied as they are printed.
+func (r *Rows) Next(dest []driver.Value) error {
+	if !r.rows.Next() {
+		return io.EOF
+	}
+
+	values := make([]nodes.ASTNode, len(dest))
+	pointers := make([]any, len(dest))
+	for i := range values {
+		pointers[i] = &values[i]
+	}
+	if err := r.rows.Scan(pointers...); err != nil {
+		return err
+	}
+
+	for i, value := range values {
+		switch v := value.(type) {
+		case *nodes.IntNode:
+			dest[i] = int64(v.Value)
+		case *nodes.FloatNode:
+			dest[i] = v.Value
+		case *nodes.StringNode:
+			dest[i] = v.Value
+		case *nodes.BoolNode:
+			dest[i] = v.Value
+		case *nodes.NullNode:
+			dest[i] = nil
+		default:
+			dest[i] = visitors.FormatValue(value)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,106 @@
+package sqldriver
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"testing"
+
+	"github.com/Jintumoni/vortex"
+	"github.com/stretchr/testify/assert"
+)
+
+const testGraph = `
+Schema Person {
+  name string
+  age  int
+}
+
+Vertex Ann Person { .name = "Ann" .age = 30 }
+Vertex Bob Person { .name = "Bob" .age = 20 }
+Vertex Cid Person { .name = "Cid" .age = 41 }
+`
+
+func TestBindPlaceholders(t *testing.T) {
+	assert.Equal(t, `Query Person { .age > $p1 and .name = $p2 }`, bindPlaceholders(`Query Person { .age > ? and .name = ? }`))
+	assert.Equal(t, `Query Person { .age > $p2 and .age < $p1 }`, bindPlaceholders(`Query Person { .age > $2 and .age < $1 }`))
+	assert.Equal(t, `Query Person { .name = "?" or .name = $p1 }`, bindPlaceholders(`Query Person { .name = "?" or .name = ? }`))
+	assert.Equal(t, `Query Person { .age > $age }`, bindPlaceholders(`Query Person { .age > $age }`))
+
+	// raw strings and comments are kept as they are
+	for _, src := range []string{
+		"Query Person { .name = `what? $1` or .name = %s }",
+		"Query Person { // who? $1\n .name = %s }",
+		"Query Person { /* who?\n $1 */ .name = %s }",
+		"Query Person { .name = 'it\\'s ?' or .name = %s }",
+	} {
+		assert.Equal(t, fmt.Sprintf(src, "$p1"), bindPlaceholders(fmt.Sprintf(src, "?")))
+	}
+}
+
+func TestParseDSN(t *testing.T) {
+	dir, options, err := parseDSN("file:/data/graph?mode=ro")
+	assert.NoError(t, err)
+	assert.Equal(t, "/data/graph", dir)
+	assert.Equal(t, &vortex.Options{ReadOnly: true}, options)
+
+	dir, _, err = parseDSN("file::memory:")
+	assert.NoError(t, err)
+	assert.Equal(t, "", dir)
+
+	_, _, err = parseDSN("/data/graph")
+	assert.ErrorIs(t, err, InvalidDSN)
+}
+
+func TestQueryContext(t *testing.T) {
+	ctx := context.Background()
+	db, err := sql.Open("vortex", "file:"+t.TempDir())
+	assert.NoError(t, err)
+	defer db.Close()
+
+	_, err = db.ExecContext(ctx, testGraph)
+	assert.NoError(t, err)
+
+	for _, query := range []struct {
+		src  string
+		args []any
+	}{
+		{`Query Person { .age > ? and .age < ? } Return .name, .age`, []any{25, 50}},
+		{`Query Person { .age > $1 and .age < $2 } Return .name, .age`, []any{25, 50}},
+		{`Query Person { .age > $min and .age < $max } Return .name, .age`, []any{sql.Named("min", 25), sql.Named("max", 50)}},
+	} {
+		rows, err := db.QueryContext(ctx, query.src, query.args...)
+		assert.NoError(t, err)
+
+		columns, err := rows.Columns()
+		assert.NoError(t, err)
+		assert.Equal(t, []string{".name", ".age"}, columns)
+
+		var names []string
+		var ages []int
+		for rows.Next() {
+			var name string
+			var age int
+			assert.NoError(t, rows.Scan(&name, &age))
+			names = append(names, name)
+			ages = append(ages, age)
+		}
+		assert.NoError(t, rows.Err())
+		assert.Equal(t, []string{"Ann", "Cid"}, names)
+		assert.Equal(t, []int{30, 41}, ages)
+	}
+
+	// a placeholder in a raw string or a comment is not bound
+	var name string
+	assert.NoError(t, db.QueryRowContext(ctx, "Query Person { .name = `who?` or .age > ? } // older than ?\n Return .name", 35).Scan(&name))
+	assert.Equal(t, "Cid", name)
+	assert.NoError(t, db.QueryRowContext(ctx, "Query Person { /* $1 is the age */ .age < $1 } Return .name", 25).Scan(&name))
+	assert.Equal(t, "Bob", name)
+
+	var count int64
+	assert.NoError(t, db.QueryRowContext(ctx, `Query Count(Person)`).Scan(&count))
+	assert.Equal(t, int64(3), count)
+
+	_, err = db.BeginTx(ctx, nil)
+	assert.ErrorIs(t, err, NoTransactions)
+}
@@ -0,0 +1,83 @@
+package sqldriver
+
+import (
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// parameterName returns the Vortex parameter an argument is bound to: its
+// name, or p1, p2... for the placeholders ? and $1, $2...
+func parameterName(name string, ordinal int) string {
+	if name != "" {
+		return name
+	}
+	return "p" + strconv.Itoa(ordinal)
+}
+
+// bindPlaceholders rewrites the placeholders of a query to Vortex parameters.
+// The n-th ? and $n become $pn, the text inside string literals and comments
+// is kept.
+func bindPlaceholders(query string) string {
+	var b strings.Builder
+	ordinal := 0
+	runes := []rune(query)
+	for i := 0; i < len(runes); i++ {
+		if end := opaque(runes, i); end > i {
+			b.WriteString(string(runes[i:end]))
+			i = end - 1
+			continue
+		}
+
+		r := runes[i]
+		switch {
+		case r == '?':
+			ordinal++
+			b.WriteString("$" + parameterName("", ordinal))
+			continue
+		case r == '$' && i+1 < len(runes) && unicode.IsDigit(runes[i+1]):
+			b.WriteString("$p")
+			continue
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// opaque returns the end of the string literal or of the comment starting at
+// runes[i], or i when none starts there. A quoted string ends at its closing
+// quote that is not escaped, a raw string at the next backtick, a // comment
+// at the end of its line and a /* comment after */.
+func opaque(runes []rune, i int) int {
+	switch {
+	case runes[i] == '"' || runes[i] == '\'':
+		for j := i + 1; j < len(runes); j++ {
+			if runes[j] == '\\' {
+				j++
+			} else if runes[j] == runes[i] {
+				return j + 1
+			}
+		}
+		return len(runes)
+	case runes[i] == '`':
+		return until(runes, i+1, "`")
+	case runes[i] == '/' && i+1 < len(runes) && runes[i+1] == '/':
+		return until(runes, i+2, "\n")
+	case runes[i] == '/' && i+1 < len(runes) && runes[i+1] == '*':
+		return until(runes, i+2, "*/")
+	default:
+		return i
+	}
+}
+
+// until returns the position after the first delimiter found from runes[i],
+// or the end of the runes when there is none
+func until(runes []rune, i int, delimiter string) int {
+	n := len([]rune(delimiter))
+	for ; i+n <= len(runes); i++ {
+		if string(runes[i:i+n]) == delimiter {
+			return i + n
+		}
+	}
+	return len(runes)
+}
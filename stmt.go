@@ -0,0 +1,143 @@
+package vortex
+
+import (
+	"context"
+
+	"github.com/Jintumoni/vortex/executor"
+)
+
+// Stmt is a program parsed once and run many times with different values of
+// its parameters. The plans of its queries are made on the first run, and
+// again after writes changed the graph.
+type Stmt struct {
+	db        *DB
+	src       string
+	statement *executor.Statement
+}
+
+// Prepare parses a program for Stmt.Run
+func (db *DB) Prepare(src string) (*Stmt, error) {
+	q := db.executor(src)
+	statement, err := q.Prepare()
+	if err != nil {
+		return nil, err
+	}
+	if statement.ReadOnly() {
+		q.Limits = db.options.Limits
+	}
+	return &Stmt{db: db, src: src, statement: statement}, nil
+}
+
+// ReadOnly reports whether the program runs without changing the graph
+func (s *Stmt) ReadOnly() bool {
+	return s.statement.ReadOnly()
+}
+
+// Run runs the program like DB.Run
+func (s *Stmt) Run(ctx context.Context, params map[string]any, emit func(*executor.Result) error) error {
+	return s.run(ctx, params, false, emit)
+}
+
+// RunReadOnly runs the program like DB.RunReadOnly
+func (s *Stmt) RunReadOnly(ctx context.Context, params map[string]any, emit func(*executor.Result) error) error {
+	return s.run(ctx, params, true, emit)
+}
+
+func (s *Stmt) run(ctx context.Context, params map[string]any, readOnly bool, emit func(*executor.Result) error) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	db := s.db
+
+	if s.ReadOnly() {
+		db.mu.RLock()
+		defer db.mu.RUnlock()
+
+		if db.closed {
+			return Closed
+		}
+		return s.statement.Run(ctx, params, emit)
+	}
+
+	if readOnly || db.options.ReadOnly {
+		return ReadOnly
+	}
+
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	if db.closed {
+		return Closed
+	}
+	return s.write(params, emit)
+}
+
+// write runs a program changing the graph, the database must be locked
+func (s *Stmt) write(params map[string]any, emit func(*executor.Result) error) error {
+	db := s.db
+	db.queriesMu.Lock()
+	clear(db.queries)
+	db.queriesMu.Unlock()
+
+	entry, err := newLogEntry(s.src, params)
+	if err != nil {
+		return err
+	}
+	// the program is logged even when it fails, replaying it stops at the
+	// same statement and leaves the graph as it is now. It runs to its end
+	// when emit fails, the log could not replay a program stopped by it.
+	var emitErr error
+	err = s.statement.Run(context.Background(), params, func(result *executor.Result) error {
+		if emitErr == nil {
+			emitErr = emit(result)
+		}
+		return nil
+	})
+	if logErr := db.append(entry); logErr != nil {
+		return logErr
+	}
+	if err != nil {
+		return err
+	}
+	return emitErr
+}
+
+// Execution is a prepared program with the values of its parameters
+type Execution struct {
+	Stmt       *Stmt
+	Parameters map[string]any
+}
+
+// RunAll runs the programs one after the other without letting any other
+// program run between them, and calls emit with the result of every
+// statement. It stops at the first statement that fails, the graph has no
+// undo so the programs before it are kept.
+func (db *DB) RunAll(ctx context.Context, executions []Execution, emit func(*executor.Result) error) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	for _, execution := range executions {
+		if !execution.Stmt.ReadOnly() && db.options.ReadOnly {
+			return ReadOnly
+		}
+	}
+
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	if db.closed {
+		return Closed
+	}
+	for _, execution := range executions {
+		var err error
+		if execution.Stmt.ReadOnly() {
+			err = execution.Stmt.statement.Run(context.Background(), execution.Parameters, emit)
+		} else {
+			err = execution.Stmt.write(execution.Parameters, emit)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
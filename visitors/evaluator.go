@@ -0,0 +1,1495 @@
+package visitors
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/Jintumoni/vortex/algorithms"
+	vortexerrors "github.com/Jintumoni/vortex/errors"
+	"github.com/Jintumoni/vortex/lexer"
+	"github.com/Jintumoni/vortex/manager"
+	"github.com/Jintumoni/vortex/nodes"
+)
+
+var (
+	NotEvaluable          = errors.New("Node can not be evaluated in a query")
+	NotACondition         = errors.New("Expression is not a condition")
+	UnknownName           = errors.New("Unknown alias, schema or vertex")
+	UnboundVertex         = errors.New("Property is not scoped to any vertex")
+	TypeMismatch          = errors.New("Type mismatch")
+	DivisionByZero        = errors.New("Division by zero")
+	EmptyAggregate        = errors.New("Aggregate over no values")
+	GroupByWithoutPattern = errors.New("Group By needs a vertex term to group")
+	MissingParameter      = errors.New("Parameter is not set")
+)
+
+func init() {
+	vortexerrors.RegisterCode("V0201", NotEvaluable)
+	vortexerrors.RegisterCode("V0202", NotACondition)
+	vortexerrors.RegisterCode("V0203", UnknownName)
+	vortexerrors.RegisterCode("V0204", UnboundVertex)
+	vortexerrors.RegisterCode("V0205", TypeMismatch)
+	vortexerrors.RegisterCode("V0206", DivisionByZero)
+	vortexerrors.RegisterCode("V0207", EmptyAggregate)
+	vortexerrors.RegisterCode("V0208", GroupByWithoutPattern)
+	vortexerrors.RegisterCode("V0209", MissingParameter)
+	vortexerrors.RegisterCode("V0210", InvalidParameter)
+}
+
+// Scope is a link in the chain of aliases bound while matching a query.
+// A vertex term binding an alias adds a link in front of the scope it is
+// matched in, so an alias resolves to its innermost binding and the
+// subqueries of a term see the aliases of every term around them.
+type Scope struct {
+	Parent *Scope
+	Alias  string
+	Vertex *nodes.VertexInitNode
+}
+
+// Lookup returns the vertex of the innermost binding of the alias
+func (s *Scope) Lookup(alias string) (*nodes.VertexInitNode, bool) {
+	for ; s != nil; s = s.Parent {
+		if s.Alias == alias {
+			return s.Vertex, true
+		}
+	}
+	return nil, false
+}
+
+// Aliases returns the aliases bound in the scope, innermost first
+func (s *Scope) Aliases() []string {
+	var aliases []string
+	for ; s != nil; s = s.Parent {
+		aliases = append(aliases, s.Alias)
+	}
+	return aliases
+}
+
+// Row holds the vertices bound while matching a query
+type Row struct {
+	Vertex *nodes.VertexInitNode // vertex the unaliased properties refer to
+	Scope  *Scope                // vertices bound with `as`, nil when there are none
+}
+
+// bind returns a copy of the row scoped to v, binding v to alias if it is not empty
+func (r *Row) bind(alias string, v *nodes.VertexInitNode) *Row {
+	row := &Row{Vertex: v, Scope: r.Scope}
+	if alias != "" {
+		row.Scope = &Scope{Parent: r.Scope, Alias: alias, Vertex: v}
+	}
+	return row
+}
+
+// scope returns a copy of the row with the same aliases scoped to v
+func (r *Row) scope(v *nodes.VertexInitNode) *Row {
+	return &Row{Vertex: v, Scope: r.Scope}
+}
+
+// PositionedError is an error caused by the part of the query at Span
+type PositionedError = vortexerrors.SemanticError
+
+// positioned attaches the position of the name to err
+func positioned(name *nodes.StringNode, err error) error {
+	if name == nil {
+		return err
+	}
+	if p, ok := err.(*PositionedError); ok && p.Span.IsZero() {
+		p.Span = name.Span
+		return p
+	}
+	return &PositionedError{Span: name.Span, Err: err}
+}
+
+// misspelt attaches the position of a name that does not exist to err, with
+// the names closest to it
+func misspelt(name *nodes.StringNode, err error, names []string) error {
+	return &PositionedError{Span: name.Span, Err: err, Suggestions: vortexerrors.Suggest(name.Value, names)}
+}
+
+// ResultSet is the table produced by a query
+type ResultSet struct {
+	Columns []string
+	Rows    [][]nodes.ASTNode
+}
+
+// Evaluator runs query statements against the data held by the AppManager.
+//
+// Each node is evaluated against a Row. Conditions produce the rows under
+// which they hold and expressions produce a value; both are left in the
+// evaluator state by the Visit methods and collected by match and eval.
+type Evaluator struct {
+	Strategy   manager.TraversalStrategy // order in which variable length relations are walked
+	Parameters map[string]nodes.ASTNode  // values of the $name parameters of the query
+	Limits     Limits                    // work a query may do before it is stopped
+
+	appManager *manager.AppManager
+	row        *Row
+	candidates []*nodes.VertexInitNode // vertices a vertex term is matched against, nil to scan
+	group      []*Row                  // rows aggregated by a Group By, nil outside of one
+	matched    bool                    // rows holds the result of a condition
+	rows       []*Row
+	value      nodes.ASTNode
+	err        error
+	profile    Profile // statistics of the operators run, nil when not profiling
+	ctx        context.Context
+	expanded   int64 // adjacency list entries read by the traversals of the query running
+	halted     error // why the query running must stop, see check
+	// values of the correlated subqueries of the query running, by outer binding
+	subqueries map[subqueryKey]nodes.ASTNode
+	nodeNames  map[nodes.ASTNode][]string
+}
+
+func NewEvaluator(appManager *manager.AppManager) *Evaluator {
+	return &Evaluator{Strategy: manager.BreadthFirst, appManager: appManager}
+}
+
+// Evaluate plans the query, runs it and returns its result table
+func (e *Evaluator) Evaluate(query *nodes.QueryStatementNode) (*ResultSet, error) {
+	plan, err := NewPlanner(e.appManager).Plan(query)
+	if err != nil {
+		return nil, err
+	}
+	return e.Run(plan)
+}
+
+// Run executes a plan made by the Planner and returns its result table
+func (e *Evaluator) Run(plan Operator) (*ResultSet, error) {
+	return e.RunContext(context.Background(), plan)
+}
+
+// RunContext is Run stopped as soon as ctx is done, with the error of ctx,
+// or once the query exceeds one of the Limits of the evaluator, with a
+// *LimitError. The traversals, the aggregates and the loops over the rows
+// are all stopped.
+func (e *Evaluator) RunContext(ctx context.Context, plan Operator) (*ResultSet, error) {
+	if e.Limits.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeoutCause(ctx, e.Limits.Timeout, &LimitError{Limit: "Timeout", Max: e.Limits.Timeout})
+		defer cancel()
+	}
+	e.ctx, e.expanded, e.halted = ctx, 0, nil
+	defer func() { e.ctx = nil }()
+	if err := e.check(0); err != nil {
+		return nil, err
+	}
+
+	root := &Row{}
+	e.subqueries = make(map[subqueryKey]nodes.ASTNode)
+	if e.profile == nil {
+		return e.run(plan, root)
+	}
+
+	m := e.measure()
+	result, err := e.run(plan, root)
+	rows := 0
+	if result != nil {
+		rows = len(result.Rows)
+	}
+	e.record(plan, m, rows)
+	return result, err
+}
+
+func (e *Evaluator) run(plan Operator, root *Row) (*ResultSet, error) {
+	switch op := plan.(type) {
+	case *ValueOp:
+		value, err := e.eval(op.Expression, root)
+		if err != nil {
+			return nil, err
+		}
+		result := &ResultSet{Columns: []string{exprString(op.Expression)}}
+		if list, ok := value.(*nodes.ListNode); ok {
+			// a list, eg: the paths of AllShortestPaths, has a row per value
+			for _, v := range list.Values {
+				result.Rows = append(result.Rows, []nodes.ASTNode{v})
+			}
+			return result, e.check(len(result.Rows))
+		}
+		result.Rows = [][]nodes.ASTNode{{value}}
+		return result, nil
+	case *ProjectOp:
+		rows, err := e.execute(op.Input, root)
+		if err != nil {
+			return nil, err
+		}
+		return e.project(op.Query, rows)
+	case *AggregateOp:
+		rows, err := e.execute(op.Input, root)
+		if err != nil {
+			return nil, err
+		}
+		return e.aggregate(op.Query, root, rows)
+	default:
+		return nil, fmt.Errorf("%w: %s", NotEvaluable, plan)
+	}
+}
+
+// execute returns the rows produced by an operator of a plan, every row
+// is scoped to the vertex of the query
+func (e *Evaluator) execute(op Operator, root *Row) ([]*Row, error) {
+	if e.profile == nil {
+		rows, err := e.executeOperator(op, root)
+		if err != nil {
+			return nil, err
+		}
+		return rows, e.check(len(rows))
+	}
+
+	m := e.measure()
+	rows, err := e.executeOperator(op, root)
+	e.record(op, m, len(rows))
+	if err != nil {
+		return nil, err
+	}
+	return rows, e.check(len(rows))
+}
+
+func (e *Evaluator) executeOperator(op Operator, root *Row) ([]*Row, error) {
+	switch o := op.(type) {
+	case *ScanOp:
+		return e.bindAll(root, o.Name, o.Alias)
+	case *SeekOp:
+		return e.bindAll(root, o.Name, o.Alias)
+	case *ExpandOp:
+		input, err := e.execute(o.Input, root)
+		if err != nil {
+			return nil, err
+		}
+		if !o.Source {
+			return e.filter(input, o.Relation)
+		}
+
+		// the vertices reached backwards are the rows the query starts from,
+		// bound with the aliases of the row they were reached from
+		var rows []*Row
+		for _, r := range input {
+			if err := e.check(len(rows)); err != nil {
+				return nil, err
+			}
+			matched, err := e.match(o.Relation, r, nil)
+			if err != nil {
+				return nil, err
+			}
+			rows = append(rows, matched...)
+		}
+		// keep the order of a scan, the result does not depend on the plan
+		sort.SliceStable(rows, func(i, j int) bool {
+			return e.appManager.Position(rows[i].Vertex) < e.appManager.Position(rows[j].Vertex)
+		})
+		return rows, nil
+	case *FilterOp:
+		input, err := e.execute(o.Input, root)
+		if err != nil {
+			return nil, err
+		}
+		return e.filter(input, o.Condition)
+	default:
+		return nil, fmt.Errorf("%w: %s", NotEvaluable, op)
+	}
+}
+
+// bindAll returns a row per vertex named by the schema or vertex name, bound to alias
+func (e *Evaluator) bindAll(root *Row, name, alias string) ([]*Row, error) {
+	saved := e.row
+	e.row = root
+	vertices, err := e.scan(name)
+	e.row = saved
+	if err != nil {
+		return nil, err
+	}
+
+	rows := make([]*Row, 0, len(vertices))
+	for _, v := range vertices {
+		rows = append(rows, root.bind(alias, v))
+	}
+	return rows, nil
+}
+
+// filter returns the rows under which the condition holds for every input row
+func (e *Evaluator) filter(input []*Row, condition nodes.ASTNode) ([]*Row, error) {
+	rows := []*Row{}
+	for _, r := range input {
+		if err := e.check(len(rows)); err != nil {
+			return nil, err
+		}
+		matched, err := e.match(condition, r, nil)
+		if err != nil {
+			return nil, err
+		}
+		for _, m := range matched {
+			rows = append(rows, m.scope(r.Vertex))
+		}
+	}
+	return rows, nil
+}
+
+// Call runs the algorithm of the call statement. The results are written to
+// the vertices when the statement has a Write clause and returned otherwise.
+func (e *Evaluator) Call(call *nodes.CallStatementNode) (*ResultSet, error) {
+	algorithm, err := algorithms.GetAlgorithm(call.Procedure.Value)
+	if err != nil {
+		return nil, err
+	}
+
+	// Call Algorithm(schema, edge, parameters...)
+	var names []string
+	var params []int
+	for _, arg := range call.Args {
+		switch a := arg.(type) {
+		case *nodes.StringNode:
+			if len(params) > 0 || len(names) == 2 {
+				return nil, fmt.Errorf("%w: %s expects a schema and an edge followed by parameters", algorithms.InvalidArguments, algorithm.Name)
+			}
+			names = append(names, a.Value)
+		case *nodes.IntNode:
+			params = append(params, a.Value)
+		}
+	}
+	for len(names) < 2 {
+		names = append(names, "")
+	}
+	params, err = algorithm.Arguments(params)
+	if err != nil {
+		return nil, err
+	}
+
+	graph, err := algorithms.NewGraph(e.appManager, names[0], names[1])
+	if err != nil {
+		return nil, err
+	}
+	values := algorithm.Run(graph, params)
+
+	if call.Write != nil {
+		for i, v := range graph.Vertices {
+			if err := e.appManager.WriteProperty(v, call.Write.Value, values[i]); err != nil {
+				return nil, fmt.Errorf("%w: %s of %s", err, call.Write.Value, v.SchemaName.Value)
+			}
+		}
+		return nil, nil
+	}
+
+	column := names[0]
+	if column == "" {
+		column = "()"
+	}
+	result := &ResultSet{Columns: []string{column, algorithm.Column}}
+	for i, v := range graph.Vertices {
+		result.Rows = append(result.Rows, []nodes.ASTNode{v, values[i]})
+	}
+	return result, nil
+}
+
+// project returns one result row per matched row
+func (e *Evaluator) project(query *nodes.QueryStatementNode, rows []*Row) (*ResultSet, error) {
+	if query.Return == nil {
+		// default projection: the distinct vertices matched by the query
+		term := query.Expression.(*nodes.VertexTermNode)
+		result := &ResultSet{Columns: []string{exprString(term)}}
+		seen := make(map[*nodes.VertexInitNode]bool)
+		for _, r := range rows {
+			if !seen[r.Vertex] {
+				seen[r.Vertex] = true
+				result.Rows = append(result.Rows, []nodes.ASTNode{r.Vertex})
+			}
+		}
+		return result, e.check(len(result.Rows))
+	}
+
+	result := &ResultSet{Columns: exprStrings(query.Return)}
+	for _, r := range rows {
+		if err := e.check(len(result.Rows)); err != nil {
+			return nil, err
+		}
+		values, err := e.evalAll(query.Return, r)
+		if err != nil {
+			return nil, err
+		}
+		result.Rows = append(result.Rows, values)
+	}
+	return result, nil
+}
+
+// aggregate groups the matched rows by the Group By expressions and returns
+// one result row per group that satisfies the Having condition
+func (e *Evaluator) aggregate(query *nodes.QueryStatementNode, root *Row, rows []*Row) (*ResultSet, error) {
+	var keys []string
+	groups := make(map[string][]*Row)
+	if query.GroupBy == nil {
+		// an ungrouped aggregate has exactly one group, even with no rows
+		keys = append(keys, "")
+		groups[""] = rows
+	}
+	for _, r := range rows {
+		if query.GroupBy == nil {
+			break
+		}
+		if err := e.check(0); err != nil {
+			return nil, err
+		}
+		values, err := e.evalAll(query.GroupBy, r)
+		if err != nil {
+			return nil, err
+		}
+		key := groupKey(values)
+		if _, ok := groups[key]; !ok {
+			keys = append(keys, key)
+		}
+		groups[key] = append(groups[key], r)
+	}
+
+	projection := query.Return
+	if projection == nil {
+		projection = query.GroupBy
+	}
+
+	result := &ResultSet{Columns: exprStrings(projection)}
+	for _, key := range keys {
+		if err := e.check(len(result.Rows)); err != nil {
+			return nil, err
+		}
+		group := groups[key]
+		// non aggregated expressions are evaluated against the first row of the group
+		first := root
+		if len(group) > 0 {
+			first = group[0]
+		}
+
+		values, err := e.evalGroup(query.Having, projection, group, first)
+		if err != nil {
+			return nil, err
+		}
+		if values != nil {
+			result.Rows = append(result.Rows, values)
+		}
+	}
+	return result, nil
+}
+
+// evalGroup evaluates the projection over a group, it returns nil when the
+// group does not satisfy the having condition
+func (e *Evaluator) evalGroup(having nodes.ASTNode, projection []nodes.ASTNode, group []*Row, first *Row) ([]nodes.ASTNode, error) {
+	e.group = group
+	defer func() { e.group = nil }()
+
+	if having != nil {
+		rows, err := e.match(having, first, nil)
+		if err != nil || len(rows) == 0 {
+			return nil, err
+		}
+	}
+	return e.evalAll(projection, first)
+}
+
+// match returns the rows, extending row, under which the condition node holds
+func (e *Evaluator) match(node nodes.ASTNode, row *Row, candidates []*nodes.VertexInitNode) ([]*Row, error) {
+	saved := *e
+	e.row, e.candidates = row, candidates
+	e.matched, e.rows, e.value, e.err = false, nil, nil, nil
+
+	node.Accept(e)
+	matched, rows, value, err := e.matched, e.rows, e.value, e.err
+
+	e.row, e.candidates = saved.row, saved.candidates
+	e.matched, e.rows, e.value, e.err = saved.matched, saved.rows, saved.value, saved.err
+
+	if err != nil {
+		return nil, err
+	}
+	if matched {
+		return rows, nil
+	}
+	if b, ok := value.(*nodes.BoolNode); ok {
+		if b.Value {
+			return []*Row{row}, nil
+		}
+		return nil, nil
+	}
+	return nil, fmt.Errorf("%w: %s", NotACondition, exprString(node))
+}
+
+// eval returns the value of the expression node evaluated against row
+func (e *Evaluator) eval(node nodes.ASTNode, row *Row) (nodes.ASTNode, error) {
+	saved := *e
+	e.row, e.candidates = row, nil
+	e.matched, e.rows, e.value, e.err = false, nil, nil, nil
+
+	node.Accept(e)
+	matched, rows, value, err := e.matched, e.rows, e.value, e.err
+
+	e.row, e.candidates = saved.row, saved.candidates
+	e.matched, e.rows, e.value, e.err = saved.matched, saved.rows, saved.value, saved.err
+
+	if err != nil {
+		return nil, err
+	}
+	if value == nil && matched {
+		return &nodes.BoolNode{Value: len(rows) > 0}, nil
+	}
+	return value, nil
+}
+
+func (e *Evaluator) evalAll(expressions []nodes.ASTNode, row *Row) ([]nodes.ASTNode, error) {
+	values := make([]nodes.ASTNode, 0, len(expressions))
+	for _, expression := range expressions {
+		value, err := e.eval(expression, row)
+		if err != nil {
+			return nil, err
+		}
+		values = append(values, value)
+	}
+	return values, nil
+}
+
+func (e *Evaluator) VisitProgramNode(node *nodes.ProgramStatementNode) {
+	e.err = fmt.Errorf("%w: ProgramStatement", NotEvaluable)
+}
+
+func (e *Evaluator) VisitIntNode(node *nodes.IntNode) {
+	e.value = node
+}
+
+func (e *Evaluator) VisitFloatNode(node *nodes.FloatNode) {
+	e.value = node
+}
+
+func (e *Evaluator) VisitBoolNode(node *nodes.BoolNode) {
+	e.value = node
+}
+
+func (e *Evaluator) VisitNullNode(node *nodes.NullNode) {
+	e.value = node
+}
+
+func (e *Evaluator) VisitListNode(node *nodes.ListNode) {
+	e.value = node
+}
+
+func (e *Evaluator) VisitParameterNode(node *nodes.ParameterNode) {
+	value, ok := e.Parameters[node.Name.Value]
+	if !ok {
+		e.err = positioned(node.Name, fmt.Errorf("%w: $%s", MissingParameter, node.Name.Value))
+		return
+	}
+	e.value = value
+}
+
+func (e *Evaluator) VisitPathNode(node *nodes.PathNode) {
+	e.value = node
+}
+
+func (e *Evaluator) VisitStringNode(node *nodes.StringNode) {
+	e.value = node
+}
+
+func (e *Evaluator) VisitSchemaDefNode(node *nodes.SchemaDefNode) {
+	e.err = fmt.Errorf("%w: SchemaDef", NotEvaluable)
+}
+
+func (e *Evaluator) VisitEdgeDefNode(node *nodes.EdgeDefNode) {
+	e.err = fmt.Errorf("%w: EdgeDef", NotEvaluable)
+}
+
+func (e *Evaluator) VisitRelationInitNode(node *nodes.RelationInitNode) {
+	e.err = fmt.Errorf("%w: RelationInit", NotEvaluable)
+}
+
+func (e *Evaluator) VisitPropertyDefNode(node *nodes.PropertyDefNode) {
+	e.err = fmt.Errorf("%w: PropertyDef", NotEvaluable)
+}
+
+func (e *Evaluator) VisitPropertyInitNode(node *nodes.PropertyInitNode) {
+	e.err = fmt.Errorf("%w: PropertyInit", NotEvaluable)
+}
+
+func (e *Evaluator) VisitVertexInitNode(node *nodes.VertexInitNode) {
+	e.err = fmt.Errorf("%w: VertexInit", NotEvaluable)
+}
+
+func (e *Evaluator) VisitEdgeNode(node *nodes.EdgeNode) {
+	e.err = fmt.Errorf("%w: Edge", NotEvaluable)
+}
+
+func (e *Evaluator) VisitPropertyNode(node *nodes.PropertyNode) {
+	vertex := e.row.Vertex
+	if node.Alias != nil {
+		v, ok := e.row.Scope.Lookup(node.Alias.Value)
+		if !ok {
+			e.err = misspelt(node.Alias, fmt.Errorf("%w: %s", UnknownName, node.Alias.Value), e.row.Scope.Aliases())
+			return
+		}
+		vertex = v
+	}
+	if vertex == nil {
+		e.err = positioned(node.PropertyName, fmt.Errorf("%w: %s", UnboundVertex, exprString(node)))
+		return
+	}
+
+	value, err := e.appManager.ReadProperty(vertex, node.PropertyName.Value)
+	if errors.Is(err, manager.PropertyDoesNotExist) {
+		e.err = misspelt(node.PropertyName, fmt.Errorf("%w: %s of %s", err, node.PropertyName.Value, vertex.VertexName.Value),
+			e.appManager.PropertyNames(vertex))
+		return
+	}
+	if err != nil {
+		e.err = fmt.Errorf("%w: %s of %s", err, node.PropertyName.Value, vertex.VertexName.Value)
+		return
+	}
+	e.value = value
+}
+
+func (e *Evaluator) VisitBinaryNode(node *nodes.BinaryNode) {
+	switch node.Operator.Type {
+	case lexer.TokenAnd:
+		left, err := e.match(node.LeftChild, e.row, nil)
+		if err != nil {
+			e.err = err
+			return
+		}
+		e.matched, e.rows = true, []*Row{}
+		for _, r := range left {
+			// the right hand side stays scoped to the vertex of the left hand side
+			right, err := e.match(node.RightChild, r.scope(e.row.Vertex), nil)
+			if err != nil {
+				e.err = err
+				return
+			}
+			e.rows = append(e.rows, right...)
+		}
+	case lexer.TokenOr:
+		left, err := e.match(node.LeftChild, e.row, nil)
+		if err != nil {
+			e.err = err
+			return
+		}
+		right, err := e.match(node.RightChild, e.row, nil)
+		if err != nil {
+			e.err = err
+			return
+		}
+		e.matched, e.rows = true, append(left, right...)
+	default:
+		left, err := e.eval(node.LeftChild, e.row)
+		if err != nil {
+			e.err = err
+			return
+		}
+		right, err := e.eval(node.RightChild, e.row)
+		if err != nil {
+			e.err = err
+			return
+		}
+		if e.value, e.err = binaryOperation(node.Operator, left, right); e.err != nil {
+			e.err = &PositionedError{Span: node.Span, Err: e.err}
+		}
+	}
+}
+
+func (e *Evaluator) VisitVertexNode(node *nodes.VertexNode) {
+	e.err = fmt.Errorf("%w: Vertex", NotEvaluable)
+}
+
+func (e *Evaluator) VisitVertexTermNode(node *nodes.VertexTermNode) {
+	name, alias := "", ""
+	if node.Vertex.VertexName != nil {
+		name = node.Vertex.VertexName.Value
+	}
+	if node.Vertex.Alias != nil {
+		alias = node.Vertex.Alias.Value
+	}
+
+	bound, isAlias := e.row.Scope.Lookup(name)
+	if isAlias && node.Conditions == nil {
+		// an alias used as a value, eg: Count(P)
+		e.value = bound
+	}
+
+	var vertices []*nodes.VertexInitNode
+	if e.candidates != nil {
+		for _, v := range e.candidates {
+			ok, err := e.isA(v, name)
+			if err != nil {
+				e.err = positioned(node.Vertex.VertexName, err)
+				return
+			}
+			if ok {
+				vertices = append(vertices, v)
+			}
+		}
+	} else {
+		var err error
+		vertices, err = e.scan(name)
+		if err != nil {
+			e.err = positioned(node.Vertex.VertexName, err)
+			return
+		}
+	}
+
+	// an alias bound again shadows the outer binding inside the term only
+	shadowed, isShadowing := e.row.Scope.Lookup(alias)
+	isShadowing = isShadowing && alias != ""
+
+	e.matched, e.rows = true, []*Row{}
+	for _, v := range vertices {
+		if err := e.check(0); err != nil {
+			e.err = err
+			return
+		}
+		row := e.row.bind(alias, v)
+		rows := []*Row{row}
+		if node.Conditions != nil {
+			var err error
+			if rows, err = e.match(node.Conditions, row, nil); err != nil {
+				e.err = err
+				return
+			}
+		}
+		for _, r := range rows {
+			r = r.scope(v)
+			if isShadowing {
+				r = r.bind(alias, shadowed).scope(v)
+			}
+			e.rows = append(e.rows, r)
+		}
+	}
+}
+
+// isA reports whether v is the vertex named by the alias, schema or vertex name
+func (e *Evaluator) isA(v *nodes.VertexInitNode, name string) (bool, error) {
+	if name == "" {
+		return true, nil
+	}
+	if bound, ok := e.row.Scope.Lookup(name); ok {
+		return bound == v, nil
+	}
+	if _, err := e.appManager.ReadSchema(name); err == nil {
+		return v.SchemaName.Value == name, nil
+	}
+	if _, err := e.appManager.ReadVertex(name); err == nil {
+		return v.VertexName.Value == name, nil
+	}
+	return false, e.unknownName(name)
+}
+
+// scan returns the vertices named by the alias, schema or vertex name
+func (e *Evaluator) scan(name string) ([]*nodes.VertexInitNode, error) {
+	if name == "" {
+		return e.appManager.ReadVertices(), nil
+	}
+	if bound, ok := e.row.Scope.Lookup(name); ok {
+		return []*nodes.VertexInitNode{bound}, nil
+	}
+	if _, err := e.appManager.ReadSchema(name); err == nil {
+		return e.appManager.ReadVerticesBySchema(name), nil
+	}
+	if v, err := e.appManager.ReadVertex(name); err == nil {
+		return []*nodes.VertexInitNode{v}, nil
+	}
+	return nil, e.unknownName(name)
+}
+
+// unknownName reports a name that is neither an alias, a schema nor a
+// vertex, with the ones closest to it. The caller knows where it was written.
+func (e *Evaluator) unknownName(name string) error {
+	names := append(e.row.Scope.Aliases(), e.appManager.SchemaNames()...)
+	names = append(names, e.appManager.VertexNames()...)
+	return &PositionedError{Err: fmt.Errorf("%w: %s", UnknownName, name), Suggestions: vortexerrors.Suggest(name, names)}
+}
+
+func (e *Evaluator) VisitRelationNode(node *nodes.RelationNode) {
+	if e.row.Vertex == nil {
+		e.err = fmt.Errorf("%w: %s", UnboundVertex, exprString(node))
+		return
+	}
+
+	traversal, err := e.traversal(node.Edge.(*nodes.EdgeNode))
+	if err != nil {
+		e.err = err
+		return
+	}
+
+	candidates := e.appManager.Reach(e.row.Vertex, traversal)
+	if err := e.check(0); err != nil {
+		e.err = err
+		return
+	}
+	if candidates == nil {
+		candidates = []*nodes.VertexInitNode{}
+	}
+
+	rows, err := e.match(node.Vertex, e.row, candidates)
+	if err != nil {
+		e.err = err
+		return
+	}
+	e.matched, e.rows = true, rows
+}
+
+// traversal returns the walk through the graph described by a relation term like [1..2]Edge
+func (e *Evaluator) traversal(edge *nodes.EdgeNode) (manager.Traversal, error) {
+	traversal := manager.Traversal{
+		LowerBound: edge.LowerBound.Value,
+		UpperBound: edge.UpperBound.Value,
+		Strategy:   e.Strategy,
+		Direction:  edge.Direction,
+		Halt:       e.halt,
+	}
+	if edge.EdgeName != nil {
+		if _, err := e.appManager.ReadEdge(edge.EdgeName.Value); err != nil {
+			return traversal, misspelt(edge.EdgeName, fmt.Errorf("%w: %s", err, edge.EdgeName.Value), e.appManager.EdgeNames())
+		}
+		traversal.EdgeName = edge.EdgeName.Value
+	}
+	return traversal, nil
+}
+
+func (e *Evaluator) VisitQueryStatement(node *nodes.QueryStatementNode) {
+	e.err = fmt.Errorf("%w: QueryStatement", NotEvaluable)
+}
+
+func (e *Evaluator) VisitExplainStatement(node *nodes.ExplainStatementNode) {
+	e.err = fmt.Errorf("%w: ExplainStatement", NotEvaluable)
+}
+
+func (e *Evaluator) VisitProfileStatement(node *nodes.ProfileStatementNode) {
+	e.err = fmt.Errorf("%w: ProfileStatement", NotEvaluable)
+}
+
+func (e *Evaluator) VisitCallStatement(node *nodes.CallStatementNode) {
+	e.err = fmt.Errorf("%w: CallStatement", NotEvaluable)
+}
+
+func (e *Evaluator) VisitSumFunc(node *nodes.SumFuncNode) {
+	e.value, e.err = e.subquery(node, func() (nodes.ASTNode, error) {
+		return e.aggregateFunc(node.FunctionName, node.Args)
+	})
+}
+
+func (e *Evaluator) VisitCountFunc(node *nodes.CountFuncNode) {
+	e.value, e.err = e.subquery(node, func() (nodes.ASTNode, error) {
+		return e.aggregateFunc(node.FunctionName, node.Args)
+	})
+}
+
+func (e *Evaluator) VisitAvgFunc(node *nodes.AvgFuncNode) {
+	e.value, e.err = e.subquery(node, func() (nodes.ASTNode, error) {
+		return e.aggregateFunc(node.FunctionName, node.Args)
+	})
+}
+
+func (e *Evaluator) VisitMaxFunc(node *nodes.MaxFuncNode) {
+	e.value, e.err = e.subquery(node, func() (nodes.ASTNode, error) {
+		return e.aggregateFunc(node.FunctionName, node.Args)
+	})
+}
+
+func (e *Evaluator) VisitMinFunc(node *nodes.MinFuncNode) {
+	e.value, e.err = e.subquery(node, func() (nodes.ASTNode, error) {
+		return e.aggregateFunc(node.FunctionName, node.Args)
+	})
+}
+
+func (e *Evaluator) VisitShortestPathFunc(node *nodes.ShortestPathFuncNode) {
+	e.value, e.err = e.subquery(node, func() (nodes.ASTNode, error) {
+		paths, err := e.shortestPaths(node.Args, false)
+		if err != nil {
+			return nil, err
+		}
+		if len(paths) == 0 {
+			return &nodes.NullNode{}, nil
+		}
+		return paths[0], nil
+	})
+}
+
+func (e *Evaluator) VisitAllShortestPathsFunc(node *nodes.AllShortestPathsFuncNode) {
+	e.value, e.err = e.subquery(node, func() (nodes.ASTNode, error) {
+		paths, err := e.shortestPaths(node.Args, true)
+		if err != nil {
+			return nil, err
+		}
+		list := &nodes.ListNode{}
+		for _, path := range paths {
+			list.Values = append(list.Values, path)
+		}
+		return list, nil
+	})
+}
+
+// shortestPaths evaluates the arguments of ShortestPath(from, to, [..]Edge, .weight)
+func (e *Evaluator) shortestPaths(args []nodes.ASTNode, all bool) ([]*nodes.PathNode, error) {
+	sources, err := e.vertices(args[0])
+	if err != nil {
+		return nil, err
+	}
+	targets, err := e.vertices(args[1])
+	if err != nil {
+		return nil, err
+	}
+	traversal, err := e.traversal(args[2].(*nodes.EdgeNode))
+	if err != nil {
+		return nil, err
+	}
+	weight := ""
+	if len(args) > 3 {
+		weight = args[3].(*nodes.PropertyNode).PropertyName.Value
+	}
+
+	paths, err := e.appManager.ShortestPaths(sources, targets, traversal, weight, all)
+	if err != nil {
+		return nil, err
+	}
+	if err := e.check(0); err != nil {
+		return nil, err
+	}
+
+	var pathNodes []*nodes.PathNode
+	for _, path := range paths {
+		pathNodes = append(pathNodes, &nodes.PathNode{Vertices: path.Vertices, Edges: path.Edges})
+	}
+	return pathNodes, nil
+}
+
+// vertices returns the distinct vertices matched by the condition node
+func (e *Evaluator) vertices(node nodes.ASTNode) ([]*nodes.VertexInitNode, error) {
+	rows, err := e.match(node, e.row, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var vertices []*nodes.VertexInitNode
+	seen := make(map[*nodes.VertexInitNode]bool)
+	for _, r := range rows {
+		if r.Vertex != nil && !seen[r.Vertex] {
+			seen[r.Vertex] = true
+			vertices = append(vertices, r.Vertex)
+		}
+	}
+	return vertices, nil
+}
+
+func (e *Evaluator) VisitLengthFunc(node *nodes.LengthFuncNode) {
+	value, err := e.eval(node.Args[0], e.row)
+	if err != nil {
+		e.err = err
+		return
+	}
+
+	switch v := value.(type) {
+	case *nodes.PathNode:
+		e.value = &nodes.IntNode{Value: len(v.Edges)}
+	case *nodes.ListNode:
+		e.value = &nodes.IntNode{Value: len(v.Values)}
+	case *nodes.StringNode:
+		e.value = &nodes.IntNode{Value: lexer.Characters(v.Value)}
+	case *nodes.NullNode:
+		e.value = v
+	default:
+		e.err = &PositionedError{Span: node.Span, Err: fmt.Errorf("%w: Length of %s", TypeMismatch, typeName(value))}
+	}
+}
+
+func (e *Evaluator) VisitNodesFunc(node *nodes.NodesFuncNode) {
+	path, ok := e.path(node.FunctionName, node.Args)
+	if !ok {
+		return
+	}
+
+	list := &nodes.ListNode{}
+	for _, v := range path.Vertices {
+		list.Values = append(list.Values, v)
+	}
+	e.value = list
+}
+
+func (e *Evaluator) VisitEdgesFunc(node *nodes.EdgesFuncNode) {
+	path, ok := e.path(node.FunctionName, node.Args)
+	if !ok {
+		return
+	}
+
+	list := &nodes.ListNode{}
+	for _, edge := range path.Edges {
+		list.Values = append(list.Values, edge)
+	}
+	e.value = list
+}
+
+// path evaluates the argument of a path function, it reports false when
+// the function has no path to work on and its result is already set
+func (e *Evaluator) path(function nodes.FuncType, args []nodes.ASTNode) (*nodes.PathNode, bool) {
+	value, err := e.eval(args[0], e.row)
+	if err != nil {
+		e.err = err
+		return nil, false
+	}
+
+	switch v := value.(type) {
+	case *nodes.PathNode:
+		return v, true
+	case *nodes.NullNode:
+		e.value = v
+		return nil, false
+	default:
+		e.err = fmt.Errorf("%w: %s of %s", TypeMismatch, function, typeName(value))
+		return nil, false
+	}
+}
+
+// subqueryKey identifies the value of a subquery for one binding of the
+// vertex it is scoped to and of the outer aliases it uses
+type subqueryKey struct {
+	node     nodes.ASTNode
+	vertex   *nodes.VertexInitNode
+	bindings string
+}
+
+// subquery returns the value computed by evaluate for the subquery node.
+//
+// The value of a subquery only depends on the vertex it is scoped to and on
+// the outer aliases it uses, so it is computed once per binding of those
+// while a query runs. Inside a Group By the value depends on the group and
+// is computed every time.
+func (e *Evaluator) subquery(node nodes.ASTNode, evaluate func() (nodes.ASTNode, error)) (nodes.ASTNode, error) {
+	if e.subqueries == nil || e.group != nil {
+		return evaluate()
+	}
+
+	bindings := new(strings.Builder)
+	for _, name := range e.names(node) {
+		if v, ok := e.row.Scope.Lookup(name); ok {
+			bindings.WriteString(strconv.Itoa(e.appManager.Position(v)))
+		}
+		bindings.WriteByte(0)
+	}
+	key := subqueryKey{node: node, vertex: e.row.Vertex, bindings: bindings.String()}
+	if value, ok := e.subqueries[key]; ok {
+		return value, nil
+	}
+
+	value, err := evaluate()
+	if err != nil {
+		return nil, err
+	}
+	e.subqueries[key] = value
+	return value, nil
+}
+
+// names returns the distinct names a node refers to, any of them can be an
+// alias bound outside of the node
+func (e *Evaluator) names(node nodes.ASTNode) []string {
+	if names, ok := e.nodeNames[node]; ok {
+		return names
+	}
+
+	var names []string
+	seen := make(map[string]bool)
+	add := func(name string) {
+		if name != "" && !seen[name] {
+			seen[name] = true
+			names = append(names, name)
+		}
+	}
+	walk(node, func(n nodes.ASTNode) {
+		switch n := n.(type) {
+		case *nodes.PropertyNode:
+			if n.Alias != nil {
+				add(n.Alias.Value)
+			}
+		case *nodes.VertexTermNode:
+			name, _ := termNames(n)
+			add(name)
+		}
+	})
+
+	if e.nodeNames == nil {
+		e.nodeNames = make(map[nodes.ASTNode][]string)
+	}
+	e.nodeNames[node] = names
+	return names
+}
+
+// aggregateFunc collects the values the aggregate function runs over and applies it.
+//
+// Inside a Group By the first argument is evaluated on every row of the group.
+// Otherwise the first argument is matched as a condition, eg: Sum([]FriendsWith Person, .salary),
+// and the optional second argument is evaluated on every matched vertex.
+func (e *Evaluator) aggregateFunc(function nodes.FuncType, args []nodes.ASTNode) (nodes.ASTNode, error) {
+	var values []nodes.ASTNode
+	if e.group != nil {
+		// nested aggregates run over the matches of their own arguments
+		group := e.group
+		e.group = nil
+		defer func() { e.group = group }()
+
+		for _, r := range group {
+			if err := e.check(0); err != nil {
+				return nil, err
+			}
+			value, err := e.eval(args[0], r)
+			if err != nil {
+				return nil, err
+			}
+			values = append(values, value)
+		}
+	} else {
+		rows, err := e.match(args[0], e.row, nil)
+		if err != nil {
+			return nil, err
+		}
+		for _, r := range rows {
+			if err := e.check(0); err != nil {
+				return nil, err
+			}
+			if len(args) < 2 {
+				values = append(values, r.Vertex)
+				continue
+			}
+			value, err := e.eval(args[1], r)
+			if err != nil {
+				return nil, err
+			}
+			values = append(values, value)
+		}
+	}
+
+	// only a count and a sum have a value over no values
+	if len(values) == 0 && function != nodes.CountFunc && function != nodes.SumFunc {
+		return nil, fmt.Errorf("%w: %s", EmptyAggregate, function)
+	}
+
+	switch function {
+	case nodes.CountFunc:
+		return &nodes.IntNode{Value: len(values)}, nil
+	case nodes.SumFunc:
+		return sum(values)
+	case nodes.AvgFunc:
+		total, err := sum(values)
+		if err != nil {
+			return nil, err
+		}
+		return &nodes.FloatNode{Value: toFloat(total) / float64(len(values))}, nil
+	case nodes.MaxFunc, nodes.MinFunc:
+		operator := lexer.Token{Type: lexer.TokenGreaterThan, Value: ">"}
+		if function == nodes.MinFunc {
+			operator = lexer.Token{Type: lexer.TokenLessThan, Value: "<"}
+		}
+		best := values[0]
+		for _, value := range values[1:] {
+			better, err := binaryOperation(operator, value, best)
+			if err != nil {
+				return nil, err
+			}
+			if better.(*nodes.BoolNode).Value {
+				best = value
+			}
+		}
+		return best, nil
+	default:
+		return nil, fmt.Errorf("%w: %s", NotEvaluable, function)
+	}
+}
+
+func sum(values []nodes.ASTNode) (nodes.ASTNode, error) {
+	var total nodes.ASTNode = &nodes.IntNode{Value: 0}
+	plus := lexer.Token{Type: lexer.TokenPlus, Value: "+"}
+	for _, value := range values {
+		var err error
+		if total, err = binaryOperation(plus, total, value); err != nil {
+			return nil, err
+		}
+	}
+	return total, nil
+}
+
+func toFloat(value nodes.ASTNode) float64 {
+	switch v := value.(type) {
+	case *nodes.IntNode:
+		return float64(v.Value)
+	case *nodes.FloatNode:
+		return v.Value
+	default:
+		return 0
+	}
+}
+
+// binaryOperation applies an arithmetic or comparison operator to two values
+func binaryOperation(operator lexer.Token, left, right nodes.ASTNode) (nodes.ASTNode, error) {
+	mismatch := fmt.Errorf("%w: %s %s %s", TypeMismatch, typeName(left), operator.Value, typeName(right))
+
+	// nothing compares to a missing value
+	_, leftNull := left.(*nodes.NullNode)
+	_, rightNull := right.(*nodes.NullNode)
+	if leftNull || rightNull {
+		switch operator.Type {
+		case lexer.TokenPlus, lexer.TokenMinus, lexer.TokenMultiply, lexer.TokenDivide:
+			return &nodes.NullNode{}, nil
+		}
+		return &nodes.BoolNode{Value: false}, nil
+	}
+
+	switch l := left.(type) {
+	case *nodes.IntNode:
+		if r, ok := right.(*nodes.IntNode); ok {
+			switch operator.Type {
+			case lexer.TokenPlus:
+				return &nodes.IntNode{Value: l.Value + r.Value}, nil
+			case lexer.TokenMinus:
+				return &nodes.IntNode{Value: l.Value - r.Value}, nil
+			case lexer.TokenMultiply:
+				return &nodes.IntNode{Value: l.Value * r.Value}, nil
+			case lexer.TokenDivide:
+				if r.Value == 0 {
+					return nil, DivisionByZero
+				}
+				return &nodes.IntNode{Value: l.Value / r.Value}, nil
+			}
+			return compare(operator, l.Value-r.Value)
+		}
+		if _, ok := right.(*nodes.FloatNode); ok {
+			return binaryOperation(operator, &nodes.FloatNode{Value: float64(l.Value)}, right)
+		}
+	case *nodes.FloatNode:
+		var r float64
+		switch rv := right.(type) {
+		case *nodes.FloatNode:
+			r = rv.Value
+		case *nodes.IntNode:
+			r = float64(rv.Value)
+		default:
+			return nil, mismatch
+		}
+		switch operator.Type {
+		case lexer.TokenPlus:
+			return &nodes.FloatNode{Value: l.Value + r}, nil
+		case lexer.TokenMinus:
+			return &nodes.FloatNode{Value: l.Value - r}, nil
+		case lexer.TokenMultiply:
+			return &nodes.FloatNode{Value: l.Value * r}, nil
+		case lexer.TokenDivide:
+			if r == 0 {
+				return nil, DivisionByZero
+			}
+			return &nodes.FloatNode{Value: l.Value / r}, nil
+		}
+		if l.Value < r {
+			return compare(operator, -1)
+		} else if l.Value > r {
+			return compare(operator, 1)
+		}
+		return compare(operator, 0)
+	case *nodes.StringNode:
+		if r, ok := right.(*nodes.StringNode); ok {
+			if operator.Type == lexer.TokenPlus {
+				return &nodes.StringNode{Value: l.Value + r.Value}, nil
+			}
+			return compare(operator, strings.Compare(l.Value, r.Value))
+		}
+	case *nodes.VertexInitNode:
+		if r, ok := right.(*nodes.VertexInitNode); ok {
+			if operator.Type == lexer.TokenEqual {
+				return &nodes.BoolNode{Value: l == r}, nil
+			}
+			if operator.Type == lexer.TokenNotEqual {
+				return &nodes.BoolNode{Value: l != r}, nil
+			}
+		}
+	}
+
+	return nil, mismatch
+}
+
+// compare turns the sign of a three way comparison into the result of the operator
+func compare(operator lexer.Token, cmp int) (nodes.ASTNode, error) {
+	switch operator.Type {
+	case lexer.TokenLessThan:
+		return &nodes.BoolNode{Value: cmp < 0}, nil
+	case lexer.TokenLessThanEqual:
+		return &nodes.BoolNode{Value: cmp <= 0}, nil
+	case lexer.TokenGreaterThan:
+		return &nodes.BoolNode{Value: cmp > 0}, nil
+	case lexer.TokenGreaterThanEqual:
+		return &nodes.BoolNode{Value: cmp >= 0}, nil
+	case lexer.TokenEqual:
+		return &nodes.BoolNode{Value: cmp == 0}, nil
+	case lexer.TokenNotEqual:
+		return &nodes.BoolNode{Value: cmp != 0}, nil
+	default:
+		return nil, fmt.Errorf("%w: unknown operator %s", TypeMismatch, operator.Value)
+	}
+}
+
+func typeName(value nodes.ASTNode) string {
+	switch value.(type) {
+	case *nodes.IntNode:
+		return "int"
+	case *nodes.FloatNode:
+		return "float"
+	case *nodes.StringNode:
+		return "string"
+	case *nodes.BoolNode:
+		return "bool"
+	case *nodes.VertexInitNode:
+		return "vertex"
+	case *nodes.EdgeDefNode:
+		return "edge"
+	case *nodes.PathNode:
+		return "path"
+	case *nodes.ListNode:
+		return "list"
+	case *nodes.NullNode:
+		return "null"
+	default:
+		return "unknown"
+	}
+}
+
+func hasAggregate(expressions []nodes.ASTNode) bool {
+	for _, expression := range expressions {
+		switch node := expression.(type) {
+		case *nodes.SumFuncNode, *nodes.CountFuncNode, *nodes.AvgFuncNode, *nodes.MaxFuncNode, *nodes.MinFuncNode:
+			return true
+		case *nodes.BinaryNode:
+			if hasAggregate([]nodes.ASTNode{node.LeftChild, node.RightChild}) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func groupKey(values []nodes.ASTNode) string {
+	key := new(strings.Builder)
+	for _, value := range values {
+		key.WriteString(typeName(value))
+		key.WriteByte(0)
+		key.WriteString(FormatValue(value))
+		key.WriteByte(0)
+	}
+	return key.String()
+}
+
+// FormatValue returns the printable form of a value produced by the evaluator
+func FormatValue(value nodes.ASTNode) string {
+	switch v := value.(type) {
+	case *nodes.IntNode:
+		return strconv.Itoa(v.Value)
+	case *nodes.FloatNode:
+		return strconv.FormatFloat(v.Value, 'f', -1, 64)
+	case *nodes.StringNode:
+		return v.Value
+	case *nodes.BoolNode:
+		return strconv.FormatBool(v.Value)
+	case *nodes.VertexInitNode:
+		return v.VertexName.Value
+	case *nodes.EdgeDefNode:
+		return v.EdgeName.Value
+	case *nodes.NullNode:
+		return "null"
+	case *nodes.ListNode:
+		values := make([]string, 0, len(v.Values))
+		for _, value := range v.Values {
+			values = append(values, FormatValue(value))
+		}
+		return "[" + strings.Join(values, ", ") + "]"
+	case *nodes.PathNode:
+		// Ann -[Knows]-> Bob -[Knows]-> Cid
+		path := new(strings.Builder)
+		for i, vertex := range v.Vertices {
+			if i > 0 {
+				path.WriteString(" -[" + v.Edges[i-1].EdgeName.Value + "]-> ")
+			}
+			path.WriteString(vertex.VertexName.Value)
+		}
+		return path.String()
+	default:
+		return ""
+	}
+}
+
+func exprStrings(expressions []nodes.ASTNode) []string {
+	names := make([]string, 0, len(expressions))
+	for _, expression := range expressions {
+		names = append(names, exprString(expression))
+	}
+	return names
+}
+
+// exprString returns a short source like representation of an expression, used to name columns
+func exprString(node nodes.ASTNode) string {
+	switch n := node.(type) {
+	case *nodes.IntNode:
+		return strconv.Itoa(n.Value)
+	case *nodes.StringNode:
+		return strconv.Quote(n.Value)
+	case *nodes.ParameterNode:
+		return "$" + n.Name.Value
+	case *nodes.PropertyNode:
+		if n.Alias != nil {
+			return n.Alias.Value + "." + n.PropertyName.Value
+		}
+		return "." + n.PropertyName.Value
+	case *nodes.BinaryNode:
+		return exprString(n.LeftChild) + " " + n.Operator.Value + " " + exprString(n.RightChild)
+	case *nodes.VertexTermNode:
+		if n.Vertex.Alias != nil && n.Vertex.Alias.Value != "" {
+			return n.Vertex.Alias.Value
+		}
+		if n.Vertex.VertexName != nil {
+			return n.Vertex.VertexName.Value
+		}
+		return "()"
+	case *nodes.RelationNode:
+		return exprString(n.Edge) + " " + exprString(n.Vertex)
+	case *nodes.EdgeNode:
+		bounds := ""
+		if n.LowerBound.Value != 1 || n.UpperBound.Value != 1 {
+			if n.LowerBound.Value > 0 || n.UpperBound.Value == n.LowerBound.Value {
+				bounds = strconv.Itoa(n.LowerBound.Value)
+			}
+			if n.UpperBound.Value != n.LowerBound.Value {
+				bounds += ".."
+				if n.UpperBound.Value != math.MaxInt {
+					bounds += strconv.Itoa(n.UpperBound.Value)
+				}
+			}
+		}
+		direction := ""
+		if n.Direction == nodes.Incoming || n.Direction == nodes.AnyDirection {
+			direction = n.Direction.String()
+		}
+		if n.EdgeName != nil {
+			return "[" + bounds + "]" + direction + n.EdgeName.Value
+		}
+		return "[" + bounds + "]" + direction + "()"
+	case *nodes.SumFuncNode:
+		return funcString(n.FunctionName, n.Args)
+	case *nodes.CountFuncNode:
+		return funcString(n.FunctionName, n.Args)
+	case *nodes.AvgFuncNode:
+		return funcString(n.FunctionName, n.Args)
+	case *nodes.MaxFuncNode:
+		return funcString(n.FunctionName, n.Args)
+	case *nodes.MinFuncNode:
+		return funcString(n.FunctionName, n.Args)
+	case *nodes.ShortestPathFuncNode:
+		return funcString(n.FunctionName, n.Args)
+	case *nodes.AllShortestPathsFuncNode:
+		return funcString(n.FunctionName, n.Args)
+	case *nodes.LengthFuncNode:
+		return funcString(n.FunctionName, n.Args)
+	case *nodes.NodesFuncNode:
+		return funcString(n.FunctionName, n.Args)
+	case *nodes.EdgesFuncNode:
+		return funcString(n.FunctionName, n.Args)
+	default:
+		return "?"
+	}
+}
+
+func funcString(function nodes.FuncType, args []nodes.ASTNode) string {
+	return function.String() + "(" + strings.Join(exprStrings(args), ", ") + ")"
+}
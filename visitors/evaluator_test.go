@@ -0,0 +1,318 @@
+package visitors
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/Jintumoni/vortex/lexer"
+	"github.com/Jintumoni/vortex/manager"
+	"github.com/Jintumoni/vortex/nodes"
+	"github.com/Jintumoni/vortex/parser"
+	"github.com/stretchr/testify/assert"
+)
+
+const testGraph = `
+Schema Person {
+  name string
+  age  int
+}
+Schema City {
+  name string
+}
+Schema Country {
+  name string
+}
+
+Vertex Ann Person { .name = "Ann" .age = 30 }
+Vertex Bob Person { .name = "Bob" .age = 20 }
+Vertex Cid Person { .name = "Cid" .age = 41 }
+Vertex London City { .name = "London" }
+Vertex Paris City { .name = "Paris" }
+Vertex England Country { .name = "England" }
+Vertex UK Country { .name = "UK" }
+
+Edge LivesIn OneWay
+Edge FriendsWith TwoWay
+Edge Within OneWay
+Edge Road TwoWay
+
+Relation LivesIn { Ann London }
+Relation LivesIn { Bob London }
+Relation LivesIn { Cid Paris }
+Relation FriendsWith { Ann Bob }
+Relation Within { London England }
+Relation Within { England UK }
+Relation Road { London Paris .distance = 340 }
+`
+
+// runQuery loads testGraph and evaluates the query written in src
+func runQuery(t *testing.T, src string) (*ResultSet, error) {
+	p := parser.NewParser(lexer.NewLexer(strings.NewReader(testGraph + src)))
+	root, err := p.Parse()
+	assert.NoError(t, err)
+
+	appManager := manager.NewAppManager()
+	var query *nodes.QueryStatementNode
+	for _, node := range root.(*nodes.ProgramStatementNode).Children {
+		switch n := node.(type) {
+		case *nodes.SchemaDefNode:
+			assert.NoError(t, appManager.WriteSchema(n))
+		case *nodes.EdgeDefNode:
+			assert.NoError(t, appManager.WriteEdge(n))
+		case *nodes.VertexInitNode:
+			assert.NoError(t, appManager.WriteVertex(n))
+		case *nodes.RelationInitNode:
+			assert.NoError(t, appManager.WriteRelation(n))
+		case *nodes.CallStatementNode:
+			_, err := NewEvaluator(appManager).Call(n)
+			assert.NoError(t, err)
+		case *nodes.QueryStatementNode:
+			query = n
+		}
+	}
+
+	return NewEvaluator(appManager).Evaluate(query)
+}
+
+// formatRows returns the printable form of every row in the result
+func formatRows(result *ResultSet) [][]string {
+	var rows [][]string
+	for _, row := range result.Rows {
+		var values []string
+		for _, value := range row {
+			values = append(values, FormatValue(value))
+		}
+		rows = append(rows, values)
+	}
+	return rows
+}
+
+func TestEvaluateVertexTerm(t *testing.T) {
+	result, err := runQuery(t, `Query Person { .age > 25 }`)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"Person"}, result.Columns)
+	assert.Equal(t, [][]string{{"Ann"}, {"Cid"}}, formatRows(result))
+}
+
+func TestEvaluateRelationWithAlias(t *testing.T) {
+	result, err := runQuery(t, `Query Person as A { []FriendsWith Person { .age < A.age } } Return A.name, .age`)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"A.name", ".age"}, result.Columns)
+	assert.Equal(t, [][]string{{"Ann", "30"}}, formatRows(result))
+}
+
+func TestEvaluateSumOverSubquery(t *testing.T) {
+	result, err := runQuery(t, `Query Person { Sum([]FriendsWith Person, .age) > 25 }`)
+	assert.NoError(t, err)
+	assert.Equal(t, [][]string{{"Bob"}}, formatRows(result))
+}
+
+func TestEvaluateGroupBy(t *testing.T) {
+	result, err := runQuery(t, `Query Person as P { []LivesIn City as C } Group By C.name Return C.name, Count(P), Avg(P.age)`)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"C.name", "Count(P)", "Avg(P.age)"}, result.Columns)
+	assert.Equal(t, [][]string{{"London", "2", "25"}, {"Paris", "1", "41"}}, formatRows(result))
+}
+
+func TestEvaluateGroupByHaving(t *testing.T) {
+	result, err := runQuery(t, `Query Person as P { []LivesIn City as C } Group By C.name Having Count(P) > 1 Return C.name, Sum(P.age)`)
+	assert.NoError(t, err)
+	assert.Equal(t, [][]string{{"London", "50"}}, formatRows(result))
+}
+
+func TestEvaluateAggregateWithoutGroupBy(t *testing.T) {
+	result, err := runQuery(t, `Query Person as P { .age > 100 } Return Count(P), Sum(P.age)`)
+	assert.NoError(t, err)
+	assert.Equal(t, [][]string{{"0", "0"}}, formatRows(result))
+}
+
+func TestEvaluateAggregateOverNoValues(t *testing.T) {
+	for _, function := range []string{"Avg", "Max", "Min"} {
+		_, err := runQuery(t, `Query Person as P { .age > 100 } Return `+function+`(P.age)`)
+		assert.ErrorIs(t, err, EmptyAggregate, function)
+	}
+
+	// a person without friends has no average age of friends, not an average of 0
+	_, err := runQuery(t, `Query Person { Avg([]FriendsWith Person, .age) > 0 }`)
+	assert.ErrorIs(t, err, EmptyAggregate)
+}
+
+func TestEvaluateVariableLengthRelation(t *testing.T) {
+	result, err := runQuery(t, `Query City { [..]Within Country { .name = "UK" } }`)
+	assert.NoError(t, err)
+	assert.Equal(t, [][]string{{"London"}}, formatRows(result))
+
+	result, err = runQuery(t, `Query City { [2]Within Country { .name = "England" } }`)
+	assert.NoError(t, err)
+	assert.Empty(t, result.Rows)
+
+	result, err = runQuery(t, `Query Person { [1..2]() City }`)
+	assert.NoError(t, err)
+	assert.Equal(t, [][]string{{"Ann"}, {"Bob"}, {"Cid"}}, formatRows(result))
+}
+
+func TestEvaluateRelationWithDirection(t *testing.T) {
+	result, err := runQuery(t, `Query City { []<-LivesIn Person { .age > 25 } }`)
+	assert.NoError(t, err)
+	assert.Equal(t, [][]string{{"London"}, {"Paris"}}, formatRows(result))
+
+	result, err = runQuery(t, `Query London as C { []<-LivesIn Person as P } Return P.name`)
+	assert.NoError(t, err)
+	assert.Equal(t, [][]string{{"Ann"}, {"Bob"}}, formatRows(result))
+
+	result, err = runQuery(t, `Query Country { []<->Within England }`)
+	assert.NoError(t, err)
+	assert.Equal(t, [][]string{{"UK"}}, formatRows(result))
+
+	result, err = runQuery(t, `Query Person { []->LivesIn Paris }`)
+	assert.NoError(t, err)
+	assert.Equal(t, [][]string{{"Cid"}}, formatRows(result))
+}
+
+func TestEvaluateShortestPath(t *testing.T) {
+	result, err := runQuery(t, `Query ShortestPath(Ann, Paris, [..]())`)
+	assert.NoError(t, err)
+	assert.Equal(t, [][]string{{"Ann -[LivesIn]-> London -[Road]-> Paris"}}, formatRows(result))
+
+	result, err = runQuery(t, `Query Person as P { .name = "Ann" } Return Length(ShortestPath(P, UK, [..]())), Edges(ShortestPath(P, UK, [..]()))`)
+	assert.NoError(t, err)
+	assert.Equal(t, [][]string{{"3", "[LivesIn, Within, Within]"}}, formatRows(result))
+
+	result, err = runQuery(t, `Query ShortestPath(UK, Cid, [..]())`)
+	assert.NoError(t, err)
+	assert.Equal(t, [][]string{{"null"}}, formatRows(result))
+}
+
+func TestEvaluateLengthOfString(t *testing.T) {
+	// characters are counted like the columns of the source, a combining mark is
+	// part of the character before it
+	result, err := runQuery(t, `Query Person { .name = "Ann" } Return Length(.name), Length("नमस्ते"), Length("Ju\u{308}rgen")`)
+	assert.NoError(t, err)
+	assert.Equal(t, [][]string{{"3", "4", "6"}}, formatRows(result))
+}
+
+func TestEvaluateAllShortestPaths(t *testing.T) {
+	result, err := runQuery(t, `Query AllShortestPaths(Person, London, []LivesIn)`)
+	assert.NoError(t, err)
+	assert.Equal(t, [][]string{{"Ann -[LivesIn]-> London"}, {"Bob -[LivesIn]-> London"}}, formatRows(result))
+}
+
+func TestEvaluateCallWrite(t *testing.T) {
+	result, err := runQuery(t, `Call ConnectedComponents(Person, FriendsWith) Write .component
+Query Person as P { .component = 0 }`)
+	assert.NoError(t, err)
+	assert.Equal(t, [][]string{{"Ann"}, {"Bob"}}, formatRows(result))
+}
+
+func TestEvaluateUnknownName(t *testing.T) {
+	_, err := runQuery(t, `Query Persons`)
+	assert.ErrorIs(t, err, UnknownName)
+}
+
+func TestEvaluateSuggestsMisspeltNames(t *testing.T) {
+	tests := []struct {
+		src string
+		err error
+		msg string
+	}{
+		{`Query Persons`, UnknownName, `Unknown alias, schema or vertex: Persons; did you mean "Person"?`},
+		{`Query Person as P { .age > p.age }`, UnknownName, `Unknown alias, schema or vertex: p; did you mean "P"?`},
+		{`Query Person { []FriendWith Person }`, manager.EdgeDoesNotExist, `Edge missing: FriendWith; did you mean "FriendsWith"?`},
+		{`Query Person { .nmae = "Ann" }`, manager.PropertyDoesNotExist, `Property missing: nmae of Ann; did you mean "name"?`},
+		{`Query Person { .height > 1 }`, manager.PropertyDoesNotExist, `Property missing: height of Ann`},
+	}
+	for _, test := range tests {
+		_, err := runQuery(t, test.src)
+		assert.ErrorIs(t, err, test.err)
+		assert.True(t, strings.HasSuffix(err.Error(), test.msg), err.Error())
+	}
+}
+
+func TestEvaluateCorrelatedSubquery(t *testing.T) {
+	result, err := runQuery(t, `Query Person as A { .name = "Ann" and []FriendsWith Person { []FriendsWith A } }`)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"A"}, result.Columns)
+	assert.Equal(t, [][]string{{"Ann"}}, formatRows(result))
+
+	result, err = runQuery(t, `Query Person as A { Count([]LivesIn City { []<-LivesIn Person { .age > A.age } }) > 0 }`)
+	assert.NoError(t, err)
+	assert.Equal(t, [][]string{{"Bob"}}, formatRows(result))
+}
+
+func TestEvaluateShadowedAlias(t *testing.T) {
+	// inside the nested term A is the friend, outside it is the outer person again
+	result, err := runQuery(t, `Query Person as A { []FriendsWith Person as A { A.age < 25 } } Return A.name`)
+	assert.NoError(t, err)
+	assert.Equal(t, [][]string{{"Ann"}}, formatRows(result))
+}
+
+func TestEvaluateUnresolvedAliasPosition(t *testing.T) {
+	_, err := runQuery(t, `Query Person { .age > B.age }`)
+	assert.ErrorIs(t, err, UnknownName)
+
+	var positioned *PositionedError
+	assert.ErrorAs(t, err, &positioned)
+	assert.Equal(t, strings.Count(testGraph, "\n"), positioned.Span.Start.Row)
+	assert.Equal(t, 22, positioned.Span.Start.Col)
+	assert.Equal(t, 23, positioned.Span.End.Col)
+}
+
+func TestEvaluateSubqueryIsMemoised(t *testing.T) {
+	appManager, _ := loadQuery(t, `Query Person`)
+	ann, _ := appManager.ReadVertex("Ann")
+	bob, _ := appManager.ReadVertex("Bob")
+
+	e := NewEvaluator(appManager)
+	e.subqueries = make(map[subqueryKey]nodes.ASTNode)
+	node := &nodes.CountFuncNode{FunctionName: nodes.CountFunc, Args: []nodes.ASTNode{
+		&nodes.PropertyNode{Alias: &nodes.StringNode{Value: "A"}, PropertyName: &nodes.StringNode{Value: "age"}},
+	}}
+
+	evaluations := 0
+	evaluate := func() (nodes.ASTNode, error) {
+		evaluations++
+		return &nodes.IntNode{Value: evaluations}, nil
+	}
+
+	for _, row := range []*Row{
+		(&Row{}).bind("A", ann).scope(bob),
+		(&Row{}).bind("A", ann).bind("B", bob).scope(bob),
+		(&Row{}).bind("A", bob).scope(bob),
+	} {
+		e.row = row
+		_, err := e.subquery(node, evaluate)
+		assert.NoError(t, err)
+	}
+	// B is not used by the subquery, only a new binding of A evaluates it again
+	assert.Equal(t, 2, evaluations)
+}
+
+func TestEvaluateParameters(t *testing.T) {
+	appManager, query := loadQuery(t, `Query Person { .age > $age } Return .name, $suffix`)
+	plan, err := NewPlanner(appManager).Plan(query)
+	assert.NoError(t, err)
+
+	// the same plan runs with every set of parameters
+	e := NewEvaluator(appManager)
+	e.Parameters, err = Parameters(map[string]any{"age": 25, "suffix": "!"})
+	assert.NoError(t, err)
+	result, err := e.Run(plan)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{".name", "$suffix"}, result.Columns)
+	assert.Equal(t, [][]string{{"Ann", "!"}, {"Cid", "!"}}, formatRows(result))
+
+	e.Parameters, err = Parameters(map[string]any{"age": int64(35), "suffix": "?"})
+	assert.NoError(t, err)
+	result, err = e.Run(plan)
+	assert.NoError(t, err)
+	assert.Equal(t, [][]string{{"Cid", "?"}}, formatRows(result))
+}
+
+func TestEvaluateMissingParameter(t *testing.T) {
+	_, err := runQuery(t, `Query Person { .age > $age }`)
+	assert.ErrorIs(t, err, MissingParameter)
+
+	var positioned *PositionedError
+	assert.ErrorAs(t, err, &positioned)
+	assert.Equal(t, 22, positioned.Span.Start.Col)
+}
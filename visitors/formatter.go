@@ -0,0 +1,525 @@
+package visitors
+
+import (
+	"bytes"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/Jintumoni/vortex/lexer"
+	"github.com/Jintumoni/vortex/nodes"
+)
+
+// the indentation of a block, eg: the properties of a schema
+const indentation = "    "
+
+// Formatter writes an AST back as canonical Vortex source: blocks indented
+// with four spaces, the types of the properties of a schema and the values
+// of a vertex aligned, the conditions of a vertex term one per line and the
+// bounds of the edges in their shortest form. The comments of the source are
+// written back before the part of the AST that follows them, or at the end
+// of the line when they followed something on their row.
+type Formatter struct {
+	comments []lexer.Comment // not written yet, in order
+	buffer   bytes.Buffer
+	indent   int
+	line     bool // the current line holds something
+	lastRow  int  // row of the source of the last thing written, -1 before the first one
+}
+
+// NewFormatter returns a formatter writing back the comments of the source,
+// read by a lexer with KeepComments set
+func NewFormatter(comments []lexer.Comment) *Formatter {
+	return &Formatter{comments: comments, lastRow: -1}
+}
+
+// Format returns the source of a program or of a statement, ending with a
+// newline unless it is empty
+func (f *Formatter) Format(node nodes.ASTNode) []byte {
+	if _, ok := node.(*nodes.ProgramStatementNode); !ok {
+		node = &nodes.ProgramStatementNode{Children: []nodes.ASTNode{node}}
+	}
+	node.Accept(f)
+	return f.buffer.Bytes()
+}
+
+func (f *Formatter) write(s string) {
+	if !f.line {
+		f.buffer.WriteString(strings.Repeat(indentation, f.indent))
+		f.line = true
+	}
+	f.buffer.WriteString(s)
+}
+
+func (f *Formatter) newline() {
+	if f.line {
+		f.buffer.WriteByte('\n')
+		f.line = false
+	}
+}
+
+// mark records the row of the source of what was just written, a comment
+// on the same row is written after it
+func (f *Formatter) mark(span nodes.Span) {
+	if !span.IsZero() {
+		f.lastRow = span.End.Row
+	}
+}
+
+// separate starts a new line, after a blank one when the source has one
+// between the last statement and the next one starting at row
+func (f *Formatter) separate(row int) {
+	f.newline()
+	if f.indent == 0 && f.lastRow >= 0 && row > f.lastRow+1 {
+		f.buffer.WriteByte('\n')
+	}
+}
+
+// flush writes the comments before the position, nothing when it is unknown
+func (f *Formatter) flush(p nodes.Position) {
+	if p == (nodes.Position{}) {
+		return
+	}
+	for len(f.comments) > 0 {
+		c := f.comments[0]
+		if c.Row > p.Row || c.Row == p.Row && c.Col >= p.Col {
+			return
+		}
+		f.comments = f.comments[1:]
+
+		if f.line && c.Row == f.lastRow {
+			f.write(" " + c.Text)
+		} else {
+			f.separate(c.Row)
+			f.write(c.Text)
+		}
+		if strings.HasPrefix(c.Text, "//") {
+			f.newline()
+		}
+		f.lastRow = c.EndRow
+	}
+}
+
+// startLine writes the comments before the node and starts its line
+func (f *Formatter) startLine(node nodes.ASTNode) {
+	f.flush(node.GetSpan().Start)
+	f.newline()
+}
+
+// open starts a block after its header
+func (f *Formatter) open(header nodes.Span) {
+	f.write(" {")
+	f.mark(header)
+	f.indent++
+}
+
+// close ends the block of a node, an empty block is written as {}
+func (f *Formatter) close(node nodes.ASTNode, empty bool) {
+	f.flush(node.GetSpan().End)
+	f.indent--
+	if !empty || !f.line {
+		f.newline()
+	}
+	f.write("}")
+	f.mark(node.GetSpan())
+}
+
+func (f *Formatter) list(expressions []nodes.ASTNode) {
+	for i, node := range expressions {
+		if i > 0 {
+			f.write(", ")
+		}
+		node.Accept(f)
+	}
+}
+
+func (f *Formatter) call(name string, args []nodes.ASTNode) {
+	f.write(name + "(")
+	f.list(args)
+	f.write(")")
+}
+
+// precedence returns how tightly an operator binds its operands, 0 for the
+// nodes that are not binary operations
+func precedence(node nodes.ASTNode) int {
+	binary, ok := node.(*nodes.BinaryNode)
+	if !ok {
+		return 0
+	}
+	switch binary.Operator.Type {
+	case lexer.TokenAnd, lexer.TokenOr:
+		return 1
+	case lexer.TokenPlus, lexer.TokenMinus:
+		return 3
+	case lexer.TokenMultiply, lexer.TokenDivide:
+		return 4
+	default:
+		return 2
+	}
+}
+
+// operand writes an operand of an operation of the precedence, within
+// parentheses when it binds less tightly. The operations are left
+// associative, so a right operand of the same precedence needs them too.
+func (f *Formatter) operand(node nodes.ASTNode, parent int, right bool) {
+	p := precedence(node)
+	if p != 0 && (p < parent || right && p == parent) {
+		f.write("(")
+		node.Accept(f)
+		f.write(")")
+		return
+	}
+	node.Accept(f)
+}
+
+// quote returns a string literal holding s
+func quote(s string) string {
+	buffer := new(strings.Builder)
+	buffer.WriteByte('"')
+	for _, r := range s {
+		switch r {
+		case '"':
+			buffer.WriteString(`\"`)
+		case '\\':
+			buffer.WriteString(`\\`)
+		case '\n':
+			buffer.WriteString(`\n`)
+		case '\t':
+			buffer.WriteString(`\t`)
+		case '\r':
+			buffer.WriteString(`\r`)
+		default:
+			if unicode.IsPrint(r) {
+				buffer.WriteRune(r)
+			} else {
+				fmt.Fprintf(buffer, `\u{%X}`, r)
+			}
+		}
+	}
+	buffer.WriteByte('"')
+	return buffer.String()
+}
+
+// pad returns the spaces aligning a name with the longest one
+func pad(name string, width int) string {
+	return strings.Repeat(" ", width-utf8.RuneCountInString(name))
+}
+
+func (f *Formatter) VisitProgramNode(node *nodes.ProgramStatementNode) {
+	for _, child := range node.Children {
+		f.flush(child.GetSpan().Start)
+		f.separate(child.GetSpan().Start.Row)
+		child.Accept(f)
+		f.mark(child.GetSpan())
+	}
+	f.flush(nodes.Position{Row: math.MaxInt})
+	f.newline()
+}
+
+func (f *Formatter) VisitIntNode(node *nodes.IntNode) {
+	f.write(strconv.Itoa(node.Value))
+}
+
+func (f *Formatter) VisitFloatNode(node *nodes.FloatNode) {
+	f.write(strconv.FormatFloat(node.Value, 'f', -1, 64))
+}
+
+func (f *Formatter) VisitBoolNode(node *nodes.BoolNode) {
+	f.write(strconv.FormatBool(node.Value))
+}
+
+func (f *Formatter) VisitNullNode(node *nodes.NullNode) {
+	f.write("null")
+}
+
+func (f *Formatter) VisitListNode(node *nodes.ListNode) {
+	f.write("[")
+	f.list(node.Values)
+	f.write("]")
+}
+
+func (f *Formatter) VisitParameterNode(node *nodes.ParameterNode) {
+	f.write("$" + node.Name.Value)
+}
+
+func (f *Formatter) VisitPathNode(node *nodes.PathNode) {
+	f.write(exprString(node))
+}
+
+func (f *Formatter) VisitStringNode(node *nodes.StringNode) {
+	f.write(quote(node.Value))
+}
+
+func (f *Formatter) VisitSchemaDefNode(node *nodes.SchemaDefNode) {
+	f.write("Schema " + node.SchemaName.Value)
+	f.open(node.SchemaName.Span)
+
+	width := 0
+	for _, p := range node.Properties {
+		width = max(width, utf8.RuneCountInString(p.(*nodes.PropertyDefNode).PropertyName.Value))
+	}
+	for _, p := range node.Properties {
+		def := p.(*nodes.PropertyDefNode)
+		f.startLine(def)
+		f.write(def.PropertyName.Value + pad(def.PropertyName.Value, width) + " " + def.PropertyType.Value)
+		f.mark(def.Span)
+	}
+	f.close(node, len(node.Properties) == 0)
+}
+
+func (f *Formatter) VisitEdgeDefNode(node *nodes.EdgeDefNode) {
+	f.write("Edge " + node.EdgeName.Value + " " + node.EdgeType.String())
+}
+
+// properties writes the properties set by a vertex or a relation, one per
+// line with their values aligned
+func (f *Formatter) properties(properties []nodes.ASTNode) {
+	width := 0
+	for _, p := range properties {
+		width = max(width, utf8.RuneCountInString(p.(*nodes.PropertyInitNode).PropertyName.Value))
+	}
+	for _, p := range properties {
+		init := p.(*nodes.PropertyInitNode)
+		f.startLine(init)
+		f.write("." + init.PropertyName.Value + pad(init.PropertyName.Value, width) + " = " + literal(init))
+		f.mark(init.Span)
+	}
+}
+
+// literal returns the value of a property as it is written
+func literal(init *nodes.PropertyInitNode) string {
+	if init.Literal == lexer.TokenIntegerConstant {
+		return init.PropertyValue.Value
+	}
+	return quote(init.PropertyValue.Value)
+}
+
+func (f *Formatter) VisitRelationInitNode(node *nodes.RelationInitNode) {
+	f.write("Relation " + node.Relation.Value)
+	f.open(node.Relation.Span)
+	f.startLine(node.LeftVertex)
+	f.write(node.LeftVertex.Value + " " + node.RightVertex.Value)
+	f.mark(node.RightVertex.Span)
+	f.properties(node.Properties)
+	f.close(node, false)
+}
+
+func (f *Formatter) VisitPropertyDefNode(node *nodes.PropertyDefNode) {
+	f.write(node.PropertyName.Value + " " + node.PropertyType.Value)
+}
+
+func (f *Formatter) VisitPropertyInitNode(node *nodes.PropertyInitNode) {
+	f.write("." + node.PropertyName.Value + " = " + literal(node))
+}
+
+func (f *Formatter) VisitVertexInitNode(node *nodes.VertexInitNode) {
+	f.write("Vertex " + node.VertexName.Value + " " + node.SchemaName.Value)
+	f.open(node.SchemaName.Span)
+	f.properties(node.Properties)
+	f.close(node, len(node.Properties) == 0)
+}
+
+// bounds returns the shortest form of the bounds of an edge: [] for exactly
+// one edge, [n] for exactly n and [n..m] otherwise, without the bounds that
+// are the defaults
+func bounds(lower, upper *nodes.IntNode) string {
+	if lower == nil || upper == nil {
+		return "[]"
+	}
+	if lower.Value == upper.Value {
+		if lower.Value == 1 {
+			return "[]"
+		}
+		return "[" + strconv.Itoa(lower.Value) + "]"
+	}
+
+	buffer := new(strings.Builder)
+	buffer.WriteString("[")
+	if lower.Value != 0 {
+		buffer.WriteString(strconv.Itoa(lower.Value))
+	}
+	buffer.WriteString("..")
+	if upper.Value != math.MaxInt {
+		buffer.WriteString(strconv.Itoa(upper.Value))
+	}
+	buffer.WriteString("]")
+	return buffer.String()
+}
+
+func (f *Formatter) VisitEdgeNode(node *nodes.EdgeNode) {
+	f.write(bounds(node.LowerBound, node.UpperBound))
+	switch node.Direction {
+	case nodes.Incoming:
+		f.write("<-")
+	case nodes.AnyDirection:
+		f.write("<->")
+	}
+	if node.EdgeName == nil {
+		f.write("()")
+	} else {
+		f.write(node.EdgeName.Value)
+	}
+}
+
+func (f *Formatter) VisitPropertyNode(node *nodes.PropertyNode) {
+	if node.Alias != nil {
+		f.write(node.Alias.Value)
+	}
+	f.write("." + node.PropertyName.Value)
+}
+
+func (f *Formatter) VisitBinaryNode(node *nodes.BinaryNode) {
+	p := precedence(node)
+	f.operand(node.LeftChild, p, false)
+	f.write(" " + node.Operator.Value + " ")
+	f.operand(node.RightChild, p, true)
+}
+
+func (f *Formatter) VisitVertexNode(node *nodes.VertexNode) {
+	if node.VertexName == nil {
+		f.write("()")
+	} else {
+		f.write(node.VertexName.Value)
+	}
+	if node.Alias != nil && node.Alias.Value != "" {
+		f.write(" as " + node.Alias.Value)
+	}
+}
+
+// VisitVertexTermNode writes the conditions of a vertex term in a block, the
+// ones joined by and or or at its top each on their own line
+func (f *Formatter) VisitVertexTermNode(node *nodes.VertexTermNode) {
+	node.Vertex.Accept(f)
+	if node.Conditions == nil {
+		return
+	}
+	f.open(node.Vertex.Span)
+
+	// the operations are left associative, the conditions are on the left
+	condition, operators := node.Conditions, []string{}
+	var conditions []nodes.ASTNode
+	for precedence(condition) == 1 {
+		binary := condition.(*nodes.BinaryNode)
+		conditions = append(conditions, binary.RightChild)
+		operators = append(operators, binary.Operator.Value)
+		condition = binary.LeftChild
+	}
+	conditions = append(conditions, condition)
+
+	for i := len(conditions) - 1; i >= 0; i-- {
+		f.startLine(conditions[i])
+		if i < len(operators) {
+			f.write(operators[i] + " ")
+			f.operand(conditions[i], 1, true)
+		} else {
+			conditions[i].Accept(f)
+		}
+		f.mark(conditions[i].GetSpan())
+	}
+	f.close(node, false)
+}
+
+func (f *Formatter) VisitRelationNode(node *nodes.RelationNode) {
+	node.Edge.Accept(f)
+	f.write(" ")
+	node.Vertex.Accept(f)
+}
+
+// clause writes a clause of a query on its own line, eg: Group By C.name
+func (f *Formatter) clause(keyword string, expressions []nodes.ASTNode) {
+	f.flush(expressions[0].GetSpan().Start)
+	f.newline()
+	f.write(keyword + " ")
+	f.list(expressions)
+	f.mark(expressions[len(expressions)-1].GetSpan())
+}
+
+func (f *Formatter) VisitQueryStatement(node *nodes.QueryStatementNode) {
+	f.write("Query ")
+	node.Expression.Accept(f)
+	if node.GroupBy != nil {
+		f.clause("Group By", node.GroupBy)
+	}
+	if node.Having != nil {
+		f.clause("Having", []nodes.ASTNode{node.Having})
+	}
+	if node.Return != nil {
+		f.clause("Return", node.Return)
+	}
+}
+
+func (f *Formatter) VisitExplainStatement(node *nodes.ExplainStatementNode) {
+	f.write("Explain ")
+	node.Query.Accept(f)
+}
+
+func (f *Formatter) VisitProfileStatement(node *nodes.ProfileStatementNode) {
+	f.write("Profile ")
+	node.Query.Accept(f)
+}
+
+func (f *Formatter) VisitCallStatement(node *nodes.CallStatementNode) {
+	f.write("Call " + node.Procedure.Value + "(")
+	for i, arg := range node.Args {
+		if i > 0 {
+			f.write(", ")
+		}
+		switch a := arg.(type) {
+		case *nodes.StringNode:
+			if a.Value == "" {
+				f.write("()")
+			} else {
+				f.write(a.Value)
+			}
+		default:
+			arg.Accept(f)
+		}
+	}
+	f.write(")")
+	if node.Write != nil {
+		f.write(" Write ." + node.Write.Value)
+	}
+}
+
+func (f *Formatter) VisitSumFunc(node *nodes.SumFuncNode) {
+	f.call(node.FunctionName.String(), node.Args)
+}
+
+func (f *Formatter) VisitCountFunc(node *nodes.CountFuncNode) {
+	f.call(node.FunctionName.String(), node.Args)
+}
+
+func (f *Formatter) VisitAvgFunc(node *nodes.AvgFuncNode) {
+	f.call(node.FunctionName.String(), node.Args)
+}
+
+func (f *Formatter) VisitMaxFunc(node *nodes.MaxFuncNode) {
+	f.call(node.FunctionName.String(), node.Args)
+}
+
+func (f *Formatter) VisitMinFunc(node *nodes.MinFuncNode) {
+	f.call(node.FunctionName.String(), node.Args)
+}
+
+func (f *Formatter) VisitShortestPathFunc(node *nodes.ShortestPathFuncNode) {
+	f.call(node.FunctionName.String(), node.Args)
+}
+
+func (f *Formatter) VisitAllShortestPathsFunc(node *nodes.AllShortestPathsFuncNode) {
+	f.call(node.FunctionName.String(), node.Args)
+}
+
+func (f *Formatter) VisitLengthFunc(node *nodes.LengthFuncNode) {
+	f.call(node.FunctionName.String(), node.Args)
+}
+
+func (f *Formatter) VisitNodesFunc(node *nodes.NodesFuncNode) {
+	f.call(node.FunctionName.String(), node.Args)
+}
+
+func (f *Formatter) VisitEdgesFunc(node *nodes.EdgesFuncNode) {
+	f.call(node.FunctionName.String(), node.Args)
+}
@@ -0,0 +1,152 @@
+package visitors
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/Jintumoni/vortex/lexer"
+	"github.com/Jintumoni/vortex/nodes"
+	"github.com/Jintumoni/vortex/parser"
+	"github.com/stretchr/testify/assert"
+)
+
+// format parses src and formats it with its comments
+func format(t *testing.T, src string) (nodes.ASTNode, string) {
+	l := lexer.NewLexer(strings.NewReader(src))
+	l.KeepComments = true
+	root, err := parser.NewParser(l).Parse()
+	assert.NoError(t, err)
+	return root, string(NewFormatter(l.Comments).Format(root))
+}
+
+// withoutPositions zeroes the spans of the nodes and the positions of their
+// tokens, so trees parsed from sources laid out differently compare equal
+func withoutPositions(v reflect.Value) {
+	switch v.Kind() {
+	case reflect.Pointer, reflect.Interface:
+		if !v.IsNil() {
+			withoutPositions(v.Elem())
+		}
+	case reflect.Slice:
+		for i := 0; i < v.Len(); i++ {
+			withoutPositions(v.Index(i))
+		}
+	case reflect.Struct:
+		switch v.Type() {
+		case reflect.TypeOf(nodes.Span{}):
+			v.SetZero()
+		case reflect.TypeOf(lexer.Token{}):
+			token := v.Addr().Interface().(*lexer.Token)
+			token.Row, token.Col, token.Span, token.EndRow, token.EndCol = 0, 0, 0, 0, 0
+		default:
+			for i := 0; i < v.NumField(); i++ {
+				withoutPositions(v.Field(i))
+			}
+		}
+	}
+}
+
+func TestFormat(t *testing.T) {
+	_, formatted := format(t, `// the people
+Schema Person { name string  age int // years
+  salary int }
+Schema Empty {}
+
+
+Vertex Ann Person { .name = 'Ann \u{1F600}' .age = 30  /* a block */ }
+Vertex Bob Person {
+	.name = `+"`Bob \"the builder\"\\n`"+`
+}
+Edge LivesIn OneWay // one way
+Relation LivesIn { Ann London .since = 2001 }
+/* a query
+   over lines */
+Query Person as A { .name = "Ann" or [1..1]LivesIn () { [0..]<-Within Country {.name="UK"} } and [2..]<->FriendsWith A }
+Group By A.name Having Count(A) > 1 Return A.name, Count(A)
+Call PageRank((), FriendsWith, 20) Write .rank
+Explain Query ShortestPath(London, Rome, [..3]Road, .distance)
+// the end
+`)
+
+	assert.Equal(t, `// the people
+Schema Person {
+    name   string
+    age    int // years
+    salary int
+}
+Schema Empty {}
+
+Vertex Ann Person {
+    .name = "Ann `+"\U0001F600"+`"
+    .age  = 30 /* a block */
+}
+Vertex Bob Person {
+    .name = "Bob \"the builder\"\\n"
+}
+Edge LivesIn OneWay // one way
+Relation LivesIn {
+    Ann London
+    .since = 2001
+}
+/* a query
+   over lines */
+Query Person as A {
+    .name = "Ann"
+    or []LivesIn () {
+        [..]<-Within Country {
+            .name = "UK"
+        }
+    }
+    and [2..]<->FriendsWith A
+}
+Group By A.name
+Having Count(A) > 1
+Return A.name, Count(A)
+Call PageRank((), FriendsWith, 20) Write .rank
+Explain Query ShortestPath(London, Rome, [..3]Road, .distance)
+// the end
+`, formatted)
+}
+
+func TestFormatParenthesises(t *testing.T) {
+	for src, expected := range map[string]string{
+		"Query (1 + 2) * 3 > 1 - (2 - 3)":                             "Query (1 + 2) * 3 > 1 - (2 - 3)\n",
+		"Query ((1 * 2) + 3) = (4 / (5 / $d))":                        "Query 1 * 2 + 3 = 4 / (5 / $d)\n",
+		"Query Person { (.a = 1 or .b = 2) and (.c = 3 and .d = 4) }": "Query Person {\n    .a = 1\n    or .b = 2\n    and (.c = 3 and .d = 4)\n}\n",
+		"Query Person { (.a = 1 or .b = 2) > 0 }":                     "Query Person {\n    (.a = 1 or .b = 2) > 0\n}\n",
+	} {
+		_, formatted := format(t, src)
+		assert.Equal(t, expected, formatted, src)
+	}
+}
+
+// TestFormatRoundTrip checks that the parser reads the formatted source of a
+// program back to the same tree, and that formatting it again changes nothing
+func TestFormatRoundTrip(t *testing.T) {
+	for _, src := range []string{
+		testGraph,
+		`Query Person as A { .name = 'John' and []FriendsWith Person { []FriendsWith A } }`,
+		`Query Person as A { Sum([]FriendsWith Person { []() () }, .salary) < .salary }`,
+		`Query Sum(Person as A {
+			.name = "Hi"
+			or (.name = "H" and .age > 10)
+			and [1..2]LivesIn () { [..]Within Country { .name="India" and []Within Continent } }
+			and Sum([]FriendsWith Person { []LivesIn () { []Within Country{.name="USA"} } }, .salary) < .salary
+		}, .age)`,
+		`Query Person as P { []LivesIn City as C } Group By C.name Having Count(P) > 1 Return C.name, Count(P), Avg(P.age)`,
+		`Query Person as A { .name = "John" and Length(ShortestPath(A, India, [..]())) <= 3 }`,
+		`Query AllShortestPaths(London, Rome, [1..4]->Road, .distance) Return Nodes(P), Edges(P)`,
+		`Profile Query Person { .age > $age and []LivesIn City { .name = $city } } Return Max(.age), Min(.age)`,
+		`Call ConnectedComponents(Person, FriendsWith) Write .component Call LabelPropagation((), (), 5)`,
+		"Vertex Ann Person { .name = 'it\\'s \\t \\u{7}' }",
+	} {
+		root, formatted := format(t, src)
+		again, twice := format(t, formatted)
+		assert.Equal(t, formatted, twice)
+
+		withoutPositions(reflect.ValueOf(root))
+		withoutPositions(reflect.ValueOf(again))
+		assert.Equal(t, root, again, formatted)
+	}
+}
@@ -0,0 +1,49 @@
+package visitors
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// Limits bound the work of a query, the zero value has no limits
+type Limits struct {
+	Timeout          time.Duration // longest a query may run
+	MaxRows          int           // rows an operator of the plan may produce
+	MaxExpandedEdges int64         // adjacency list entries the traversals of a query may read
+}
+
+// LimitError is the error of a query stopped by one of its Limits
+type LimitError struct {
+	Limit string // field of Limits exceeded, eg: MaxRows
+	Max   any    // value of the limit
+}
+
+func (e *LimitError) Error() string {
+	return fmt.Sprintf("Query exceeded %s of %v", e.Limit, e.Max)
+}
+
+// check returns why the query running must stop: its context is done or
+// it exceeded a limit, rows is the number of rows produced so far by the
+// loop calling it. The reason is kept, the query stops where it is next checked.
+func (e *Evaluator) check(rows int) error {
+	if e.halted != nil {
+		return e.halted
+	}
+	if e.ctx != nil && e.ctx.Err() != nil {
+		e.halted = context.Cause(e.ctx)
+	} else if e.Limits.MaxRows > 0 && rows > e.Limits.MaxRows {
+		e.halted = &LimitError{Limit: "MaxRows", Max: e.Limits.MaxRows}
+	}
+	return e.halted
+}
+
+// halt counts the adjacency list entries read by the traversals of the
+// query, it stops them once the query must stop, see manager.Traversal
+func (e *Evaluator) halt(entries int) bool {
+	e.expanded += int64(entries)
+	if e.halted == nil && e.Limits.MaxExpandedEdges > 0 && e.expanded > e.Limits.MaxExpandedEdges {
+		e.halted = &LimitError{Limit: "MaxExpandedEdges", Max: e.Limits.MaxExpandedEdges}
+	}
+	return e.check(0) != nil
+}
@@ -0,0 +1,68 @@
+package visitors
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMaxRows(t *testing.T) {
+	appManager, query := loadQuery(t, `Query Person Return .name`)
+	plan, err := NewPlanner(appManager).Plan(query)
+	assert.NoError(t, err)
+
+	evaluator := NewEvaluator(appManager)
+	evaluator.Limits.MaxRows = 3
+	result, err := evaluator.Run(plan)
+	assert.NoError(t, err)
+	assert.Len(t, result.Rows, 3)
+
+	evaluator.Limits.MaxRows = 2
+	_, err = evaluator.Run(plan)
+	var limit *LimitError
+	assert.True(t, errors.As(err, &limit))
+	assert.Equal(t, &LimitError{Limit: "MaxRows", Max: 2}, limit)
+	assert.EqualError(t, err, "Query exceeded MaxRows of 2")
+}
+
+func TestMaxExpandedEdges(t *testing.T) {
+	appManager, query := loadQuery(t, `Query London { [..]Within Country }`)
+	plan, err := NewPlanner(appManager).Plan(query)
+	assert.NoError(t, err)
+
+	// London has two adjacency list entries, England one and UK none
+	evaluator := NewEvaluator(appManager)
+	evaluator.Limits.MaxExpandedEdges = 3
+	_, err = evaluator.Run(plan)
+	assert.NoError(t, err)
+
+	evaluator.Limits.MaxExpandedEdges = 2
+	_, err = evaluator.Run(plan)
+	assert.Equal(t, &LimitError{Limit: "MaxExpandedEdges", Max: int64(2)}, err)
+}
+
+func TestRunContext(t *testing.T) {
+	appManager, query := loadQuery(t, `Query Person { Count([..]FriendsWith Person) > 0 }`)
+	plan, err := NewPlanner(appManager).Plan(query)
+	assert.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = NewEvaluator(appManager).RunContext(ctx, plan)
+	assert.ErrorIs(t, err, context.Canceled)
+
+	evaluator := NewEvaluator(appManager)
+	evaluator.Limits.Timeout = time.Nanosecond
+	time.Sleep(time.Millisecond)
+	_, err = evaluator.RunContext(context.Background(), plan)
+	assert.Equal(t, &LimitError{Limit: "Timeout", Max: time.Nanosecond}, err)
+
+	// the limits do not outlive the query
+	evaluator.Limits.Timeout = 0
+	result, err := evaluator.RunContext(context.Background(), plan)
+	assert.NoError(t, err)
+	assert.Len(t, result.Rows, 3)
+}
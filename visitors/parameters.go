@@ -0,0 +1,70 @@
+package visitors
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"reflect"
+
+	"github.com/Jintumoni/vortex/nodes"
+)
+
+var InvalidParameter = errors.New("Parameter type is not supported")
+
+// Parameters converts the Go values of the parameters of a statement to the
+// values the evaluator works with.
+func Parameters(params map[string]any) (map[string]nodes.ASTNode, error) {
+	values := make(map[string]nodes.ASTNode, len(params))
+	for name, param := range params {
+		value, err := ParameterValue(param)
+		if err != nil {
+			return nil, fmt.Errorf("%w: $%s", err, name)
+		}
+		values[name] = value
+	}
+	return values, nil
+}
+
+// ParameterValue converts a Go value to a value of the evaluator. Integers,
+// floats, strings, booleans, nil and slices of those are supported.
+func ParameterValue(param any) (nodes.ASTNode, error) {
+	switch p := param.(type) {
+	case nil:
+		return &nodes.NullNode{}, nil
+	case nodes.ASTNode:
+		return p, nil
+	case int:
+		return &nodes.IntNode{Value: p}, nil
+	case int8, int16, int32, int64:
+		return &nodes.IntNode{Value: int(reflect.ValueOf(p).Int())}, nil
+	case uint8, uint16, uint32:
+		return &nodes.IntNode{Value: int(reflect.ValueOf(p).Uint())}, nil
+	case uint, uint64:
+		if number := reflect.ValueOf(p).Uint(); number <= math.MaxInt {
+			return &nodes.IntNode{Value: int(number)}, nil
+		}
+		return nil, fmt.Errorf("%w: %v overflows int", InvalidParameter, p)
+	case float32:
+		return &nodes.FloatNode{Value: float64(p)}, nil
+	case float64:
+		return &nodes.FloatNode{Value: p}, nil
+	case string:
+		return &nodes.StringNode{Value: p}, nil
+	case bool:
+		return &nodes.BoolNode{Value: p}, nil
+	}
+
+	value := reflect.ValueOf(param)
+	if value.Kind() == reflect.Slice || value.Kind() == reflect.Array {
+		list := &nodes.ListNode{Values: make([]nodes.ASTNode, 0, value.Len())}
+		for i := 0; i < value.Len(); i++ {
+			element, err := ParameterValue(value.Index(i).Interface())
+			if err != nil {
+				return nil, err
+			}
+			list.Values = append(list.Values, element)
+		}
+		return list, nil
+	}
+	return nil, fmt.Errorf("%w: %T", InvalidParameter, param)
+}
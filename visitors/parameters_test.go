@@ -0,0 +1,29 @@
+package visitors
+
+import (
+	"testing"
+
+	"github.com/Jintumoni/vortex/nodes"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParameters(t *testing.T) {
+	values, err := Parameters(map[string]any{
+		"int":    int32(7),
+		"float":  1.5,
+		"string": "Ann",
+		"bool":   true,
+		"null":   nil,
+		"list":   []string{"Ann", "Bob"},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, &nodes.IntNode{Value: 7}, values["int"])
+	assert.Equal(t, &nodes.FloatNode{Value: 1.5}, values["float"])
+	assert.Equal(t, &nodes.StringNode{Value: "Ann"}, values["string"])
+	assert.Equal(t, &nodes.BoolNode{Value: true}, values["bool"])
+	assert.Equal(t, &nodes.NullNode{}, values["null"])
+	assert.Equal(t, "[Ann, Bob]", FormatValue(values["list"]))
+
+	_, err = Parameters(map[string]any{"map": map[string]int{}})
+	assert.ErrorIs(t, err, InvalidParameter)
+}
@@ -0,0 +1,593 @@
+package visitors
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/Jintumoni/vortex/lexer"
+	"github.com/Jintumoni/vortex/manager"
+	"github.com/Jintumoni/vortex/nodes"
+)
+
+// Operator is a step of a physical query plan. The rows produced by the
+// inputs of an operator flow into it, the root of a plan produces the result.
+type Operator interface {
+	Inputs() []Operator
+	String() string
+	// EstimatedRows is the number of rows the planner expects the operator to produce
+	EstimatedRows() float64
+}
+
+type estimate struct {
+	rows float64
+}
+
+func (e estimate) EstimatedRows() float64 {
+	return e.rows
+}
+
+// ScanOp produces a row per vertex of a schema, or per vertex of the graph when Name is empty
+type ScanOp struct {
+	estimate
+	Name  string
+	Alias string
+}
+
+// SeekOp produces the row of a single vertex looked up by its name
+type SeekOp struct {
+	estimate
+	Name  string
+	Alias string
+}
+
+// ExpandOp matches a relation from the vertex of every input row.
+//
+// A Source expansion produces the rows a query starts from by walking a
+// relation of the query backwards from the rows of the vertices it leads
+// to, a row per vertex reached from each of them. Every row binds a pair
+// the relation joins, so the relation is not matched again.
+type ExpandOp struct {
+	estimate
+	Input    Operator
+	Relation *nodes.RelationNode
+	Source   bool
+}
+
+// FilterOp keeps the input rows under which a condition holds
+type FilterOp struct {
+	estimate
+	Input     Operator
+	Condition nodes.ASTNode
+}
+
+// ProjectOp turns the input rows into the result of a query without aggregates
+type ProjectOp struct {
+	estimate
+	Input Operator
+	Query *nodes.QueryStatementNode
+}
+
+// AggregateOp groups the input rows and turns every group into a result row
+type AggregateOp struct {
+	estimate
+	Input Operator
+	Query *nodes.QueryStatementNode
+}
+
+// ValueOp evaluates a query without a vertex term, eg: Query ShortestPath(A, B, [..]())
+type ValueOp struct {
+	estimate
+	Expression nodes.ASTNode
+}
+
+func (op *ScanOp) Inputs() []Operator      { return nil }
+func (op *SeekOp) Inputs() []Operator      { return nil }
+func (op *ExpandOp) Inputs() []Operator    { return []Operator{op.Input} }
+func (op *FilterOp) Inputs() []Operator    { return []Operator{op.Input} }
+func (op *ProjectOp) Inputs() []Operator   { return []Operator{op.Input} }
+func (op *AggregateOp) Inputs() []Operator { return []Operator{op.Input} }
+func (op *ValueOp) Inputs() []Operator     { return nil }
+
+func (op *ScanOp) String() string {
+	return "Scan " + termString(op.Name, op.Alias)
+}
+
+func (op *SeekOp) String() string {
+	return "IndexSeek " + termString(op.Name, op.Alias)
+}
+
+func (op *ExpandOp) String() string {
+	s := "Expand " + exprString(op.Relation.Edge) + " " + relationTarget(op.Relation)
+	if op.Source {
+		s += " (source)"
+	}
+	return s
+}
+
+func (op *FilterOp) String() string {
+	return "Filter " + exprString(op.Condition)
+}
+
+func (op *ProjectOp) String() string {
+	if op.Query.Return == nil {
+		return "Project " + exprString(op.Query.Expression)
+	}
+	return "Project " + strings.Join(exprStrings(op.Query.Return), ", ")
+}
+
+func (op *AggregateOp) String() string {
+	var clauses []string
+	if op.Query.GroupBy != nil {
+		clauses = append(clauses, "Group By "+strings.Join(exprStrings(op.Query.GroupBy), ", "))
+	}
+	if op.Query.Having != nil {
+		clauses = append(clauses, "Having "+exprString(op.Query.Having))
+	}
+	if op.Query.Return != nil {
+		clauses = append(clauses, "Return "+strings.Join(exprStrings(op.Query.Return), ", "))
+	}
+	return "Aggregate " + strings.Join(clauses, " ")
+}
+
+func (op *ValueOp) String() string {
+	return "Evaluate " + exprString(op.Expression)
+}
+
+func termString(name, alias string) string {
+	if name == "" {
+		name = "()"
+	}
+	if alias != "" {
+		return name + " as " + alias
+	}
+	return name
+}
+
+// relationTarget describes the vertex term a relation leads to, with its conditions
+func relationTarget(relation *nodes.RelationNode) string {
+	term := relation.Vertex.(*nodes.VertexTermNode)
+	name, alias := termNames(term)
+	s := termString(name, alias)
+	if term.Conditions != nil {
+		s += " { " + exprString(term.Conditions) + " }"
+	}
+	return s
+}
+
+func termNames(term *nodes.VertexTermNode) (string, string) {
+	name, alias := "", ""
+	if term.Vertex.VertexName != nil {
+		name = term.Vertex.VertexName.Value
+	}
+	if term.Vertex.Alias != nil {
+		alias = term.Vertex.Alias.Value
+	}
+	return name, alias
+}
+
+// Planner turns query statements into physical plans. The vertices a query
+// starts from and the order its conditions are checked in are chosen from
+// the cardinalities kept by the AppManager.
+//
+// A query Person { c1 and c2 } starts from a scan of Person, or from an
+// expansion of the relation in one of its conditions when that relation
+// leads to fewer vertices, and checks every condition on each of its rows.
+// The conditions only see the vertex of the query, so they can be checked
+// in any order that binds an alias before it is used.
+type Planner struct {
+	appManager *manager.AppManager
+}
+
+func NewPlanner(appManager *manager.AppManager) *Planner {
+	return &Planner{appManager: appManager}
+}
+
+// Plan returns the physical plan of the query
+func (p *Planner) Plan(query *nodes.QueryStatementNode) (Operator, error) {
+	term, ok := query.Expression.(*nodes.VertexTermNode)
+	if !ok {
+		if query.GroupBy != nil || query.Having != nil || query.Return != nil {
+			return nil, GroupByWithoutPattern
+		}
+		return &ValueOp{estimate: estimate{1}, Expression: query.Expression}, nil
+	}
+
+	conditions := p.order(conjuncts(term.Conditions))
+	op, walked := p.source(term, conditions)
+	for _, condition := range conditions {
+		if relation, ok := condition.(*nodes.RelationNode); ok {
+			if relation == walked {
+				// the rows of the source are the pairs the relation joins
+				continue
+			}
+			op = &ExpandOp{estimate: estimate{op.EstimatedRows() * p.fanOut(relation)}, Input: op, Relation: relation}
+		} else {
+			op = &FilterOp{estimate: estimate{op.EstimatedRows() * p.selectivity(condition)}, Input: op, Condition: condition}
+		}
+	}
+
+	rows := op.EstimatedRows()
+	if query.GroupBy == nil && query.Having == nil && !hasAggregate(query.Return) {
+		return &ProjectOp{estimate: estimate{rows}, Input: op, Query: query}, nil
+	}
+	if query.GroupBy == nil {
+		rows = 1
+	} else if query.Having != nil {
+		rows *= p.selectivity(query.Having)
+	}
+	return &AggregateOp{estimate: estimate{rows}, Input: op, Query: query}, nil
+}
+
+// source returns the cheapest operator producing the vertices of the query
+// term and the relation it walks backwards, if any
+func (p *Planner) source(term *nodes.VertexTermNode, conditions []nodes.ASTNode) (Operator, *nodes.RelationNode) {
+	name, alias := termNames(term)
+	var best Operator = p.scan(name, alias)
+	if _, ok := best.(*SeekOp); ok {
+		return best, nil
+	}
+	var walked *nodes.RelationNode
+
+	perRow := 0.0
+	for _, condition := range conditions {
+		perRow += p.cost(condition)
+	}
+	bestCost := best.EstimatedRows() * (1 + perRow)
+
+	for _, condition := range conditions {
+		relation, ok := condition.(*nodes.RelationNode)
+		if !ok {
+			continue
+		}
+		targetTerm := relation.Vertex.(*nodes.VertexTermNode)
+		target, targetAlias := termNames(targetTerm)
+		if target == "" || target == alias || definesAlias(conditions, target) {
+			continue
+		}
+		if targetAlias != "" && targetAlias == alias || usesOuterAlias(targetTerm, alias, conditions) {
+			// the conditions of the target need the vertex of the query
+			continue
+		}
+		if _, err := p.appManager.ReadVertex(target); err != nil && !p.isSchema(target) {
+			// unknown names are reported when the query runs
+			continue
+		}
+
+		// the conditions of the target are checked on its scan
+		input := p.scan(target, targetAlias)
+		inputCost := input.EstimatedRows()
+		if targetTerm.Conditions != nil {
+			inputCost *= 1 + p.cost(targetTerm.Conditions)
+			input = &FilterOp{
+				estimate:  estimate{input.EstimatedRows() * p.selectivity(targetTerm.Conditions)},
+				Input:     input,
+				Condition: targetTerm.Conditions,
+			}
+		}
+
+		edge := reverse(relation.Edge.(*nodes.EdgeNode))
+		reached := input.EstimatedRows() * p.reach(edge)
+		rows := reached * p.nameSelectivity(name)
+		cost := inputCost + reached + rows*(1+perRow-p.cost(relation))
+		if cost < bestCost {
+			walked = relation
+			best, bestCost = &ExpandOp{
+				estimate: estimate{rows},
+				Input:    input,
+				Relation: &nodes.RelationNode{
+					Edge:   edge,
+					Vertex: &nodes.VertexTermNode{Vertex: &nodes.VertexNode{VertexName: term.Vertex.VertexName, Alias: term.Vertex.Alias}},
+				},
+				Source: true,
+			}, cost
+		}
+	}
+	return best, walked
+}
+
+// scan returns the operator producing the vertices named by a schema or a vertex name
+func (p *Planner) scan(name, alias string) Operator {
+	if name != "" && !p.isSchema(name) {
+		if _, err := p.appManager.ReadVertex(name); err == nil {
+			return &SeekOp{estimate: estimate{1}, Name: name, Alias: alias}
+		}
+	}
+	rows := p.appManager.VertexCardinality()
+	if name != "" {
+		rows = p.appManager.SchemaCardinality(name)
+	}
+	return &ScanOp{estimate: estimate{float64(rows)}, Name: name, Alias: alias}
+}
+
+func (p *Planner) isSchema(name string) bool {
+	_, err := p.appManager.ReadSchema(name)
+	return err == nil
+}
+
+// order returns the conditions sorted by their cost, a condition using an
+// alias stays after the condition binding it
+func (p *Planner) order(conditions []nodes.ASTNode) []nodes.ASTNode {
+	remaining := append([]nodes.ASTNode{}, conditions...)
+	ordered := make([]nodes.ASTNode, 0, len(conditions))
+	for len(remaining) > 0 {
+		best := -1
+		for i, condition := range remaining {
+			others := append(append([]nodes.ASTNode{}, remaining[:i]...), remaining[i+1:]...)
+			if usesAlias(condition, others) {
+				continue
+			}
+			if best < 0 || p.rank(condition) < p.rank(remaining[best]) {
+				best = i
+			}
+		}
+		if best < 0 {
+			// the aliases depend on each other, keep the written order
+			best = 0
+		}
+		ordered = append(ordered, remaining[best])
+		remaining = append(remaining[:best], remaining[best+1:]...)
+	}
+	return ordered
+}
+
+// rank is the cost of checking a condition on a row plus the rows it leaves,
+// conditions with a lower rank are checked first
+func (p *Planner) rank(condition nodes.ASTNode) float64 {
+	if relation, ok := condition.(*nodes.RelationNode); ok {
+		return p.cost(relation) + p.fanOut(relation)
+	}
+	return p.cost(condition) + p.selectivity(condition)
+}
+
+// fanOut is the number of rows a relation is expected to match from a vertex
+func (p *Planner) fanOut(relation *nodes.RelationNode) float64 {
+	term := relation.Vertex.(*nodes.VertexTermNode)
+	name, _ := termNames(term)
+	rows := p.reach(relation.Edge.(*nodes.EdgeNode)) * p.nameSelectivity(name)
+	if term.Conditions != nil {
+		rows *= p.selectivity(term.Conditions)
+	}
+	return rows
+}
+
+// reach is the number of distinct vertices an edge is expected to reach from a vertex
+func (p *Planner) reach(edge *nodes.EdgeNode) float64 {
+	vertices := float64(p.appManager.VertexCardinality())
+	if vertices == 0 {
+		return 0
+	}
+	name := ""
+	if edge.EdgeName != nil {
+		name = edge.EdgeName.Value
+	}
+	degree := float64(p.appManager.EdgeCardinality(name)) / vertices
+	if edge.Direction == nodes.AnyDirection {
+		degree *= 2
+	}
+
+	reached, frontier := 0.0, 1.0
+	if edge.LowerBound.Value == 0 {
+		reached = 1
+	}
+	// a path without repeated vertices has at most vertices-1 edges
+	for hops := 1; hops <= edge.UpperBound.Value && hops < int(vertices); hops++ {
+		frontier *= degree
+		if hops >= edge.LowerBound.Value {
+			reached += frontier
+		}
+		if reached >= vertices || frontier < 1e-6 {
+			break
+		}
+	}
+	return min(reached, vertices)
+}
+
+// nameSelectivity is the fraction of the vertices named by an alias, schema or vertex name
+func (p *Planner) nameSelectivity(name string) float64 {
+	vertices := float64(p.appManager.VertexCardinality())
+	if name == "" || vertices == 0 {
+		return 1
+	}
+	if p.isSchema(name) {
+		return float64(p.appManager.SchemaCardinality(name)) / vertices
+	}
+	return 1 / vertices
+}
+
+// selectivity is the fraction of the rows a condition is expected to hold for
+func (p *Planner) selectivity(condition nodes.ASTNode) float64 {
+	switch n := condition.(type) {
+	case *nodes.BinaryNode:
+		switch n.Operator.Type {
+		case lexer.TokenAnd:
+			return p.selectivity(n.LeftChild) * p.selectivity(n.RightChild)
+		case lexer.TokenOr:
+			left, right := p.selectivity(n.LeftChild), p.selectivity(n.RightChild)
+			return left + right - left*right
+		case lexer.TokenEqual:
+			return 0.1
+		case lexer.TokenNotEqual:
+			return 0.9
+		default:
+			return 0.33
+		}
+	case *nodes.RelationNode:
+		return min(p.fanOut(n), 1)
+	default:
+		return 0.5
+	}
+}
+
+// cost is the number of vertices expected to be visited while checking a condition on a row
+func (p *Planner) cost(node nodes.ASTNode) float64 {
+	switch n := node.(type) {
+	case *nodes.BinaryNode:
+		return 1 + p.cost(n.LeftChild) + p.cost(n.RightChild)
+	case *nodes.RelationNode:
+		reached := p.reach(n.Edge.(*nodes.EdgeNode))
+		return reached * (1 + p.cost(n.Vertex))
+	case *nodes.VertexTermNode:
+		if n.Conditions == nil {
+			return 0
+		}
+		name, _ := termNames(n)
+		rows := 1.0
+		if p.isSchema(name) || name == "" {
+			rows = p.scan(name, "").EstimatedRows()
+		}
+		return rows * (1 + p.cost(n.Conditions))
+	case *nodes.ShortestPathFuncNode, *nodes.AllShortestPathsFuncNode:
+		return float64(p.appManager.VertexCardinality() + p.appManager.EdgeCardinality(""))
+	default:
+		total := 0.0
+		for _, child := range children(node) {
+			total += p.cost(child)
+		}
+		return total
+	}
+}
+
+// reverse returns the edge walked in the opposite direction
+func reverse(edge *nodes.EdgeNode) *nodes.EdgeNode {
+	reversed := *edge
+	switch edge.Direction {
+	case nodes.Incoming:
+		reversed.Direction = nodes.Outgoing
+	case nodes.AnyDirection:
+		reversed.Direction = nodes.AnyDirection
+	default:
+		reversed.Direction = nodes.Incoming
+	}
+	return &reversed
+}
+
+// conjuncts splits a condition on its top level and operators
+func conjuncts(condition nodes.ASTNode) []nodes.ASTNode {
+	if condition == nil {
+		return nil
+	}
+	if n, ok := condition.(*nodes.BinaryNode); ok && n.Operator.Type == lexer.TokenAnd {
+		return append(conjuncts(n.LeftChild), conjuncts(n.RightChild)...)
+	}
+	return []nodes.ASTNode{condition}
+}
+
+// usesAlias reports whether the condition refers to an alias bound by one of the others
+func usesAlias(condition nodes.ASTNode, others []nodes.ASTNode) bool {
+	used := false
+	walk(condition, func(node nodes.ASTNode) {
+		switch n := node.(type) {
+		case *nodes.PropertyNode:
+			if n.Alias != nil && definesAlias(others, n.Alias.Value) {
+				used = true
+			}
+		case *nodes.VertexTermNode:
+			if name, _ := termNames(n); name != "" && definesAlias(others, name) {
+				used = true
+			}
+		}
+	})
+	return used
+}
+
+// usesOuterAlias reports whether the conditions of a vertex term refer to
+// the alias of the query or to an alias bound outside of the term
+func usesOuterAlias(term *nodes.VertexTermNode, alias string, conditions []nodes.ASTNode) bool {
+	if term.Conditions == nil {
+		return false
+	}
+	_, own := termNames(term)
+	outer := func(name string) bool {
+		if name == "" || name == own || definesAlias([]nodes.ASTNode{term.Conditions}, name) {
+			return false
+		}
+		return name == alias || definesAlias(conditions, name)
+	}
+	used := false
+	walk(term.Conditions, func(node nodes.ASTNode) {
+		switch n := node.(type) {
+		case *nodes.PropertyNode:
+			if n.Alias != nil && outer(n.Alias.Value) {
+				used = true
+			}
+		case *nodes.VertexTermNode:
+			if name, _ := termNames(n); outer(name) {
+				used = true
+			}
+		}
+	})
+	return used
+}
+
+// definesAlias reports whether any of the conditions binds the alias
+func definesAlias(conditions []nodes.ASTNode, alias string) bool {
+	defined := false
+	for _, condition := range conditions {
+		walk(condition, func(node nodes.ASTNode) {
+			if n, ok := node.(*nodes.VertexTermNode); ok {
+				if _, a := termNames(n); a == alias {
+					defined = true
+				}
+			}
+		})
+	}
+	return defined
+}
+
+// walk calls visit on the node and on every node below it
+func walk(node nodes.ASTNode, visit func(nodes.ASTNode)) {
+	if node == nil {
+		return
+	}
+	visit(node)
+	for _, child := range children(node) {
+		walk(child, visit)
+	}
+}
+
+// children returns the expressions directly below a node of a condition
+func children(node nodes.ASTNode) []nodes.ASTNode {
+	switch n := node.(type) {
+	case *nodes.BinaryNode:
+		return []nodes.ASTNode{n.LeftChild, n.RightChild}
+	case *nodes.VertexTermNode:
+		if n.Conditions == nil {
+			return nil
+		}
+		return []nodes.ASTNode{n.Conditions}
+	case *nodes.RelationNode:
+		return []nodes.ASTNode{n.Edge, n.Vertex}
+	case *nodes.SumFuncNode:
+		return n.Args
+	case *nodes.CountFuncNode:
+		return n.Args
+	case *nodes.AvgFuncNode:
+		return n.Args
+	case *nodes.MaxFuncNode:
+		return n.Args
+	case *nodes.MinFuncNode:
+		return n.Args
+	case *nodes.ShortestPathFuncNode:
+		return n.Args
+	case *nodes.AllShortestPathsFuncNode:
+		return n.Args
+	case *nodes.LengthFuncNode:
+		return n.Args
+	case *nodes.NodesFuncNode:
+		return n.Args
+	case *nodes.EdgesFuncNode:
+		return n.Args
+	case *nodes.ListNode:
+		return n.Values
+	default:
+		return nil
+	}
+}
+
+// formatEstimate prints an estimated number of rows
+func formatEstimate(rows float64) string {
+	if rows < 10 {
+		return fmt.Sprintf("%.2f", rows)
+	}
+	return fmt.Sprintf("%.0f", rows)
+}
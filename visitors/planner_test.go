@@ -0,0 +1,124 @@
+package visitors
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/Jintumoni/vortex/lexer"
+	"github.com/Jintumoni/vortex/manager"
+	"github.com/Jintumoni/vortex/nodes"
+	"github.com/Jintumoni/vortex/parser"
+	"github.com/stretchr/testify/assert"
+)
+
+// planQuery loads testGraph and plans the query written in src
+func planQuery(t *testing.T, src string) Operator {
+	appManager, query := loadQuery(t, src)
+	plan, err := NewPlanner(appManager).Plan(query)
+	assert.NoError(t, err)
+	return plan
+}
+
+// loadQuery loads testGraph and returns the query written in src
+func loadQuery(t *testing.T, src string) (*manager.AppManager, *nodes.QueryStatementNode) {
+	p := parser.NewParser(lexer.NewLexer(strings.NewReader(testGraph + src)))
+	root, err := p.Parse()
+	assert.NoError(t, err)
+
+	appManager := manager.NewAppManager()
+	var query *nodes.QueryStatementNode
+	for _, node := range root.(*nodes.ProgramStatementNode).Children {
+		switch n := node.(type) {
+		case *nodes.SchemaDefNode:
+			assert.NoError(t, appManager.WriteSchema(n))
+		case *nodes.EdgeDefNode:
+			assert.NoError(t, appManager.WriteEdge(n))
+		case *nodes.VertexInitNode:
+			assert.NoError(t, appManager.WriteVertex(n))
+		case *nodes.RelationInitNode:
+			assert.NoError(t, appManager.WriteRelation(n))
+		case *nodes.QueryStatementNode:
+			query = n
+		}
+	}
+	return appManager, query
+}
+
+// operators returns the plan from its root down to its first leaf
+func operators(plan Operator) []string {
+	var ops []string
+	for op := plan; op != nil; {
+		ops = append(ops, op.String())
+		if len(op.Inputs()) == 0 {
+			break
+		}
+		op = op.Inputs()[0]
+	}
+	return ops
+}
+
+func TestPlanScan(t *testing.T) {
+	plan := planQuery(t, `Query Person as P { .age > 25 }`)
+	assert.Equal(t, []string{"Project P", "Filter .age > 25", "Scan Person as P"}, operators(plan))
+	assert.Equal(t, 3.0, plan.Inputs()[0].Inputs()[0].EstimatedRows())
+}
+
+func TestPlanIndexSeek(t *testing.T) {
+	plan := planQuery(t, `Query Ann`)
+	assert.Equal(t, []string{"Project Ann", "IndexSeek Ann"}, operators(plan))
+}
+
+func TestPlanStartsFromSelectiveRelation(t *testing.T) {
+	plan := planQuery(t, `Query Person { []LivesIn Paris }`)
+	assert.Equal(t, []string{
+		"Project Person",
+		"Expand []<-LivesIn Person (source)",
+		"IndexSeek Paris",
+	}, operators(plan))
+}
+
+func TestPlanChecksTheTargetOfAWalkedRelationOnItsScan(t *testing.T) {
+	plan := planQuery(t, `Query Person as P { []LivesIn City as C { .name = "London" } } Return P.name, C.name`)
+	assert.Equal(t, []string{
+		"Project P.name, C.name",
+		"Expand []<-LivesIn Person as P (source)",
+		`Filter .name = "London"`,
+		"Scan City as C",
+	}, operators(plan))
+
+	// the target needs the vertex of the query, it is not walked from
+	plan = planQuery(t, `Query Person as P { []LivesIn City { .name = P.name } }`)
+	assert.Equal(t, "Scan Person as P", operators(plan)[len(operators(plan))-1])
+}
+
+func TestPlanChecksCheapConditionsFirst(t *testing.T) {
+	plan := planQuery(t, `Query Person as A { [1..2]() City as B and .age > 25 and B.name = A.name }`)
+	assert.Equal(t, []string{
+		"Project A",
+		"Filter B.name = A.name",
+		"Filter .age > 25",
+		"Expand [1..2]<-() Person as A (source)",
+		"Scan City as B",
+	}, operators(plan))
+}
+
+func TestPlanAggregate(t *testing.T) {
+	plan := planQuery(t, `Query Person as P { .age > 100 } Return Count(P)`)
+	assert.IsType(t, &AggregateOp{}, plan)
+	assert.Equal(t, 1.0, plan.EstimatedRows())
+}
+
+func TestVisualizePlan(t *testing.T) {
+	plan := planQuery(t, `Query Person as P { .age > 25 }`)
+
+	output := new(bytes.Buffer)
+	visualizer := NewVisualizer()
+	visualizer.Output = output
+	visualizer.VisualizePlan(plan)
+
+	assert.Equal(t, `┗━Project P (estimated rows: 0.99)
+    ┗━Filter .age > 25 (estimated rows: 0.99)
+        ┗━Scan Person as P (estimated rows: 3.00)
+`, output.String())
+}
@@ -0,0 +1,67 @@
+package visitors
+
+import (
+	"context"
+	"time"
+
+	"github.com/Jintumoni/vortex/manager"
+)
+
+// OperatorStats is the work done by an operator of a profiled plan, the
+// work done by its inputs is not included
+type OperatorStats struct {
+	RowsIn  int
+	RowsOut int
+	manager.Counters
+	Time time.Duration
+
+	// the work done by the operator and its inputs
+	total     manager.Counters
+	totalTime time.Duration
+}
+
+// Profile holds the statistics of every operator of a plan that ran
+type Profile map[Operator]*OperatorStats
+
+// Profile runs the plan like Run does and also returns the work done by
+// every operator of the plan
+func (e *Evaluator) Profile(plan Operator) (*ResultSet, Profile, error) {
+	return e.ProfileContext(context.Background(), plan)
+}
+
+// ProfileContext is Profile stopped like RunContext
+func (e *Evaluator) ProfileContext(ctx context.Context, plan Operator) (*ResultSet, Profile, error) {
+	profile := make(Profile)
+	e.profile = profile
+	defer func() { e.profile = nil }()
+
+	result, err := e.RunContext(ctx, plan)
+	return result, profile, err
+}
+
+type measurement struct {
+	start    time.Time
+	counters manager.Counters
+}
+
+func (e *Evaluator) measure() measurement {
+	return measurement{start: time.Now(), counters: e.appManager.Counters()}
+}
+
+// record saves the work done by op since m, minus the work of its inputs
+func (e *Evaluator) record(op Operator, m measurement, rows int) {
+	stats := &OperatorStats{
+		RowsOut:   rows,
+		total:     e.appManager.Counters().Sub(m.counters),
+		totalTime: time.Since(m.start),
+	}
+	stats.Counters, stats.Time = stats.total, stats.totalTime
+	for _, input := range op.Inputs() {
+		if in, ok := e.profile[input]; ok {
+			stats.RowsIn += in.RowsOut
+			stats.Counters = stats.Counters.Sub(in.total)
+			stats.Time -= in.totalTime
+		}
+	}
+	e.profile[op] = stats
+}
@@ -0,0 +1,82 @@
+package visitors
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestProfile(t *testing.T) {
+	appManager, query := loadQuery(t, `Query Person { Sum([]FriendsWith Person, .age) > 25 }`)
+	plan, err := NewPlanner(appManager).Plan(query)
+	assert.NoError(t, err)
+
+	result, profile, err := NewEvaluator(appManager).Profile(plan)
+	assert.NoError(t, err)
+	assert.Equal(t, [][]string{{"Bob"}}, formatRows(result))
+
+	project := plan.(*ProjectOp)
+	filter := project.Input.(*FilterOp)
+	scan := filter.Input.(*ScanOp)
+
+	assert.Equal(t, 0, profile[scan].RowsIn)
+	assert.Equal(t, 3, profile[scan].RowsOut)
+	assert.Equal(t, int64(0), profile[scan].AdjacencyEntries)
+
+	// the subquery of the filter reads the adjacency list of every person
+	assert.Equal(t, 3, profile[filter].RowsIn)
+	assert.Equal(t, 1, profile[filter].RowsOut)
+	assert.Equal(t, int64(5), profile[filter].AdjacencyEntries)
+
+	assert.Equal(t, 1, profile[project].RowsIn)
+	assert.Equal(t, 1, profile[project].RowsOut)
+}
+
+func TestProfileIndexHits(t *testing.T) {
+	appManager, query := loadQuery(t, `Query Person { []LivesIn Paris }`)
+	plan, err := NewPlanner(appManager).Plan(query)
+	assert.NoError(t, err)
+
+	_, profile, err := NewEvaluator(appManager).Profile(plan)
+	assert.NoError(t, err)
+
+	seek := plan.Inputs()[0].Inputs()[0].(*SeekOp)
+	assert.Positive(t, profile[seek].IndexHits)
+	assert.Equal(t, 1, profile[seek].RowsOut)
+}
+
+func TestProfileWalkBack(t *testing.T) {
+	appManager, query := loadQuery(t, `Query Person as P { []LivesIn City as C { .name = "London" } } Return P.name, C.name`)
+	plan, err := NewPlanner(appManager).Plan(query)
+	assert.NoError(t, err)
+
+	result, profile, err := NewEvaluator(appManager).Profile(plan)
+	assert.NoError(t, err)
+	assert.Equal(t, [][]string{{"Ann", "London"}, {"Bob", "London"}}, formatRows(result))
+
+	// only the list of London is read, against 5 entries for the lists of
+	// every person, and the relation is not matched again
+	expand := plan.Inputs()[0].(*ExpandOp)
+	assert.True(t, expand.Source)
+	assert.Equal(t, 1, profile[expand].RowsIn)
+	assert.Equal(t, int64(3), profile[expand].AdjacencyEntries)
+}
+
+func TestVisualizeProfile(t *testing.T) {
+	appManager, query := loadQuery(t, `Query Person as P { .age > 25 }`)
+	plan, err := NewPlanner(appManager).Plan(query)
+	assert.NoError(t, err)
+	_, profile, err := NewEvaluator(appManager).Profile(plan)
+	assert.NoError(t, err)
+
+	output := new(bytes.Buffer)
+	visualizer := NewVisualizer()
+	visualizer.Output = output
+	visualizer.VisualizeProfile(plan, profile)
+
+	lines := strings.Split(strings.TrimSpace(output.String()), "\n")
+	assert.Len(t, lines, 3)
+	assert.True(t, strings.HasPrefix(lines[1], "    ┗━Filter .age > 25 (rows in: 3, rows out: 2, adjacency entries: 0, index hits: 0, time: "))
+}
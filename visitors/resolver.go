@@ -0,0 +1,451 @@
+package visitors
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/Jintumoni/vortex/algorithms"
+	"github.com/Jintumoni/vortex/manager"
+	"github.com/Jintumoni/vortex/nodes"
+)
+
+// SymbolKind is what a name defined by a program names
+type SymbolKind int
+
+const (
+	SchemaSymbol SymbolKind = iota + 1
+	PropertySymbol
+	VertexSymbol
+	EdgeSymbol
+	AliasSymbol
+)
+
+func (k SymbolKind) String() string {
+	switch k {
+	case SchemaSymbol:
+		return "Schema"
+	case PropertySymbol:
+		return "Property"
+	case VertexSymbol:
+		return "Vertex"
+	case EdgeSymbol:
+		return "Edge"
+	case AliasSymbol:
+		return "Alias"
+	default:
+		return ""
+	}
+}
+
+// Symbol is a name defined by a program
+type Symbol struct {
+	Kind       SymbolKind
+	Name       string
+	Span       nodes.Span    // where the name is defined
+	Node       nodes.ASTNode // node defining the name, a VertexTermNode for an alias
+	Schema     *Symbol       // of a property, a vertex or the vertices bound to an alias, nil when it is not known
+	Type       string        // type of a property, empty for one computed by an algorithm
+	Properties []*Symbol     // of a schema, in the order they are defined
+}
+
+// property returns the property of the schema with the name, nil when there is none
+func (s *Symbol) property(name string) *Symbol {
+	if s == nil {
+		return nil
+	}
+	for _, p := range s.Properties {
+		if p.Name == name {
+			return p
+		}
+	}
+	return nil
+}
+
+// Reference is a name written in a program and the symbol it refers to
+type Reference struct {
+	Span   nodes.Span
+	Symbol *Symbol
+}
+
+// Resolver binds the names written in a program to their definitions
+// without running it, for the tools editing programs. The definitions are
+// checked by the AppManager when they are written; the Resolver reports the
+// names of queries and calls that refer to nothing.
+type Resolver struct {
+	Symbols    []*Symbol    // in the order they are defined
+	References []*Reference // in the order they are written
+	Errors     []error      // a *PositionedError for every name that refers to nothing
+
+	schemas  map[string]*Symbol
+	vertices map[string]*Symbol
+	edges    map[string]*Symbol
+	aliases  []*Symbol // bound in the query resolved, innermost last
+	scoped   []*Symbol // schemas of the vertex terms around the node resolved, nil when not known
+	outer    *Symbol   // schema of the vertex the query returns
+	outers   int       // vertex terms at the top of the query
+}
+
+func NewResolver() *Resolver {
+	return &Resolver{
+		schemas:  make(map[string]*Symbol),
+		vertices: make(map[string]*Symbol),
+		edges:    make(map[string]*Symbol),
+	}
+}
+
+// Resolve binds the names of a statement or of a program, the statements
+// are resolved in order and see the definitions of the ones before them
+func (r *Resolver) Resolve(node nodes.ASTNode) {
+	node.Accept(r)
+}
+
+// SymbolAt returns the symbol defined or referred to at the position,
+// nil when there is no name there
+func (r *Resolver) SymbolAt(p nodes.Position) (*Symbol, nodes.Span) {
+	for _, ref := range r.References {
+		if contains(ref.Span, p) {
+			return ref.Symbol, ref.Span
+		}
+	}
+	for _, s := range r.Symbols {
+		if contains(s.Span, p) {
+			return s, s.Span
+		}
+	}
+	return nil, nodes.Span{}
+}
+
+// Names returns the names of the symbols of the kind, sorted
+func (r *Resolver) Names(kind SymbolKind) []string {
+	seen := make(map[string]bool)
+	var names []string
+	for _, s := range r.Symbols {
+		if s.Kind == kind && !seen[s.Name] {
+			seen[s.Name] = true
+			names = append(names, s.Name)
+		}
+	}
+
+	sort.Strings(names)
+	return names
+}
+
+// contains reports whether the position is in the span or right after it,
+// where the cursor is once a name is typed
+func contains(s nodes.Span, p nodes.Position) bool {
+	if s.IsZero() || s.Start.Row != p.Row || s.End.Row != p.Row {
+		return false
+	}
+	return s.Start.Col <= p.Col && p.Col <= s.End.Col
+}
+
+func (r *Resolver) define(kind SymbolKind, name *nodes.StringNode, node nodes.ASTNode) *Symbol {
+	symbol := &Symbol{Kind: kind, Name: name.Value, Span: name.Span, Node: node}
+	r.Symbols = append(r.Symbols, symbol)
+	return symbol
+}
+
+func (r *Resolver) refer(name *nodes.StringNode, symbol *Symbol) {
+	r.References = append(r.References, &Reference{Span: name.Span, Symbol: symbol})
+}
+
+func (r *Resolver) lookup(alias string) *Symbol {
+	for i := len(r.aliases) - 1; i >= 0; i-- {
+		if r.aliases[i].Name == alias {
+			return r.aliases[i]
+		}
+	}
+	return nil
+}
+
+func (r *Resolver) aliasNames() []string {
+	names := make([]string, 0, len(r.aliases))
+	for _, a := range r.aliases {
+		names = append(names, a.Name)
+	}
+	return names
+}
+
+// vertexTerm returns the schema of the vertices named by an alias, a
+// schema or a vertex, and whether the name refers to anything
+func (r *Resolver) vertexTerm(name *nodes.StringNode) (*Symbol, bool) {
+	if alias := r.lookup(name.Value); alias != nil {
+		r.refer(name, alias)
+		return alias.Schema, true
+	}
+	if schema, ok := r.schemas[name.Value]; ok {
+		r.refer(name, schema)
+		return schema, true
+	}
+	if vertex, ok := r.vertices[name.Value]; ok {
+		r.refer(name, vertex)
+		return vertex.Schema, true
+	}
+	return nil, false
+}
+
+func (r *Resolver) VisitProgramNode(node *nodes.ProgramStatementNode) {
+	for _, child := range node.Children {
+		child.Accept(r)
+	}
+}
+
+func (r *Resolver) VisitIntNode(node *nodes.IntNode)             {}
+func (r *Resolver) VisitFloatNode(node *nodes.FloatNode)         {}
+func (r *Resolver) VisitBoolNode(node *nodes.BoolNode)           {}
+func (r *Resolver) VisitNullNode(node *nodes.NullNode)           {}
+func (r *Resolver) VisitListNode(node *nodes.ListNode)           {}
+func (r *Resolver) VisitParameterNode(node *nodes.ParameterNode) {}
+func (r *Resolver) VisitPathNode(node *nodes.PathNode)           {}
+func (r *Resolver) VisitStringNode(node *nodes.StringNode)       {}
+func (r *Resolver) VisitVertexNode(node *nodes.VertexNode)       {}
+
+func (r *Resolver) VisitSchemaDefNode(node *nodes.SchemaDefNode) {
+	if _, ok := r.schemas[node.SchemaName.Value]; ok {
+		return
+	}
+	schema := r.define(SchemaSymbol, node.SchemaName, node)
+	r.schemas[schema.Name] = schema
+	for _, p := range node.Properties {
+		def := p.(*nodes.PropertyDefNode)
+		property := r.define(PropertySymbol, def.PropertyName, def)
+		property.Schema, property.Type = schema, def.PropertyType.Value
+		schema.Properties = append(schema.Properties, property)
+	}
+}
+
+func (r *Resolver) VisitEdgeDefNode(node *nodes.EdgeDefNode) {
+	if _, ok := r.edges[node.EdgeName.Value]; ok {
+		return
+	}
+	r.edges[node.EdgeName.Value] = r.define(EdgeSymbol, node.EdgeName, node)
+}
+
+func (r *Resolver) VisitRelationInitNode(node *nodes.RelationInitNode) {
+	for _, name := range []*nodes.StringNode{node.LeftVertex, node.RightVertex} {
+		if vertex, ok := r.vertices[name.Value]; ok {
+			r.refer(name, vertex)
+		}
+	}
+	if edge, ok := r.edges[node.Relation.Value]; ok {
+		r.refer(node.Relation, edge)
+	}
+}
+
+func (r *Resolver) VisitPropertyDefNode(node *nodes.PropertyDefNode)   {}
+func (r *Resolver) VisitPropertyInitNode(node *nodes.PropertyInitNode) {}
+
+func (r *Resolver) VisitVertexInitNode(node *nodes.VertexInitNode) {
+	schema, ok := r.schemas[node.SchemaName.Value]
+	if ok {
+		r.refer(node.SchemaName, schema)
+		for _, p := range node.Properties {
+			init := p.(*nodes.PropertyInitNode)
+			if property := schema.property(init.PropertyName.Value); property != nil {
+				r.refer(init.PropertyName, property)
+			}
+		}
+	}
+
+	if _, ok := r.vertices[node.VertexName.Value]; ok {
+		return
+	}
+	vertex := r.define(VertexSymbol, node.VertexName, node)
+	vertex.Schema = schema
+	r.vertices[vertex.Name] = vertex
+}
+
+func (r *Resolver) VisitEdgeNode(node *nodes.EdgeNode) {
+	if node.EdgeName == nil {
+		return
+	}
+	if edge, ok := r.edges[node.EdgeName.Value]; ok {
+		r.refer(node.EdgeName, edge)
+		return
+	}
+	r.Errors = append(r.Errors, misspelt(node.EdgeName, fmt.Errorf("%w: %s", manager.EdgeDoesNotExist, node.EdgeName.Value), r.Names(EdgeSymbol)))
+}
+
+func (r *Resolver) VisitPropertyNode(node *nodes.PropertyNode) {
+	var schema *Symbol
+	if len(r.scoped) > 0 {
+		schema = r.scoped[len(r.scoped)-1]
+	}
+	if node.Alias != nil {
+		alias := r.lookup(node.Alias.Value)
+		if alias == nil {
+			r.Errors = append(r.Errors, misspelt(node.Alias, fmt.Errorf("%w: %s", UnknownName, node.Alias.Value), r.aliasNames()))
+			return
+		}
+		r.refer(node.Alias, alias)
+		schema = alias.Schema
+	}
+	if schema == nil {
+		return
+	}
+
+	if property := schema.property(node.PropertyName.Value); property != nil {
+		r.refer(node.PropertyName, property)
+		return
+	}
+	names := make([]string, 0, len(schema.Properties))
+	for _, p := range schema.Properties {
+		names = append(names, p.Name)
+	}
+	r.Errors = append(r.Errors, misspelt(node.PropertyName,
+		fmt.Errorf("%w: %s of %s", manager.PropertyDoesNotExist, node.PropertyName.Value, schema.Name), names))
+}
+
+func (r *Resolver) VisitBinaryNode(node *nodes.BinaryNode) {
+	node.LeftChild.Accept(r)
+	node.RightChild.Accept(r)
+}
+
+func (r *Resolver) VisitVertexTermNode(node *nodes.VertexTermNode) {
+	var schema *Symbol
+	if name := node.Vertex.VertexName; name != nil && name.Value != "" {
+		var ok bool
+		if schema, ok = r.vertexTerm(name); !ok {
+			names := append(r.aliasNames(), r.Names(SchemaSymbol)...)
+			names = append(names, r.Names(VertexSymbol)...)
+			r.Errors = append(r.Errors, misspelt(name, fmt.Errorf("%w: %s", UnknownName, name.Value), names))
+		}
+	}
+	if len(r.scoped) == 0 {
+		// the query returns the vertices of its outer terms, their
+		// properties are only known when they share a schema
+		if r.outers == 0 || r.outer == schema {
+			r.outer = schema
+		} else {
+			r.outer = nil
+		}
+		r.outers++
+	}
+
+	// an alias bound again shadows the outer binding inside the term only
+	bound := len(r.aliases)
+	shadowing := false
+	if alias := node.Vertex.Alias; alias != nil && alias.Value != "" {
+		shadowing = r.lookup(alias.Value) != nil
+		symbol := r.define(AliasSymbol, alias, node)
+		symbol.Schema = schema
+		r.aliases = append(r.aliases, symbol)
+	}
+
+	if node.Conditions != nil {
+		r.scoped = append(r.scoped, schema)
+		node.Conditions.Accept(r)
+		r.scoped = r.scoped[:len(r.scoped)-1]
+	}
+	if shadowing {
+		r.aliases = r.aliases[:bound]
+	}
+}
+
+func (r *Resolver) VisitRelationNode(node *nodes.RelationNode) {
+	node.Edge.Accept(r)
+	node.Vertex.Accept(r)
+}
+
+func (r *Resolver) VisitQueryStatement(node *nodes.QueryStatementNode) {
+	r.aliases, r.scoped, r.outer, r.outers = nil, nil, nil, 0
+	node.Expression.Accept(r)
+
+	// the clauses are evaluated on the rows of the query
+	r.scoped = []*Symbol{r.outer}
+	for _, expression := range node.GroupBy {
+		expression.Accept(r)
+	}
+	if node.Having != nil {
+		node.Having.Accept(r)
+	}
+	for _, expression := range node.Return {
+		expression.Accept(r)
+	}
+	r.aliases, r.scoped, r.outer, r.outers = nil, nil, nil, 0
+}
+
+func (r *Resolver) VisitExplainStatement(node *nodes.ExplainStatementNode) {
+	node.Query.Accept(r)
+}
+
+func (r *Resolver) VisitProfileStatement(node *nodes.ProfileStatementNode) {
+	node.Query.Accept(r)
+}
+
+func (r *Resolver) VisitCallStatement(node *nodes.CallStatementNode) {
+	if _, err := algorithms.GetAlgorithm(node.Procedure.Value); err != nil {
+		r.Errors = append(r.Errors, misspelt(node.Procedure,
+			fmt.Errorf("%w: %s", algorithms.UnknownAlgorithm, node.Procedure.Value), algorithms.GetAllAlgorithms()))
+	}
+
+	// Unit runs the algorithm on the vertices of every schema
+	var schemas []*Symbol
+	for _, s := range r.Symbols {
+		if s.Kind == SchemaSymbol {
+			schemas = append(schemas, s)
+		}
+	}
+	if len(node.Args) > 0 {
+		if name, ok := node.Args[0].(*nodes.StringNode); ok && name.Value != "" {
+			if schema, found := r.schemas[name.Value]; found {
+				r.refer(name, schema)
+				schemas = []*Symbol{schema}
+			} else {
+				schemas = nil
+				r.Errors = append(r.Errors, misspelt(name, fmt.Errorf("%w: %s", manager.SchemaDoesNotExist, name.Value), r.Names(SchemaSymbol)))
+			}
+		}
+	}
+	if len(node.Args) > 1 {
+		if name, ok := node.Args[1].(*nodes.StringNode); ok && name.Value != "" {
+			if edge, found := r.edges[name.Value]; found {
+				r.refer(name, edge)
+			} else {
+				r.Errors = append(r.Errors, misspelt(name, fmt.Errorf("%w: %s", manager.EdgeDoesNotExist, name.Value), r.Names(EdgeSymbol)))
+			}
+		}
+	}
+
+	// the property written is known to the queries after the call
+	if node.Write == nil || len(schemas) == 0 {
+		return
+	}
+	if property := schemas[0].property(node.Write.Value); property != nil {
+		r.refer(node.Write, property)
+		return
+	}
+	property := r.define(PropertySymbol, node.Write, node)
+	if len(schemas) == 1 {
+		property.Schema = schemas[0]
+	}
+	for _, schema := range schemas {
+		if schema.property(property.Name) == nil {
+			schema.Properties = append(schema.Properties, property)
+		}
+	}
+}
+
+func (r *Resolver) resolveArgs(args []nodes.ASTNode) {
+	for _, arg := range args {
+		arg.Accept(r)
+	}
+}
+
+func (r *Resolver) VisitSumFunc(node *nodes.SumFuncNode)       { r.resolveArgs(node.Args) }
+func (r *Resolver) VisitCountFunc(node *nodes.CountFuncNode)   { r.resolveArgs(node.Args) }
+func (r *Resolver) VisitAvgFunc(node *nodes.AvgFuncNode)       { r.resolveArgs(node.Args) }
+func (r *Resolver) VisitMaxFunc(node *nodes.MaxFuncNode)       { r.resolveArgs(node.Args) }
+func (r *Resolver) VisitMinFunc(node *nodes.MinFuncNode)       { r.resolveArgs(node.Args) }
+func (r *Resolver) VisitLengthFunc(node *nodes.LengthFuncNode) { r.resolveArgs(node.Args) }
+func (r *Resolver) VisitNodesFunc(node *nodes.NodesFuncNode)   { r.resolveArgs(node.Args) }
+func (r *Resolver) VisitEdgesFunc(node *nodes.EdgesFuncNode)   { r.resolveArgs(node.Args) }
+
+// the weight of a shortest path is a property of the relations, they have no schema
+func (r *Resolver) VisitShortestPathFunc(node *nodes.ShortestPathFuncNode) {
+	r.resolveArgs(node.Args[:min(3, len(node.Args))])
+}
+
+func (r *Resolver) VisitAllShortestPathsFunc(node *nodes.AllShortestPathsFuncNode) {
+	r.resolveArgs(node.Args[:min(3, len(node.Args))])
+}
@@ -0,0 +1,110 @@
+package visitors
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/Jintumoni/vortex/lexer"
+	"github.com/Jintumoni/vortex/manager"
+	"github.com/Jintumoni/vortex/nodes"
+	"github.com/Jintumoni/vortex/parser"
+	"github.com/stretchr/testify/assert"
+)
+
+// resolve resolves testGraph followed by the program written in src
+func resolve(t *testing.T, src string) *Resolver {
+	root, err := parser.NewParser(lexer.NewLexer(strings.NewReader(testGraph + src))).Parse()
+	assert.NoError(t, err)
+
+	r := NewResolver()
+	r.Resolve(root)
+	return r
+}
+
+// at returns the position of the n-th occurrence of s in the program
+// resolved by resolve, counted from 0
+func at(src, s string, n int) nodes.Position {
+	text := testGraph + src
+	offset := -1
+	for i := 0; i <= n; i++ {
+		offset += 1 + strings.Index(text[offset+1:], s)
+	}
+	row := strings.Count(text[:offset], "\n")
+	return nodes.Position{Row: row, Col: offset - strings.LastIndex(text[:offset], "\n") - 1}
+}
+
+func TestResolveDefinitions(t *testing.T) {
+	r := resolve(t, "")
+	assert.Equal(t, []string{"City", "Country", "Person"}, r.Names(SchemaSymbol))
+	assert.Equal(t, []string{"FriendsWith", "LivesIn", "Road", "Within"}, r.Names(EdgeSymbol))
+	assert.Equal(t, []string{"age", "name"}, r.Names(PropertySymbol))
+	assert.Contains(t, r.Names(VertexSymbol), "Ann")
+	assert.Empty(t, r.Errors)
+
+	// the endpoints of a relation refer to their vertices
+	position := at("", "Ann Bob", 0)
+	symbol, span := r.SymbolAt(position)
+	assert.Equal(t, VertexSymbol, symbol.Kind)
+	assert.Equal(t, "Ann", symbol.Name)
+	assert.Equal(t, at("", "Ann Person", 0), symbol.Span.Start)
+	assert.Equal(t, position, span.Start)
+
+	symbol, _ = r.SymbolAt(at("", "Relation FriendsWith", 0))
+	assert.Nil(t, symbol)
+}
+
+func TestResolveQueries(t *testing.T) {
+	src := `Query Person as P { []FriendsWith Person { .age < P.age } } Return P.name`
+	r := resolve(t, src)
+	assert.Empty(t, r.Errors)
+
+	// P refers to its binding
+	alias, _ := r.SymbolAt(at(src, "P.age", 0))
+	assert.Equal(t, AliasSymbol, alias.Kind)
+	assert.Equal(t, at(src, "P {", 0), alias.Span.Start)
+	assert.Equal(t, "Person", alias.Schema.Name)
+
+	// the properties refer to the definitions of the schema, with their type
+	property, _ := r.SymbolAt(at(src, "age <", 0))
+	assert.Equal(t, PropertySymbol, property.Kind)
+	assert.Equal(t, "int", property.Type)
+	assert.Equal(t, "Person", property.Schema.Name)
+	property, _ = r.SymbolAt(at(src, "name", 0))
+	assert.Equal(t, "string", property.Type)
+
+	edge, _ := r.SymbolAt(at(src, "FriendsWith", 0))
+	assert.Equal(t, EdgeSymbol, edge.Kind)
+}
+
+func TestResolveReportsUnknownNames(t *testing.T) {
+	r := resolve(t, `Query Persn as P1 { []FriendWith City { .nmae = "a" } and P2.age > 1 }
+Call PageRank(Persn, FriendsWith)`)
+
+	var messages []string
+	for _, err := range r.Errors {
+		var positioned *PositionedError
+		assert.ErrorAs(t, err, &positioned)
+		assert.False(t, positioned.Span.IsZero())
+		messages = append(messages, err.Error())
+	}
+	assert.Equal(t, []string{
+		`33:7: Unknown alias, schema or vertex: Persn; did you mean "Person"?`,
+		`33:23: Edge missing: FriendWith; did you mean "FriendsWith"?`,
+		`33:42: Property missing: nmae of City; did you mean "name"?`,
+		`33:59: Unknown alias, schema or vertex: P2; did you mean "P1"?`,
+		`34:15: Schema missing: Persn; did you mean "Person"?`,
+	}, messages)
+	assert.ErrorIs(t, r.Errors[1], manager.EdgeDoesNotExist)
+}
+
+func TestResolveComputedProperties(t *testing.T) {
+	r := resolve(t, `Call ConnectedComponents(Person, FriendsWith) Write .component
+Query Person { .component = 0 }
+Call PageRank((), ()) Write .rank
+Query City { .rank > 0 }`)
+	assert.Empty(t, r.Errors)
+
+	// an alias bound again shadows the outer one in its term only
+	r = resolve(t, `Query Person as A { []LivesIn City as A { A.name = "Paris" } and A.age > 1 }`)
+	assert.Empty(t, r.Errors)
+}
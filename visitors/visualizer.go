@@ -2,6 +2,9 @@ package visitors
 
 import (
 	"fmt"
+	"io"
+	"math"
+	"os"
 	"strconv"
 	"strings"
 
@@ -11,24 +14,55 @@ import (
 )
 
 type Visualizer struct {
+	Output      io.Writer // the tree is drawn here
 	level       int
 	indentation int
 	lastChild   []int
 }
 
 func NewVisualizer() *Visualizer {
-	return &Visualizer{level: 0, indentation: 4, lastChild: []int{1}}
+	return &Visualizer{Output: os.Stdout, level: 0, indentation: 4, lastChild: []int{1}}
 }
 
 func (v *Visualizer) print(s string) {
 	for _, isLast := range v.lastChild[:len(v.lastChild)-1] {
 		if isLast != 0 {
-			fmt.Printf("┃%s", strings.Repeat(" ", v.indentation-1))
+			fmt.Fprintf(v.Output, "┃%s", strings.Repeat(" ", v.indentation-1))
 		} else {
-			fmt.Printf(strings.Repeat(" ", v.indentation))
+			fmt.Fprint(v.Output, strings.Repeat(" ", v.indentation))
 		}
 	}
-	fmt.Printf("┗━%s\n", s)
+	fmt.Fprintf(v.Output, "┗━%s\n", s)
+}
+
+// VisualizePlan draws a query plan made by the Planner
+func (v *Visualizer) VisualizePlan(op Operator) {
+	v.visualizeOperator(op, func(op Operator) string {
+		return fmt.Sprintf("%s (estimated rows: %s)", op, formatEstimate(op.EstimatedRows()))
+	})
+}
+
+// VisualizeProfile draws a query plan with the work done by each of its operators
+func (v *Visualizer) VisualizeProfile(op Operator, profile Profile) {
+	v.visualizeOperator(op, func(op Operator) string {
+		stats, ok := profile[op]
+		if !ok {
+			return fmt.Sprintf("%s (not run)", op)
+		}
+		return fmt.Sprintf("%s (rows in: %d, rows out: %d, adjacency entries: %d, index hits: %d, time: %s)",
+			op, stats.RowsIn, stats.RowsOut, stats.AdjacencyEntries, stats.IndexHits, stats.Time)
+	})
+}
+
+func (v *Visualizer) visualizeOperator(op Operator, describe func(Operator) string) {
+	v.print(describe(op))
+	v.shiftRight(len(op.Inputs()))
+
+	for _, input := range op.Inputs() {
+		v.visualizeOperator(input, describe)
+	}
+
+	v.shiftLeft()
 }
 
 func (v *Visualizer) shiftRight(childCnt int) {
@@ -57,6 +91,30 @@ func (v *Visualizer) VisitIntNode(node *nodes.IntNode) {
 	v.print(strconv.Itoa(node.Value))
 }
 
+func (v *Visualizer) VisitFloatNode(node *nodes.FloatNode) {
+	v.print(strconv.FormatFloat(node.Value, 'f', -1, 64))
+}
+
+func (v *Visualizer) VisitBoolNode(node *nodes.BoolNode) {
+	v.print(strconv.FormatBool(node.Value))
+}
+
+func (v *Visualizer) VisitNullNode(node *nodes.NullNode) {
+	v.print("null")
+}
+
+func (v *Visualizer) VisitListNode(node *nodes.ListNode) {
+	v.printList("List", node.Values)
+}
+
+func (v *Visualizer) VisitParameterNode(node *nodes.ParameterNode) {
+	v.print("$" + node.Name.Value)
+}
+
+func (v *Visualizer) VisitPathNode(node *nodes.PathNode) {
+	v.print(FormatValue(node))
+}
+
 func (v *Visualizer) VisitStringNode(node *nodes.StringNode) {
 	v.print(node.Value)
 }
@@ -94,10 +152,13 @@ func (v *Visualizer) VisitRelationInitNode(node *nodes.RelationInitNode) {
 	v.shiftRight(1)
 
 	v.print(node.Relation.Value)
-	v.shiftRight(2)
+	v.shiftRight(2 + len(node.Properties))
 
 	v.print(node.LeftVertex.Value)
 	v.print(node.RightVertex.Value)
+	for _, p := range node.Properties {
+		p.Accept(v)
+	}
 
 	v.shiftLeft()
 	v.shiftLeft()
@@ -127,7 +188,19 @@ func (v *Visualizer) VisitVertexInitNode(node *nodes.VertexInitNode) {
 }
 
 func (v *Visualizer) VisitEdgeNode(node *nodes.EdgeNode) {
-	v.print(fmt.Sprintf("%s %d..%d", node.EdgeName.Value, node.LowerBound.Value, node.UpperBound.Value))
+	edgeName := "()"
+	if node.EdgeName != nil {
+		edgeName = node.EdgeName.Value
+	}
+
+	upperBound := ""
+	if node.UpperBound.Value != math.MaxInt {
+		upperBound = strconv.Itoa(node.UpperBound.Value)
+	}
+	if node.Direction == nodes.Incoming || node.Direction == nodes.AnyDirection {
+		edgeName = node.Direction.String() + edgeName
+	}
+	v.print(fmt.Sprintf("%s %d..%s", edgeName, node.LowerBound.Value, upperBound))
 }
 
 func (v *Visualizer) VisitPropertyNode(node *nodes.PropertyNode) {
@@ -181,18 +254,117 @@ func (v *Visualizer) VisitRelationNode(node *nodes.RelationNode) {
 
 func (v *Visualizer) VisitQueryStatement(node *nodes.QueryStatementNode) {
 	v.print("QueryStatement")
-	v.shiftRight(1)
+
+	childCnt := 1
+	if node.GroupBy != nil {
+		childCnt++
+	}
+	if node.Having != nil {
+		childCnt++
+	}
+	if node.Return != nil {
+		childCnt++
+	}
+	v.shiftRight(childCnt)
 
 	node.Expression.Accept(v)
 
+	if node.GroupBy != nil {
+		v.printList("GroupBy", node.GroupBy)
+	}
+	if node.Having != nil {
+		v.print("Having")
+		v.shiftRight(1)
+		node.Having.Accept(v)
+		v.shiftLeft()
+	}
+	if node.Return != nil {
+		v.printList("Return", node.Return)
+	}
+
 	v.shiftLeft()
 }
 
-func (v *Visualizer) VisitSumFunc(node *nodes.SumFuncNode) {
-	v.print("Sum: BuiltinFunc")
-	v.shiftRight(len(node.Args))
-	for _, arg := range node.Args {
-		arg.Accept(v)
+func (v *Visualizer) VisitExplainStatement(node *nodes.ExplainStatementNode) {
+	v.print("ExplainStatement")
+	v.shiftRight(1)
+
+	node.Query.Accept(v)
+
+	v.shiftLeft()
+}
+
+func (v *Visualizer) VisitProfileStatement(node *nodes.ProfileStatementNode) {
+	v.print("ProfileStatement")
+	v.shiftRight(1)
+
+	node.Query.Accept(v)
+
+	v.shiftLeft()
+}
+
+func (v *Visualizer) VisitCallStatement(node *nodes.CallStatementNode) {
+	v.print("CallStatement")
+
+	childCnt := 1
+	if node.Write != nil {
+		childCnt++
+	}
+	v.shiftRight(childCnt)
+
+	v.printList(node.Procedure.Value, node.Args)
+	if node.Write != nil {
+		v.print(fmt.Sprintf("Write .%s", node.Write.Value))
+	}
+
+	v.shiftLeft()
+}
+
+func (v *Visualizer) printList(s string, children []nodes.ASTNode) {
+	v.print(s)
+	v.shiftRight(len(children))
+	for _, c := range children {
+		c.Accept(v)
 	}
 	v.shiftLeft()
 }
+
+func (v *Visualizer) VisitSumFunc(node *nodes.SumFuncNode) {
+	v.printList("Sum: BuiltinFunc", node.Args)
+}
+
+func (v *Visualizer) VisitCountFunc(node *nodes.CountFuncNode) {
+	v.printList("Count: BuiltinFunc", node.Args)
+}
+
+func (v *Visualizer) VisitAvgFunc(node *nodes.AvgFuncNode) {
+	v.printList("Avg: BuiltinFunc", node.Args)
+}
+
+func (v *Visualizer) VisitMaxFunc(node *nodes.MaxFuncNode) {
+	v.printList("Max: BuiltinFunc", node.Args)
+}
+
+func (v *Visualizer) VisitMinFunc(node *nodes.MinFuncNode) {
+	v.printList("Min: BuiltinFunc", node.Args)
+}
+
+func (v *Visualizer) VisitShortestPathFunc(node *nodes.ShortestPathFuncNode) {
+	v.printList("ShortestPath: BuiltinFunc", node.Args)
+}
+
+func (v *Visualizer) VisitAllShortestPathsFunc(node *nodes.AllShortestPathsFuncNode) {
+	v.printList("AllShortestPaths: BuiltinFunc", node.Args)
+}
+
+func (v *Visualizer) VisitLengthFunc(node *nodes.LengthFuncNode) {
+	v.printList("Length: BuiltinFunc", node.Args)
+}
+
+func (v *Visualizer) VisitNodesFunc(node *nodes.NodesFuncNode) {
+	v.printList("Nodes: BuiltinFunc", node.Args)
+}
+
+func (v *Visualizer) VisitEdgesFunc(node *nodes.EdgesFuncNode) {
+	v.printList("Edges: BuiltinFunc", node.Args)
+}
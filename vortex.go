@@ -0,0 +1,224 @@
+// Package vortex embeds a Vortex graph database in a Go program.
+//
+//	db, err := vortex.Open("/data/graph", nil)
+//	if err != nil {
+//		return err
+//	}
+//	defer db.Close()
+//
+//	err = db.Exec(ctx, `Vertex Ann Person { .name = "Ann" .age = 30 }`)
+//	rows, err := db.Query(ctx, `Query Person { .age > $age } Return .name`, map[string]any{"age": 18})
+package vortex
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/Jintumoni/vortex/executor"
+	"github.com/Jintumoni/vortex/lexer"
+	"github.com/Jintumoni/vortex/manager"
+	"github.com/Jintumoni/vortex/nodes"
+	"github.com/Jintumoni/vortex/parser"
+	"github.com/Jintumoni/vortex/visitors"
+)
+
+var (
+	Closed     = errors.New("Database is closed")
+	ReadOnly   = errors.New("Database is read only")
+	NotAQuery  = errors.New("Program is not a single query statement")
+	CorruptLog = errors.New("Database log is corrupt")
+)
+
+// LogFile is the name of the file a database keeps its programs in
+const LogFile = "vortex.log"
+
+// Options configures a database, the zero value is a writable database
+type Options struct {
+	ReadOnly bool // Exec fails, the database can only be queried
+	// Limits bound every query of the programs that do not change the graph.
+	// A program changing it runs to its end, like it does when the log is replayed.
+	Limits visitors.Limits
+}
+
+// DB is a graph database. Its definitions are kept in memory and every
+// program changing the graph is appended to a log in the directory of the
+// database, which is replayed by Open. A DB is safe for concurrent use.
+type DB struct {
+	options    Options
+	appManager *manager.AppManager
+	log        *os.File // nil for a database held only in memory
+
+	mu     sync.RWMutex
+	closed bool
+	// plans of the queries by source, cleared when the graph changes
+	queries   map[string]visitors.Operator
+	queriesMu sync.Mutex
+}
+
+// Open opens the database kept in dir, creating it if it does not exist.
+// An empty dir opens a database held only in memory. opts may be nil.
+func Open(dir string, opts *Options) (*DB, error) {
+	db := &DB{
+		appManager: manager.NewAppManager(),
+		queries:    make(map[string]visitors.Operator),
+	}
+	if opts != nil {
+		db.options = *opts
+	}
+	if dir == "" {
+		return db, nil
+	}
+
+	flag := os.O_RDONLY
+	if !db.options.ReadOnly {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return nil, err
+		}
+		flag = os.O_CREATE | os.O_RDWR | os.O_APPEND
+	}
+	log, err := os.OpenFile(filepath.Join(dir, LogFile), flag, 0644)
+	if err != nil {
+		return nil, err
+	}
+	if err := db.replay(log); err != nil {
+		log.Close()
+		return nil, err
+	}
+	db.log = log
+	return db, nil
+}
+
+// Close closes the log of the database, the database can not be used afterwards
+func (db *DB) Close() error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	if db.closed {
+		return Closed
+	}
+	db.closed = true
+	if db.log != nil {
+		return db.log.Close()
+	}
+	return nil
+}
+
+// Ping reports whether the database can be used, it fails once it is closed
+func (db *DB) Ping() error {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	if db.closed {
+		return Closed
+	}
+	return nil
+}
+
+// Exec runs a program that defines schemas, edges, vertices and relations,
+// or writes computed properties with Call. The results of the queries in it
+// are discarded. The statements run until the first one that fails, the
+// ones before it are kept.
+func (db *DB) Exec(ctx context.Context, src string) error {
+	return db.run(ctx, src, nil, false, func(*executor.Result) error { return nil })
+}
+
+// Run runs a program with the values of its $name parameters and calls emit
+// with the result of every statement, until the first one that fails.
+//
+// A program that changes the graph runs alone and is not cancelled once it
+// started, ctx is only checked before it. Other programs run alongside each
+// other and ctx is checked before every statement.
+func (db *DB) Run(ctx context.Context, src string, params map[string]any, emit func(*executor.Result) error) error {
+	return db.run(ctx, src, params, false, emit)
+}
+
+// RunReadOnly is Run for programs that do not change the graph, the other
+// programs fail with ReadOnly
+func (db *DB) RunReadOnly(ctx context.Context, src string, params map[string]any, emit func(*executor.Result) error) error {
+	return db.run(ctx, src, params, true, emit)
+}
+
+func (db *DB) run(ctx context.Context, src string, params map[string]any, readOnly bool, emit func(*executor.Result) error) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	stmt, err := db.Prepare(src)
+	if err != nil {
+		return err
+	}
+	return stmt.run(ctx, params, readOnly, emit)
+}
+
+// Query runs a single query statement with the values of its $name
+// parameters and returns its rows. params may be nil.
+func (db *DB) Query(ctx context.Context, src string, params map[string]any) (*Rows, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	if db.closed {
+		return nil, Closed
+	}
+
+	plan, err := db.prepare(src)
+	if err != nil {
+		return nil, err
+	}
+	parameters, err := visitors.Parameters(params)
+	if err != nil {
+		return nil, err
+	}
+
+	evaluator := visitors.NewEvaluator(db.appManager)
+	evaluator.Parameters = parameters
+	evaluator.Limits = db.options.Limits
+	result, err := evaluator.RunContext(ctx, plan)
+	if err != nil {
+		return nil, err
+	}
+	return &Rows{columns: result.Columns, rows: result.Rows}, nil
+}
+
+// prepare parses and plans the query written in src, or returns the one prepared before
+func (db *DB) prepare(src string) (visitors.Operator, error) {
+	db.queriesMu.Lock()
+	defer db.queriesMu.Unlock()
+
+	if plan, ok := db.queries[src]; ok {
+		return plan, nil
+	}
+
+	root, err := parser.NewParser(lexer.NewLexer(strings.NewReader(src))).Parse()
+	if err != nil {
+		return nil, err
+	}
+	program := root.(*nodes.ProgramStatementNode)
+	if len(program.Children) != 1 {
+		return nil, NotAQuery
+	}
+	query, ok := program.Children[0].(*nodes.QueryStatementNode)
+	if !ok {
+		return nil, NotAQuery
+	}
+	plan, err := visitors.NewPlanner(db.appManager).Plan(query)
+	if err != nil {
+		return nil, err
+	}
+
+	db.queries[src] = plan
+	return plan, nil
+}
+
+func (db *DB) executor(src string) *executor.Executor {
+	q := executor.NewExecutor(db.appManager, parser.NewParser(lexer.NewLexer(strings.NewReader(src))))
+	q.Output = io.Discard
+	return q
+}
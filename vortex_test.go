@@ -0,0 +1,172 @@
+package vortex
+
+import (
+	"context"
+	"testing"
+
+	"github.com/Jintumoni/vortex/executor"
+	"github.com/Jintumoni/vortex/manager"
+	"github.com/Jintumoni/vortex/visitors"
+	"github.com/stretchr/testify/assert"
+)
+
+const testGraph = `
+Schema Person {
+  name string
+  age  int
+}
+Schema City {
+  name string
+}
+
+Vertex Ann Person { .name = "Ann" .age = 30 }
+Vertex Bob Person { .name = "Bob" .age = 20 }
+Vertex London City { .name = "London" }
+
+Edge LivesIn OneWay
+
+Relation LivesIn { Ann London }
+Relation LivesIn { Bob London }
+`
+
+func TestQuery(t *testing.T) {
+	ctx := context.Background()
+	db, err := Open("", nil)
+	assert.NoError(t, err)
+	defer db.Close()
+	assert.NoError(t, db.Exec(ctx, testGraph))
+
+	_, err = db.Query(ctx, `Query Person { .age > $age } Return P`, map[string]any{"age": 18})
+	assert.ErrorIs(t, err, visitors.UnknownName)
+
+	rows, err := db.Query(ctx, `Query Person { .age > $age } Return .name, .age`, map[string]any{"age": 18})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{".name", ".age"}, rows.Columns())
+
+	var names []string
+	var ages []int
+	for rows.Next() {
+		var name string
+		var age int
+		assert.NoError(t, rows.Scan(&name, &age))
+		names = append(names, name)
+		ages = append(ages, age)
+	}
+	assert.Equal(t, []string{"Ann", "Bob"}, names)
+	assert.Equal(t, []int{30, 20}, ages)
+
+	rows, err = db.Query(ctx, `Query Person { []LivesIn City as C } Group By C Return C, Count(Person)`, nil)
+	assert.NoError(t, err)
+	assert.True(t, rows.Next())
+	var city bool
+	assert.ErrorIs(t, rows.Scan(&city, new(int)), ScanTypeError)
+	values, err := rows.Values()
+	assert.NoError(t, err)
+	assert.Equal(t, []any{"London", 2}, values)
+}
+
+func TestQueryIsASingleQuery(t *testing.T) {
+	db, err := Open("", nil)
+	assert.NoError(t, err)
+	defer db.Close()
+
+	_, err = db.Query(context.Background(), testGraph, nil)
+	assert.ErrorIs(t, err, NotAQuery)
+}
+
+func TestOpenReplaysTheLog(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+
+	db, err := Open(dir, nil)
+	assert.NoError(t, err)
+	assert.NoError(t, db.Exec(ctx, testGraph))
+	// fails at the relation, the vertex before it is kept
+	assert.ErrorIs(t, db.Exec(ctx, `Vertex Cid Person { .name = "Cid" .age = 41 } Relation LivesIn { Cid Paris }`), manager.VertexDoesNotExist)
+	assert.NoError(t, db.Close())
+	assert.ErrorIs(t, db.Exec(ctx, testGraph), Closed)
+
+	db, err = Open(dir, &Options{ReadOnly: true})
+	assert.NoError(t, err)
+	defer db.Close()
+	assert.ErrorIs(t, db.Exec(ctx, testGraph), ReadOnly)
+
+	rows, err := db.Query(ctx, `Query Person Return .name`, nil)
+	assert.NoError(t, err)
+	var names []string
+	for rows.Next() {
+		var name string
+		assert.NoError(t, rows.Scan(&name))
+		names = append(names, name)
+	}
+	assert.Equal(t, []string{"Ann", "Bob", "Cid"}, names)
+}
+
+func TestContextIsChecked(t *testing.T) {
+	db, err := Open("", nil)
+	assert.NoError(t, err)
+	defer db.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	assert.ErrorIs(t, db.Exec(ctx, testGraph), context.Canceled)
+	_, err = db.Query(ctx, `Query Person`, nil)
+	assert.ErrorIs(t, err, context.Canceled)
+}
+
+func TestRun(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	db, err := Open(dir, nil)
+	assert.NoError(t, err)
+	assert.NoError(t, db.Exec(ctx, testGraph))
+
+	var results []*executor.Result
+	emit := func(result *executor.Result) error {
+		results = append(results, result)
+		return nil
+	}
+	// the query is replayed with its parameter, the vertex after it is kept
+	program := `Query Person { .age > $age } Vertex Cid Person { .name = "Cid" .age = 41 }`
+	assert.NoError(t, db.Run(ctx, program, map[string]any{"age": 1.5}, emit))
+	assert.Len(t, results, 2)
+	assert.Equal(t, 2, len(results[0].Rows.Rows))
+	assert.Nil(t, results[1].Rows)
+
+	assert.ErrorIs(t, db.RunReadOnly(ctx, program, nil, emit), ReadOnly)
+	assert.NoError(t, db.RunReadOnly(ctx, `Explain Query Person`, nil, emit))
+	assert.NotNil(t, results[2].Plan)
+	assert.NoError(t, db.Close())
+
+	db, err = Open(dir, nil)
+	assert.NoError(t, err)
+	defer db.Close()
+	// a subquery is not an operator of the plan
+	rows, err := db.Query(ctx, `Query Count(Person)`, nil)
+	assert.NoError(t, err)
+	assert.True(t, rows.Next())
+	var count int
+	assert.NoError(t, rows.Scan(&count))
+	assert.Equal(t, 3, count)
+}
+
+func TestLimits(t *testing.T) {
+	ctx := context.Background()
+	db, err := Open("", &Options{Limits: visitors.Limits{MaxRows: 1}})
+	assert.NoError(t, err)
+	defer db.Close()
+
+	// the query of a program changing the graph is not limited
+	assert.NoError(t, db.Exec(ctx, testGraph+`Query Person`))
+
+	var limit *visitors.LimitError
+	_, err = db.Query(ctx, `Query Person`, nil)
+	assert.ErrorAs(t, err, &limit)
+	err = db.RunReadOnly(ctx, `Query Person`, nil, func(*executor.Result) error { return nil })
+	assert.ErrorAs(t, err, &limit)
+
+	// a subquery is not an operator of the plan
+	rows, err := db.Query(ctx, `Query Count(Person)`, nil)
+	assert.NoError(t, err)
+	assert.True(t, rows.Next())
+}
@@ -0,0 +1,393 @@
+package wire
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"sync"
+)
+
+var (
+	ConnClosed    = errors.New("Connection is closed")
+	NoRow         = errors.New("Scan called without a row, call Next first")
+	ScanTypeError = errors.New("Value can not be scanned into the destination")
+)
+
+// Conn is a session opened on a server. It runs one request at a time, a
+// request holds the session until its results are read or closed.
+type Conn struct {
+	BatchSize int // rows per batch asked for, DefaultBatchSize when 0
+
+	conn    net.Conn
+	mu      sync.Mutex // held by the request running
+	writeMu sync.Mutex
+	nextID  uint64
+	err     error // why the connection can not be used anymore
+}
+
+// Dial opens a session on the server at the address, see Listen
+func Dial(ctx context.Context, address string) (*Conn, error) {
+	network, address := split(address)
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, network, address)
+	if err != nil {
+		return nil, err
+	}
+	return &Conn{conn: conn}, nil
+}
+
+// Close closes the session, its prepared statements and its transaction are dropped
+func (c *Conn) Close() error {
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+	if c.err == ConnClosed {
+		return ConnClosed
+	}
+	c.err = ConnClosed
+	return c.conn.Close()
+}
+
+// Err returns why the session can not be used anymore, nil while it can
+func (c *Conn) Err() error {
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+	return c.err
+}
+
+// Run runs a program with the values of its $name parameters
+func (c *Conn) Run(ctx context.Context, program string, params map[string]any) (*Results, error) {
+	return c.start(ctx, &Request{Type: RequestRun, Program: program, Parameters: params})
+}
+
+// RunReadOnly is Run for programs that do not change the graph, the other
+// programs fail with vortex.ReadOnly. They can be sent again safely.
+func (c *Conn) RunReadOnly(ctx context.Context, program string, params map[string]any) (*Results, error) {
+	return c.start(ctx, &Request{Type: RequestRun, Program: program, Parameters: params, ReadOnly: true})
+}
+
+// Prepare parses a program on the server, it is run with Stmt.Run
+func (c *Conn) Prepare(ctx context.Context, program string) (*Stmt, error) {
+	done, err := c.do(ctx, &Request{Type: RequestPrepare, Program: program})
+	if err != nil {
+		return nil, err
+	}
+	return &Stmt{conn: c, id: done.Prepared}, nil
+}
+
+// Begin opens a transaction. The programs changing the graph run on Commit,
+// one after the other and without any other program running between them.
+// The programs reading the graph run at once and do not see them.
+func (c *Conn) Begin(ctx context.Context) error {
+	_, err := c.do(ctx, &Request{Type: RequestBegin})
+	return err
+}
+
+// Commit runs the programs of the transaction and returns their results
+func (c *Conn) Commit(ctx context.Context) (*Results, error) {
+	return c.start(ctx, &Request{Type: RequestCommit})
+}
+
+// Rollback drops the programs of the transaction
+func (c *Conn) Rollback(ctx context.Context) error {
+	_, err := c.do(ctx, &Request{Type: RequestRollback})
+	return err
+}
+
+// Stmt is a program prepared in a session
+type Stmt struct {
+	conn *Conn
+	id   uint64
+}
+
+// Run runs the program with the values of its $name parameters
+func (s *Stmt) Run(ctx context.Context, params map[string]any) (*Results, error) {
+	return s.conn.start(ctx, &Request{Type: RequestExecute, Statement: s.id, Parameters: params})
+}
+
+// RunReadOnly is Run for a program that does not change the graph, see Conn.RunReadOnly
+func (s *Stmt) RunReadOnly(ctx context.Context, params map[string]any) (*Results, error) {
+	return s.conn.start(ctx, &Request{Type: RequestExecute, Statement: s.id, Parameters: params, ReadOnly: true})
+}
+
+// Close drops the statement from the session
+func (s *Stmt) Close(ctx context.Context) error {
+	_, err := s.conn.do(ctx, &Request{Type: RequestClose, Statement: s.id})
+	return err
+}
+
+// do runs a request without results and returns its Done response
+func (c *Conn) do(ctx context.Context, request *Request) (*Response, error) {
+	results, err := c.start(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+	for results.NextResult() {
+	}
+	if err := results.Err(); err != nil {
+		return nil, err
+	}
+	return results.done, nil
+}
+
+// start sends a request, the session is held until its results are complete
+func (c *Conn) start(ctx context.Context, request *Request) (*Results, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	c.mu.Lock()
+	c.nextID++
+	request.ID = c.nextID
+	request.BatchSize = c.BatchSize
+	if err := c.send(request); err != nil {
+		c.mu.Unlock()
+		return nil, err
+	}
+
+	results := &Results{conn: c, id: request.ID, ctx: ctx}
+	// the server stops the request when the context is done
+	results.stop = context.AfterFunc(ctx, func() {
+		c.send(&Request{Type: RequestCancel, Target: request.ID})
+	})
+	return results, nil
+}
+
+func (c *Conn) send(request *Request) error {
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+	if c.err != nil {
+		return c.err
+	}
+	if err := WriteFrame(c.conn, request); err != nil {
+		c.err = err
+		return err
+	}
+	return nil
+}
+
+func (c *Conn) fail(err error) {
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+	if c.err == nil {
+		c.err = err
+	}
+}
+
+// Results are the results of the statements of a request, read as they
+// arrive. NextResult moves to the next statement and Next to the next row
+// of its query:
+//
+//	for results.NextResult() {
+//		for results.Next() {
+//			var name string
+//			if err := results.Scan(&name); err != nil {
+//				return err
+//			}
+//		}
+//	}
+//	if err := results.Err(); err != nil {
+//		return err
+//	}
+type Results struct {
+	conn *Conn
+	id   uint64
+	ctx  context.Context
+	stop func() bool
+
+	current *Response // Result of the statement read
+	next    *Response // Result of the statement after it, read ahead
+	rows    [][]any   // rows of the last Batch not read yet
+	row     []any
+	done    *Response
+	err     error
+	broken  bool // the connection failed while reading the results
+}
+
+// Statement returns the keyword of the statement, eg: Query
+func (r *Results) Statement() string {
+	if r.current == nil {
+		return ""
+	}
+	return r.current.Statement
+}
+
+// Columns returns the columns of the rows of the statement, nil when it has no rows
+func (r *Results) Columns() []string {
+	if r.current == nil {
+		return nil
+	}
+	return r.current.Columns
+}
+
+// Plan returns the plan of an Explain or a Profile as it is printed
+func (r *Results) Plan() string {
+	if r.current == nil {
+		return ""
+	}
+	return r.current.Plan
+}
+
+// Queued reports whether the request only queued a program in a transaction,
+// it is known once the results are complete
+func (r *Results) Queued() bool {
+	return r.done != nil && r.done.Queued
+}
+
+// Err returns why the request failed, once the results are complete
+func (r *Results) Err() error {
+	return r.err
+}
+
+// NextResult moves to the result of the next statement, skipping the rows
+// of the current one. It returns false once there are no more statements.
+func (r *Results) NextResult() bool {
+	r.rows, r.row = nil, nil
+	for r.next == nil && r.done == nil {
+		response := r.read()
+		if response != nil && response.Type == ResponseResult {
+			r.next = response
+		}
+	}
+	if r.next == nil {
+		r.current = nil
+		return false
+	}
+	r.current, r.next = r.next, nil
+	return true
+}
+
+// Next moves to the next row of the current statement
+func (r *Results) Next() bool {
+	for len(r.rows) == 0 {
+		if r.current == nil || r.next != nil || r.done != nil {
+			r.row = nil
+			return false
+		}
+		response := r.read()
+		if response == nil {
+			continue
+		}
+		switch response.Type {
+		case ResponseBatch:
+			r.rows = response.Rows
+		case ResponseResult:
+			r.next = response
+		}
+	}
+	r.row, r.rows = r.rows[0], r.rows[1:]
+	return true
+}
+
+// Row returns the values of the current row
+func (r *Results) Row() []any {
+	return r.row
+}
+
+// Scan copies the values of the current row into the values pointed at by
+// dest. A number is scanned into *int, *int64 or *float64, a string into
+// *string, a bool into *bool and any value into *any.
+func (r *Results) Scan(dest ...any) error {
+	if r.row == nil {
+		return NoRow
+	}
+	if len(dest) != len(r.row) {
+		return fmt.Errorf("%w: %d destinations for %d columns", ScanTypeError, len(dest), len(r.row))
+	}
+	for i, value := range r.row {
+		if err := scan(value, dest[i]); err != nil {
+			return fmt.Errorf("column %s: %w", r.current.Columns[i], err)
+		}
+	}
+	return nil
+}
+
+// Close stops the request if it is still running and reads its remaining results
+func (r *Results) Close() error {
+	if r.done == nil {
+		r.conn.send(&Request{Type: RequestCancel, Target: r.id})
+	}
+	for r.done == nil {
+		r.read()
+	}
+	r.current, r.next, r.rows, r.row = nil, nil, nil, nil
+	if r.broken {
+		return r.err
+	}
+	return nil
+}
+
+// read reads the next response of the request, it completes the results
+// on Done, on Error and when the connection fails
+func (r *Results) read() *Response {
+	var response Response
+	err := ReadFrame(r.conn.conn, &response)
+	if err == nil && response.ID != r.id {
+		err = unexpected(&response)
+	}
+	if err != nil {
+		r.conn.fail(err)
+		r.broken = true
+		r.complete(&Response{ID: r.id, Type: ResponseError}, err)
+		return nil
+	}
+
+	switch response.Type {
+	case ResponseDone:
+		r.complete(&response, nil)
+	case ResponseError:
+		var err error = unexpected(&response)
+		if r.ctx.Err() != nil {
+			err = r.ctx.Err()
+		} else if len(response.Errors) > 1 {
+			err = response.Errors
+		} else if response.Error != nil {
+			err = response.Error
+		}
+		r.complete(&response, err)
+	}
+	return &response
+}
+
+func (r *Results) complete(done *Response, err error) {
+	r.done, r.err = done, err
+	r.stop()
+	r.conn.mu.Unlock()
+}
+
+func scan(value any, dest any) error {
+	switch d := dest.(type) {
+	case *any:
+		*d = value
+		return nil
+	case *int:
+		if v, ok := value.(int64); ok {
+			*d = int(v)
+			return nil
+		}
+	case *int64:
+		if v, ok := value.(int64); ok {
+			*d = v
+			return nil
+		}
+	case *float64:
+		switch v := value.(type) {
+		case float64:
+			*d = v
+			return nil
+		case int64:
+			*d = float64(v)
+			return nil
+		}
+	case *string:
+		if v, ok := value.(string); ok {
+			*d = v
+			return nil
+		}
+	case *bool:
+		if v, ok := value.(bool); ok {
+			*d = v
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: %v into %T", ScanTypeError, value, dest)
+}
@@ -0,0 +1,180 @@
+// Package wire is the binary protocol of Vortex. A client and a server
+// exchange frames over a TCP or Unix socket, each one a msgpack encoded
+// Request or Response preceded by its length as a big endian uint32.
+//
+// A session runs its requests in the order they are sent. Every request is
+// answered with Done or Error; the results of a program are sent before, a
+// Result frame for every statement followed by the rows of a query in
+// Batch frames. A Cancel request stops the request it targets while it runs,
+// it is handled as soon as it is read and is not answered.
+// The prepared statements and the open transaction belong to the session.
+package wire
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+
+	"github.com/Jintumoni/vortex/server"
+	"github.com/vmihailenco/msgpack/v5"
+)
+
+var (
+	FrameTooLarge      = errors.New("Frame is larger than MaxFrameSize")
+	UnexpectedResponse = errors.New("Response does not follow the protocol")
+)
+
+// MaxFrameSize is the largest frame accepted, in bytes
+const MaxFrameSize = 64 << 20
+
+// DefaultBatchSize is the number of rows sent in a Batch when the request does not set it
+const DefaultBatchSize = 1000
+
+type RequestType uint8
+
+const (
+	RequestRun      RequestType = iota + 1 // run Program
+	RequestPrepare                         // parse Program, Done holds the id of the statement
+	RequestExecute                         // run the prepared Statement
+	RequestClose                           // forget the prepared Statement
+	RequestCancel                          // stop the request Target, not answered
+	RequestBegin                           // open a transaction
+	RequestCommit                          // run the programs of the transaction
+	RequestRollback                        // drop the programs of the transaction
+)
+
+func (t RequestType) String() string {
+	switch t {
+	case RequestRun:
+		return "Run"
+	case RequestPrepare:
+		return "Prepare"
+	case RequestExecute:
+		return "Execute"
+	case RequestClose:
+		return "Close"
+	case RequestCancel:
+		return "Cancel"
+	case RequestBegin:
+		return "Begin"
+	case RequestCommit:
+		return "Commit"
+	case RequestRollback:
+		return "Rollback"
+	default:
+		return ""
+	}
+}
+
+// Request is a frame sent by the client, the fields used depend on its type
+type Request struct {
+	ID         uint64         `json:"id"` // chosen by the client, repeated in every response
+	Type       RequestType    `json:"type"`
+	Program    string         `json:"program,omitempty"`
+	Parameters map[string]any `json:"parameters,omitempty"`
+	Statement  uint64         `json:"statement,omitempty"`  // prepared statement of an Execute or a Close
+	Target     uint64         `json:"target,omitempty"`     // request stopped by a Cancel
+	BatchSize  int            `json:"batch_size,omitempty"` // rows per Batch, DefaultBatchSize when 0
+	ReadOnly   bool           `json:"read_only,omitempty"`  // a Run or an Execute changing the graph fails
+}
+
+type ResponseType uint8
+
+const (
+	ResponseResult ResponseType = iota + 1 // a statement ran, the rows of a query follow
+	ResponseBatch                          // rows of the last Result
+	ResponseDone                           // the request is complete
+	ResponseError                          // the request failed, it is complete
+)
+
+func (t ResponseType) String() string {
+	switch t {
+	case ResponseResult:
+		return "Result"
+	case ResponseBatch:
+		return "Batch"
+	case ResponseDone:
+		return "Done"
+	case ResponseError:
+		return "Error"
+	default:
+		return ""
+	}
+}
+
+// Response is a frame sent by the server, the fields used depend on its type
+type Response struct {
+	ID        uint64        `json:"id"`
+	Type      ResponseType  `json:"type"`
+	Statement string        `json:"statement,omitempty"` // keyword of the statement of a Result
+	Columns   []string      `json:"columns,omitempty"`   // columns of a Result with rows
+	Plan      string        `json:"plan,omitempty"`      // plan of an Explain or a Profile
+	Rows      [][]any       `json:"rows,omitempty"`      // rows of a Batch
+	Prepared  uint64        `json:"prepared,omitempty"`  // statement made by a Prepare, set on Done
+	Queued    bool          `json:"queued,omitempty"`    // Done of a program that runs on Commit
+	Error     *server.Error `json:"error,omitempty"`
+	Errors    server.Errors `json:"errors,omitempty"` // every syntax error of the program or the error alone, Error is the first one
+}
+
+// WriteFrame writes v as a frame
+func WriteFrame(w io.Writer, v any) error {
+	var body bytes.Buffer
+	body.Write(make([]byte, 4))
+	encoder := msgpack.NewEncoder(&body)
+	encoder.SetCustomStructTag("json")
+	if err := encoder.Encode(v); err != nil {
+		return err
+	}
+
+	frame := body.Bytes()
+	if len(frame)-4 > MaxFrameSize {
+		return FrameTooLarge
+	}
+	binary.BigEndian.PutUint32(frame, uint32(len(frame)-4))
+	_, err := w.Write(frame)
+	return err
+}
+
+// ReadFrame reads a frame into v. Integers are decoded as int64 or uint64
+// and floats as float64 where v holds an any.
+func ReadFrame(r io.Reader, v any) error {
+	var header [4]byte
+	if _, err := io.ReadFull(r, header[:]); err != nil {
+		return err
+	}
+	size := binary.BigEndian.Uint32(header[:])
+	if size > MaxFrameSize {
+		return FrameTooLarge
+	}
+	body := make([]byte, size)
+	if _, err := io.ReadFull(r, body); err != nil {
+		return err
+	}
+
+	decoder := msgpack.NewDecoder(bytes.NewReader(body))
+	decoder.SetCustomStructTag("json")
+	decoder.UseLooseInterfaceDecoding(true)
+	return decoder.Decode(v)
+}
+
+// Listen listens on an address written as host:port, or unix:path for a Unix socket
+func Listen(address string) (net.Listener, error) {
+	network, address := split(address)
+	return net.Listen(network, address)
+}
+
+func split(address string) (string, string) {
+	if path, ok := strings.CutPrefix(address, "unix:"); ok {
+		return "unix", path
+	}
+	return "tcp", address
+}
+
+// unexpected is the error of a response the protocol does not allow
+func unexpected(response *Response) error {
+	return fmt.Errorf("%w: %s to request %d", UnexpectedResponse, response.Type, response.ID)
+}
@@ -0,0 +1,270 @@
+package wire
+
+import (
+	"context"
+	"errors"
+	"net"
+	"sync"
+
+	"github.com/Jintumoni/vortex"
+	"github.com/Jintumoni/vortex/executor"
+	"github.com/Jintumoni/vortex/server"
+	"github.com/Jintumoni/vortex/visitors"
+)
+
+var (
+	UnknownRequest   = errors.New("Unknown request type")
+	UnknownStatement = errors.New("Prepared statement missing")
+	TransactionOpen  = errors.New("Transaction is already open")
+	NoTransaction    = errors.New("No transaction is open")
+)
+
+// Server answers the sessions opened on one database
+type Server struct {
+	DB       *vortex.DB
+	ReadOnly bool // programs changing the graph are refused
+}
+
+func NewServer(db *vortex.DB) *Server {
+	return &Server{DB: db}
+}
+
+// ListenAndServe serves the database on the address, see Listen
+func (s *Server) ListenAndServe(address string) error {
+	listener, err := Listen(address)
+	if err != nil {
+		return err
+	}
+	return s.Serve(listener)
+}
+
+// Serve opens a session for every connection accepted on the listener,
+// until the listener is closed
+func (s *Server) Serve(listener net.Listener) error {
+	defer listener.Close()
+	for {
+		conn, err := listener.Accept()
+		if err != nil {
+			if errors.Is(err, net.ErrClosed) {
+				return nil
+			}
+			return err
+		}
+		go newSession(s, conn).serve()
+	}
+}
+
+// session is the state of one connection
+type session struct {
+	server *Server
+	conn   net.Conn
+
+	writeMu sync.Mutex
+	// cancels the requests read and not complete, by id
+	running   map[uint64]context.CancelFunc
+	runningMu sync.Mutex
+
+	// owned by the goroutine handling the requests
+	statements    map[uint64]*vortex.Stmt
+	nextStatement uint64
+	transaction   []vortex.Execution
+	inTransaction bool
+}
+
+type queued struct {
+	request *Request
+	ctx     context.Context
+}
+
+func newSession(s *Server, conn net.Conn) *session {
+	return &session{
+		server:     s,
+		conn:       conn,
+		running:    make(map[uint64]context.CancelFunc),
+		statements: make(map[uint64]*vortex.Stmt),
+	}
+}
+
+// serve reads the requests of the connection until it is closed. Cancel is
+// handled as soon as it is read, the other requests are run in order.
+func (s *session) serve() {
+	defer s.conn.Close()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	requests := make(chan queued, 64)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for q := range requests {
+			s.handle(q.ctx, q.request)
+			s.finish(q.request.ID)
+		}
+	}()
+
+	for {
+		var request Request
+		if err := ReadFrame(s.conn, &request); err != nil {
+			break
+		}
+		if request.Type == RequestCancel {
+			s.cancel(request.Target)
+			continue
+		}
+
+		requestCtx, cancel := context.WithCancel(ctx)
+		s.runningMu.Lock()
+		s.running[request.ID] = cancel
+		s.runningMu.Unlock()
+		requests <- queued{request: &request, ctx: requestCtx}
+	}
+
+	// the client is gone, nobody reads the results of its requests
+	cancel()
+	close(requests)
+	<-done
+}
+
+func (s *session) cancel(id uint64) {
+	s.runningMu.Lock()
+	defer s.runningMu.Unlock()
+	if cancel, ok := s.running[id]; ok {
+		cancel()
+	}
+}
+
+func (s *session) finish(id uint64) {
+	s.runningMu.Lock()
+	defer s.runningMu.Unlock()
+	if cancel, ok := s.running[id]; ok {
+		cancel()
+		delete(s.running, id)
+	}
+}
+
+func (s *session) send(response *Response) error {
+	s.writeMu.Lock()
+	defer s.writeMu.Unlock()
+	return WriteFrame(s.conn, response)
+}
+
+func (s *session) fail(request *Request, err error) {
+	errs := server.NewErrors(err)
+	s.send(&Response{ID: request.ID, Type: ResponseError, Error: errs[0], Errors: errs})
+}
+
+func (s *session) handle(ctx context.Context, request *Request) {
+	done := &Response{ID: request.ID, Type: ResponseDone}
+	var err error
+
+	switch request.Type {
+	case RequestRun:
+		var stmt *vortex.Stmt
+		if stmt, err = s.server.DB.Prepare(request.Program); err == nil {
+			done.Queued, err = s.execute(ctx, request, stmt)
+		}
+	case RequestPrepare:
+		var stmt *vortex.Stmt
+		if stmt, err = s.server.DB.Prepare(request.Program); err == nil {
+			s.nextStatement++
+			s.statements[s.nextStatement] = stmt
+			done.Prepared = s.nextStatement
+		}
+	case RequestExecute:
+		stmt, ok := s.statements[request.Statement]
+		if !ok {
+			err = UnknownStatement
+			break
+		}
+		done.Queued, err = s.execute(ctx, request, stmt)
+	case RequestClose:
+		if _, ok := s.statements[request.Statement]; !ok {
+			err = UnknownStatement
+			break
+		}
+		delete(s.statements, request.Statement)
+	case RequestBegin:
+		if s.inTransaction {
+			err = TransactionOpen
+			break
+		}
+		s.inTransaction = true
+	case RequestCommit:
+		if !s.inTransaction {
+			err = NoTransaction
+			break
+		}
+		transaction := s.transaction
+		s.transaction, s.inTransaction = nil, false
+		err = s.server.DB.RunAll(ctx, transaction, s.emitter(ctx, request))
+	case RequestRollback:
+		if !s.inTransaction {
+			err = NoTransaction
+			break
+		}
+		s.transaction, s.inTransaction = nil, false
+	default:
+		err = UnknownRequest
+	}
+
+	if err != nil {
+		s.fail(request, err)
+		return
+	}
+	s.send(done)
+}
+
+// execute runs a statement, a statement changing the graph inside a
+// transaction is queued until the Commit instead
+func (s *session) execute(ctx context.Context, request *Request, stmt *vortex.Stmt) (bool, error) {
+	readOnly := s.server.ReadOnly || request.ReadOnly
+	if s.inTransaction && !stmt.ReadOnly() {
+		if readOnly {
+			return false, vortex.ReadOnly
+		}
+		// the values are checked now, the Commit must not fail on them
+		if _, err := visitors.Parameters(request.Parameters); err != nil {
+			return false, err
+		}
+		s.transaction = append(s.transaction, vortex.Execution{Stmt: stmt, Parameters: request.Parameters})
+		return true, nil
+	}
+
+	emit := s.emitter(ctx, request)
+	if readOnly {
+		return false, stmt.RunReadOnly(ctx, request.Parameters, emit)
+	}
+	return false, stmt.Run(ctx, request.Parameters, emit)
+}
+
+// emitter returns the function sending the result of every statement of a request
+func (s *session) emitter(ctx context.Context, request *Request) func(*executor.Result) error {
+	batchSize := request.BatchSize
+	if batchSize <= 0 {
+		batchSize = DefaultBatchSize
+	}
+
+	return func(result *executor.Result) error {
+		r := server.NewResult(result)
+		response := &Response{ID: request.ID, Type: ResponseResult, Statement: r.Statement, Plan: r.Plan}
+		if r.Table == nil {
+			return s.send(response)
+		}
+
+		response.Columns = r.Columns
+		if err := s.send(response); err != nil {
+			return err
+		}
+		for start := 0; start < len(r.Rows); start += batchSize {
+			// a cancelled request stops between batches
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			end := min(start+batchSize, len(r.Rows))
+			if err := s.send(&Response{ID: request.ID, Type: ResponseBatch, Rows: r.Rows[start:end]}); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
@@ -0,0 +1,198 @@
+package wire
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"github.com/Jintumoni/vortex"
+	"github.com/Jintumoni/vortex/server"
+	"github.com/stretchr/testify/assert"
+)
+
+const testGraph = `
+Schema Person {
+  name string
+  age  int
+}
+
+Vertex Ann Person { .name = "Ann" .age = 30 }
+Vertex Bob Person { .name = "Bob" .age = 20 }
+Vertex Cid Person { .name = "Cid" .age = 41 }
+`
+
+// dial serves a database holding testGraph on the address and opens a session on it
+func dial(t *testing.T, address string) *Conn {
+	ctx := context.Background()
+	db, err := vortex.Open("", nil)
+	assert.NoError(t, err)
+	assert.NoError(t, db.Exec(ctx, testGraph))
+	t.Cleanup(func() { db.Close() })
+
+	listener, err := Listen(address)
+	assert.NoError(t, err)
+	go NewServer(db).Serve(listener)
+	t.Cleanup(func() { listener.Close() })
+
+	address = listener.Addr().String()
+	if listener.Addr().Network() == "unix" {
+		address = "unix:" + address
+	}
+	conn, err := Dial(ctx, address)
+	assert.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+	return conn
+}
+
+// names reads the first column of every row of the current statement
+func names(t *testing.T, results *Results) []string {
+	var names []string
+	for results.Next() {
+		var name string
+		assert.NoError(t, results.Scan(&name))
+		names = append(names, name)
+	}
+	return names
+}
+
+func TestRunStreamsBatches(t *testing.T) {
+	conn := dial(t, "127.0.0.1:0")
+	conn.BatchSize = 2
+
+	results, err := conn.Run(context.Background(), `Query Person Return .name Explain Query Person Query Person { .age > 100 }`, nil)
+	assert.NoError(t, err)
+
+	assert.True(t, results.NextResult())
+	assert.Equal(t, "Query", results.Statement())
+	assert.Equal(t, []string{".name"}, results.Columns())
+	assert.Equal(t, []string{"Ann", "Bob", "Cid"}, names(t, results))
+
+	assert.True(t, results.NextResult())
+	assert.Equal(t, "Explain", results.Statement())
+	assert.Contains(t, results.Plan(), "Scan Person")
+	assert.False(t, results.Next())
+
+	assert.True(t, results.NextResult())
+	assert.Empty(t, names(t, results))
+	assert.False(t, results.NextResult())
+	assert.NoError(t, results.Err())
+	assert.NoError(t, results.Close())
+}
+
+func TestPreparedStatement(t *testing.T) {
+	ctx := context.Background()
+	conn := dial(t, "unix:"+filepath.Join(t.TempDir(), "vortex.sock"))
+
+	stmt, err := conn.Prepare(ctx, `Query Person { .age > $age } Return .name, .age`)
+	assert.NoError(t, err)
+	for age, expected := range map[int][]string{25: {"Ann", "Cid"}, 35: {"Cid"}} {
+		results, err := stmt.Run(ctx, map[string]any{"age": age})
+		assert.NoError(t, err)
+		assert.True(t, results.NextResult())
+
+		var rows []string
+		for results.Next() {
+			var name string
+			var age int
+			assert.NoError(t, results.Scan(&name, &age))
+			rows = append(rows, name)
+		}
+		assert.Equal(t, expected, rows)
+		assert.NoError(t, results.Close())
+	}
+
+	assert.NoError(t, stmt.Close(ctx))
+	results, err := stmt.Run(ctx, nil)
+	assert.NoError(t, err)
+	assert.False(t, results.NextResult())
+	assert.EqualError(t, results.Err(), UnknownStatement.Error())
+}
+
+func TestTransaction(t *testing.T) {
+	ctx := context.Background()
+	conn := dial(t, "127.0.0.1:0")
+	count := func() int {
+		results, err := conn.Run(ctx, `Query Count(Person)`, nil)
+		assert.NoError(t, err)
+		assert.True(t, results.NextResult())
+		assert.True(t, results.Next())
+		var count int
+		assert.NoError(t, results.Scan(&count))
+		assert.NoError(t, results.Close())
+		return count
+	}
+
+	assert.NoError(t, conn.Begin(ctx))
+	results, err := conn.Run(ctx, `Vertex Dan Person { .name = "Dan" .age = 50 }`, nil)
+	assert.NoError(t, err)
+	assert.False(t, results.NextResult())
+	assert.True(t, results.Queued())
+	assert.Equal(t, 3, count())
+
+	results, err = conn.Commit(ctx)
+	assert.NoError(t, err)
+	assert.True(t, results.NextResult())
+	assert.Equal(t, "Vertex", results.Statement())
+	assert.False(t, results.NextResult())
+	assert.NoError(t, results.Err())
+	assert.Equal(t, 4, count())
+
+	assert.NoError(t, conn.Begin(ctx))
+	results, err = conn.Run(ctx, `Vertex Eve Person { .name = "Eve" .age = 50 }`, nil)
+	assert.NoError(t, err)
+	assert.NoError(t, results.Close())
+	assert.NoError(t, conn.Rollback(ctx))
+	assert.Equal(t, 4, count())
+
+	var e *server.Error
+	assert.True(t, errors.As(conn.Rollback(ctx), &e))
+	assert.Equal(t, NoTransaction.Error(), e.Message)
+}
+
+func TestCloseStopsTheRequest(t *testing.T) {
+	ctx := context.Background()
+	conn := dial(t, "127.0.0.1:0")
+	conn.BatchSize = 1
+
+	results, err := conn.Run(ctx, `Query Person Return .name`, nil)
+	assert.NoError(t, err)
+	assert.True(t, results.NextResult())
+	assert.True(t, results.Next())
+	assert.NoError(t, results.Close())
+
+	// the session can be used again
+	results, err = conn.Run(ctx, `Query Person Return .name`, nil)
+	assert.NoError(t, err)
+	assert.True(t, results.NextResult())
+	assert.Equal(t, []string{"Ann", "Bob", "Cid"}, names(t, results))
+
+	cancelled, cancel := context.WithCancel(ctx)
+	cancel()
+	_, err = conn.Run(cancelled, `Query Person`, nil)
+	assert.ErrorIs(t, err, context.Canceled)
+}
+
+func TestSyntaxError(t *testing.T) {
+	conn := dial(t, "127.0.0.1:0")
+
+	results, err := conn.Run(context.Background(), "Query Person {\n  .age >\n}", nil)
+	assert.NoError(t, err)
+	assert.False(t, results.NextResult())
+
+	var e *server.Error
+	assert.True(t, errors.As(results.Err(), &e))
+	assert.Equal(t, "}", e.Token)
+	assert.Equal(t, 3, e.Row)
+
+	// every error of a program is reported
+	results, err = conn.Run(context.Background(), "Foo\nQuery Person {\n  .age >\n}", nil)
+	assert.NoError(t, err)
+	assert.False(t, results.NextResult())
+
+	var errs server.Errors
+	assert.True(t, errors.As(results.Err(), &errs))
+	assert.Len(t, errs, 2)
+	assert.Equal(t, "Foo", errs[0].Token)
+	assert.Equal(t, "}", errs[1].Token)
+}